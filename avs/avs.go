@@ -3,9 +3,9 @@ package avs
 import (
 	"context"
 	sdkmath "cosmossdk.io/math"
+	"errors"
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
-	"github.com/imua-xyz/imua-avs-sdk/client/txmgr"
 	sdkEcdsa "github.com/imua-xyz/imua-avs-sdk/crypto/ecdsa"
 	"github.com/imua-xyz/imua-avs-sdk/logging"
 	sdklogging "github.com/imua-xyz/imua-avs-sdk/logging"
@@ -14,6 +14,8 @@ import (
 	"github.com/imua-xyz/imua-avs/core"
 	chain "github.com/imua-xyz/imua-avs/core/chainio"
 	"github.com/imua-xyz/imua-avs/core/chainio/eth"
+	"github.com/imua-xyz/imua-avs/core/metrics"
+	"github.com/imua-xyz/imua-avs/core/network"
 	"github.com/imua-xyz/imua-avs/types"
 	"math/big"
 	"math/rand"
@@ -46,6 +48,15 @@ type Avs struct {
 	thresholdPercentage   uint8
 	taskStatisticalPeriod uint64
 	avsEpochIdentifier    string
+	taskType              core.TaskType
+	// drainer tracks the task creation a shutdown waits for
+	drainer *core.Drainer
+
+	ethClient eth.EthClient
+	avsSender common.Address
+	metrics   *metrics.AvsMetrics
+	// metricsAddress is where metrics are served, empty when they are disabled
+	metricsAddress string
 }
 
 // NewAvs creates a new Avs with the provided config.
@@ -56,21 +67,40 @@ func NewAvs(c *types.NodeConfig) (*Avs, error) {
 	} else {
 		logLevel = sdklogging.Development
 	}
-	logger, err := sdklogging.NewZapLogger(logLevel)
+	logger, err := core.NewZapLogger(logLevel)
+	if err != nil {
+		return nil, err
+	}
+
+	taskType, err := core.LookupTaskType(c.TaskType)
 	if err != nil {
+		logger.Error("Cannot find task type", "taskType", c.TaskType, "err", err)
 		return nil, err
 	}
 
-	ethRpcClient, err := eth.NewClient(c.EthRpcUrl)
+	avsMetrics := metrics.NewAvsMetrics()
+	var ethRpcClient eth.EthClient
+	ethRpcClient, err = eth.NewClient(c.EthRpcUrl)
 	if err != nil {
 		logger.Error("Cannot create http ethclient", "err", err)
 		return nil, err
 	}
+	// task creation transactions and their receipts are timed in rpc_latency_seconds
+	ethRpcClient = eth.NewObservedClient(ethRpcClient, avsMetrics.ObserveRPC)
 	chainId, err := ethRpcClient.ChainID(context.Background())
 	if err != nil {
 		logger.Error("Cannot get chainId", "err", err)
 		return nil, err
 	}
+	profile, err := network.Load(c.Network)
+	if err != nil {
+		logger.Error("Cannot load network profile", "network", c.Network, "err", err)
+		return nil, err
+	}
+	if err := chain.VerifyNetwork(context.Background(), profile, ethRpcClient); err != nil {
+		logger.Error("Node does not match the network profile", "network", profile.Name, "err", err)
+		return nil, err
+	}
 
 	ecdsaKeyPassword, ok := os.LookupEnv("AVS_ECDSA_KEY_PASSWORD")
 	if !ok {
@@ -136,7 +166,7 @@ func NewAvs(c *types.NodeConfig) (*Avs, error) {
 		}
 	}
 
-	txMgr := txmgr.NewSimpleTxManager(ethRpcClient, logger, signer, avsSender)
+	txMgr := chain.NewTxQueue(ethRpcClient, logger, signer, avsSender)
 	avsWriter, err := chain.BuildChainWriter(
 		common.HexToAddress(c.AVSAddress),
 		ethRpcClient,
@@ -163,6 +193,16 @@ func NewAvs(c *types.NodeConfig) (*Avs, error) {
 		return nil, err
 	}
 	if info == "" {
+		ownerAddresses, err := profile.ParseAddresses(c.AvsOwnerAddresses)
+		if err != nil {
+			logger.Error("Invalid avs_owner_addresses", "err", err)
+			return nil, err
+		}
+		whitelistAddresses, err := profile.ParseAddresses(c.WhitelistAddresses)
+		if err != nil {
+			logger.Error("Invalid whitelist_addresses", "err", err)
+			return nil, err
+		}
 		params := avs.AVSParams{
 			Sender:              common.HexToAddress(c.AVSOwnerAddress),
 			AvsName:             avsName,
@@ -170,8 +210,8 @@ func NewAvs(c *types.NodeConfig) (*Avs, error) {
 			TaskAddress:         common.HexToAddress(c.TaskAddress),
 			SlashAddress:        common.HexToAddress(c.AVSRewardAddress),
 			RewardAddress:       common.HexToAddress(c.AVSSlashAddress),
-			AvsOwnerAddresses:   core.ConvertToEthAddresses(c.AvsOwnerAddresses),
-			WhitelistAddresses:  core.ConvertToEthAddresses(c.WhitelistAddresses),
+			AvsOwnerAddresses:   ownerAddresses,
+			WhitelistAddresses:  whitelistAddresses,
 			AssetIDs:            c.AssetIDs,
 			AvsUnbondingPeriod:  c.AvsUnbondingPeriod,
 			MinSelfDelegation:   c.MinSelfDelegation,
@@ -191,6 +231,10 @@ func NewAvs(c *types.NodeConfig) (*Avs, error) {
 	}
 	info, _ = avsReader.GetAVSEpochIdentifier(&bind.CallOpts{}, c.AVSAddress)
 
+	metricsAddress := ""
+	if c.EnableMetrics {
+		metricsAddress = c.AvsMetricsIpPortAddress
+	}
 	return &Avs{
 		logger:                logger,
 		avsWriter:             avsWriter,
@@ -202,18 +246,41 @@ func NewAvs(c *types.NodeConfig) (*Avs, error) {
 		thresholdPercentage:   c.ThresholdPercentage,
 		taskStatisticalPeriod: c.TaskStatisticalPeriod,
 		avsEpochIdentifier:    info,
+		taskType:              taskType,
+		drainer:               core.NewDrainer(),
+		ethClient:             ethRpcClient,
+		avsSender:             avsSender,
+		metrics:               avsMetrics,
+		metricsAddress:        metricsAddress,
 	}, nil
 }
 
 func (avs *Avs) Start(ctx context.Context) error {
 	avs.logger.Infof("Starting avs.")
+	if avs.metricsAddress != "" {
+		go avs.metrics.Serve(ctx, avs.metricsAddress, avs.logger)
+		go avs.metrics.Poll(ctx, metrics.PollConfig{
+			Client:           avs.ethClient,
+			Account:          avs.avsSender,
+			EpochIdentifiers: []string{avs.avsEpochIdentifier},
+			CurrentEpoch: func(ctx context.Context, epochIdentifier string) (int64, error) {
+				return avs.avsReader.GetCurrentEpoch(&bind.CallOpts{Context: ctx}, epochIdentifier)
+			},
+			OnPoll: avs.pollUSDValue,
+		})
+	}
 	ticker := time.NewTicker(time.Duration(avs.createTaskInterval) * time.Second)
 	avs.logger.Infof("Avs owner set to send new task every %d seconds", avs.createTaskInterval)
 	defer ticker.Stop()
 	taskNum := int64(1)
 	// Wait for the operator process to prepare work, such as deposit delegation, before sending the task
-	time.Sleep(20 * time.Second)
-	err := avs.sendNewTask()
+	select {
+	case <-ctx.Done():
+		avs.logger.Info("Context canceled; stopping AVS.")
+		return nil
+	case <-time.After(20 * time.Second):
+	}
+	err := avs.runNewTask(ctx)
 	if err != nil {
 		// we log the errors inside sendNewTask() so here we just continue to do the next task
 		avs.logger.Info("sendNewTask encountered an error: %v; continuing to do the next task.", err)
@@ -226,7 +293,7 @@ func (avs *Avs) Start(ctx context.Context) error {
 			return nil
 		case <-ticker.C:
 			avs.logger.Info("sendNewTask-num:", "taskNum", taskNum)
-			err := avs.sendNewTask()
+			err := avs.runNewTask(ctx)
 			if err != nil {
 				// we log the errors inside sendNewTask() so here we just continue to do the next task
 				avs.logger.Info("sendNewTask encountered an error: %v; continuing to do the next task.", err)
@@ -237,14 +304,55 @@ func (avs *Avs) Start(ctx context.Context) error {
 	}
 }
 
+// runNewTask sends a new task and waits for it, unless ctx is canceled first.
+// A task creation still in flight then is left to the shutdown drain.
+func (avs *Avs) runNewTask(ctx context.Context) error {
+	done := make(chan error, 1)
+	avs.drainer.Go(func() {
+		err := avs.sendNewTask(ctx)
+		switch {
+		case err == nil:
+			avs.metrics.TasksCreated.Inc()
+		case !errors.Is(err, context.Canceled):
+			avs.metrics.TaskCreationFailures.Inc()
+		}
+		done <- err
+	})
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Shutdown waits up to timeout for a task creation in flight, then flushes the logs.
+func (avs *Avs) Shutdown(timeout time.Duration) error {
+	avs.logger.Info("Shutting down avs, draining in-flight task creation", "timeout", timeout)
+	err := avs.drainer.Wait(timeout)
+	if err != nil {
+		avs.logger.Warn("Abandoning in-flight task creation", "err", err)
+	}
+	avs.logger.Info("Avs shut down")
+	if serr := core.SyncLogger(avs.logger); serr != nil && err == nil {
+		err = serr
+	}
+	return err
+}
+
 // sendNewTask sends a new task to the task manager contract.
-func (avs *Avs) sendNewTask() error {
+func (avs *Avs) sendNewTask(ctx context.Context) error {
 	avs.logger.Info("Avs sending new task")
 	var taskPowerTotal sdkmath.LegacyDec
 	var lastErr error
 
 	for attempt := 1; attempt <= maxRetries; attempt++ {
 		taskPowerTotal, lastErr = avs.avsReader.GtAVSUSDValue(&bind.CallOpts{}, avs.avsAddress)
+		if lastErr == nil {
+			if f, err := taskPowerTotal.Float64(); err == nil {
+				avs.metrics.USDValue.Set(f)
+			}
+		}
 
 		if lastErr == nil && !taskPowerTotal.IsZero() && !taskPowerTotal.IsNegative() {
 			break
@@ -281,16 +389,30 @@ func (avs *Avs) sendNewTask() error {
 		}
 				time.Sleep(1 * sleepDuration)
 		*/
-		time.Sleep(retryDelay)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(retryDelay):
+		}
 	}
 
 	if taskPowerTotal.IsZero() || taskPowerTotal.IsNegative() {
 		// panic("the voting power of AVS is zero or negative")
 	}
-	_, err := avs.avsWriter.CreateNewTask(
-		context.Background(),
-		GenerateRandomName(5),
-		uint64(rand.Intn(500)),
+	input, err := avs.taskType.NewInput()
+	if err != nil {
+		avs.logger.Error("Avs failed to generate task input", "taskType", avs.taskType.Name(), "err", err)
+		return err
+	}
+	rawInput, err := avs.taskType.EncodeInput(input)
+	if err != nil {
+		avs.logger.Error("Avs failed to encode task input", "taskType", avs.taskType.Name(), "err", err)
+		return err
+	}
+	_, err = avs.avsWriter.CreateNewTask(
+		avs.drainer.Context(),
+		core.FormatTaskName(avs.taskType.Name(), GenerateRandomName(5)),
+		rawInput,
 		avs.taskResponsePeriod,
 		avs.taskChallengePeriod,
 		avs.thresholdPercentage,
@@ -302,6 +424,19 @@ func (avs *Avs) sendNewTask() error {
 	}
 	return nil
 }
+
+// pollUSDValue refreshes the AVS USD value gauge.
+func (avs *Avs) pollUSDValue(ctx context.Context) {
+	start := time.Now()
+	value, err := avs.avsReader.GtAVSUSDValue(&bind.CallOpts{Context: ctx}, avs.avsAddress)
+	avs.metrics.ObserveRPC("getAVSUSDValue", start, err)
+	if err == nil {
+		if f, err := value.Float64(); err == nil {
+			avs.metrics.USDValue.Set(f)
+		}
+	}
+}
+
 func GenerateRandomName(length int) string {
 	const charset = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
 	result := make([]byte, length)
@@ -3,6 +3,7 @@ package main
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	sdkutils "github.com/imua-xyz/imua-avs-sdk/utils"
 	"github.com/imua-xyz/imua-avs/types"
@@ -12,6 +13,7 @@ import (
 	"github.com/urfave/cli"
 
 	"github.com/imua-xyz/imua-avs/avs"
+	"github.com/imua-xyz/imua-avs/core"
 	"github.com/imua-xyz/imua-avs/core/config"
 )
 
@@ -56,10 +58,15 @@ func avsMain(ctx *cli.Context) error {
 		return err
 	}
 
-	err = agg.Start(context.Background())
-	if err != nil {
-		return err
+	runCtx, stop := core.SignalContext(context.Background())
+	defer stop()
+
+	runErr := agg.Start(runCtx)
+	shutdownErr := agg.Shutdown(core.ShutdownTimeout(nodeConfig.ShutdownTimeout))
+	if code := core.ExitCode(runErr, shutdownErr); code != core.ExitOK {
+		return cli.NewExitError(fmt.Sprintf("avs stopped: %v", errors.Join(runErr, shutdownErr)), code)
 	}
+	log.Println("avs stopped")
 
 	return nil
 }
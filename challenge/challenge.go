@@ -2,29 +2,39 @@ package challenge
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"github.com/ethereum/go-ethereum"
-	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
 	ethtypes "github.com/ethereum/go-ethereum/core/types"
-	"github.com/ethereum/go-ethereum/ethclient"
-	"github.com/imua-xyz/imua-avs-sdk/client/txmgr"
 	sdklogging "github.com/imua-xyz/imua-avs-sdk/logging"
-	"github.com/imua-xyz/imua-avs-sdk/nodeapi"
 	"github.com/imua-xyz/imua-avs-sdk/signer"
+	"github.com/imua-xyz/imua-avs/challenge/report"
+	"github.com/imua-xyz/imua-avs/challenge/verify"
 	avs "github.com/imua-xyz/imua-avs/contracts/bindings/avs"
+	"github.com/imua-xyz/imua-avs/core"
 	chain "github.com/imua-xyz/imua-avs/core/chainio"
 	"github.com/imua-xyz/imua-avs/core/chainio/eth"
+	"github.com/imua-xyz/imua-avs/core/health"
+	"github.com/imua-xyz/imua-avs/core/metrics"
+	"github.com/imua-xyz/imua-avs/core/network"
 	"github.com/imua-xyz/imua-avs/types"
 	"math/big"
 	"os"
 	"strconv"
+	"time"
 )
 
 const (
 	AvsName = "hello-world-avs-demo"
 	SemVer  = "0.0.1"
+	// defaultCheckpointPath is used when challenger_checkpoint_path is not configured
+	defaultCheckpointPath = "data/challenger_checkpoint.json"
+	// defaultReportDir is used when challenge_report_dir is not configured
+	defaultReportDir = "data/challenge_reports"
+	// epochPollInterval is how often a task waiting for its challenge window checks the epoch.
+	epochPollInterval = 5 * time.Second
 	// DayEpochID defines the identifier for a daily epoch.
 )
 
@@ -32,13 +42,24 @@ type Challenger struct {
 	config          types.NodeConfig
 	logger          sdklogging.Logger
 	ethClient       eth.EthClient
-	ethWsClient     *ethclient.Client
-	nodeApi         *nodeapi.NodeApi
 	avsWriter       chain.AvsWriter
 	avsReader       chain.ChainReader
 	avsAddr         common.Address
+	avsManager      common.Address
 	epochIdentifier string
-	contractABI     abi.ABI
+	// follower handles the logs of the AVS contract, backfilling the TaskCreated events missed
+	// since its checkpoint on restart
+	follower *chain.LogFollower
+	// reportDir is where the verdict report of every challenged task is written
+	reportDir string
+	// drainer tracks the challenges a shutdown waits for
+	drainer *core.Drainer
+	metrics *metrics.ChallengerMetrics
+	// subscriptionUp is set while the log subscription is established
+	subscriptionUp health.Flag
+	// events and backfillEvents route the logs of the subscription and of the backfill
+	events         *chain.EventRouter
+	backfillEvents *chain.EventRouter
 }
 
 func NewChallengeFromConfig(c types.NodeConfig) (*Challenger, error) {
@@ -48,14 +69,12 @@ func NewChallengeFromConfig(c types.NodeConfig) (*Challenger, error) {
 	} else {
 		logLevel = sdklogging.Development
 	}
-	logger, err := sdklogging.NewZapLogger(logLevel)
+	logger, err := core.NewZapLogger(logLevel)
 	if err != nil {
 		return nil, err
 	}
 
-	// Setup Node Api
-	nodeApi := nodeapi.NewNodeApi(AvsName, SemVer, c.NodeApiIpPortAddress, logger)
-
+	challengerMetrics := metrics.NewChallengerMetrics()
 	var ethRpcClient eth.EthClient
 	ethRpcClient, err = eth.NewClient(c.EthRpcUrl)
 	if err != nil {
@@ -63,17 +82,23 @@ func NewChallengeFromConfig(c types.NodeConfig) (*Challenger, error) {
 
 		return nil, err
 	}
-	ethWsClient, err := ethclient.Dial(c.EthWsUrl)
-	if err != nil {
-		logger.Error("Cannot create ws eth client", "err", err)
-		return nil, err
-	}
+	// task and response reads, challenge transactions and their receipts are timed in rpc_latency_seconds
+	ethRpcClient = eth.NewObservedClient(ethRpcClient, challengerMetrics.ObserveRPC)
 
 	chainId, err := ethRpcClient.ChainID(context.Background())
 	if err != nil {
 		logger.Error("Cannot get chainId", "err", err)
 		return nil, err
 	}
+	profile, err := network.Load(c.Network)
+	if err != nil {
+		logger.Error("Cannot load network profile", "network", c.Network, "err", err)
+		return nil, err
+	}
+	if err := chain.VerifyNetwork(context.Background(), profile, ethRpcClient); err != nil {
+		logger.Error("Node does not match the network profile", "network", profile.Name, "err", err)
+		return nil, err
+	}
 
 	ecdsaKeyPassword, ok := os.LookupEnv("AVS_ECDSA_KEY_PASSWORD")
 	if !ok {
@@ -99,7 +124,7 @@ func NewChallengeFromConfig(c types.NodeConfig) (*Challenger, error) {
 	if c.AVSOwnerAddress != challengeSender.String() {
 		logger.Error("challengeSender is not equal AVSOwnerAddress")
 	}
-	txMgr := txmgr.NewSimpleTxManager(ethRpcClient, logger, signer, common.HexToAddress(c.AVSOwnerAddress))
+	txMgr := chain.NewTxQueue(ethRpcClient, logger, signer, common.HexToAddress(c.AVSOwnerAddress))
 
 	avsReader, _ := chain.BuildChainReader(
 		common.HexToAddress(c.AVSAddress),
@@ -121,23 +146,42 @@ func NewChallengeFromConfig(c types.NodeConfig) (*Challenger, error) {
 		logger.Error("Cannot GetAVSEpochIdentifier", "err", err)
 		return nil, err
 	}
-	contractABI, err := avs.ContracthelloWorldMetaData.GetAbi()
+	checkpointPath := c.ChallengerCheckpointPath
+	if checkpointPath == "" {
+		checkpointPath = defaultCheckpointPath
+	}
+	checkpoint, err := chain.OpenLogCheckpoint(checkpointPath)
 	if err != nil {
-		logger.Error("Cannot GetAbi", "err", err)
+		logger.Error("Cannot open log checkpoint", "path", checkpointPath, "err", err)
 		return nil, err
 	}
+	reportDir := c.ChallengeReportDir
+	if reportDir == "" {
+		reportDir = defaultReportDir
+	}
 	challenger := &Challenger{
 		config:          c,
 		logger:          logger,
-		nodeApi:         nodeApi,
 		ethClient:       ethRpcClient,
-		ethWsClient:     ethWsClient,
 		avsWriter:       avsWriter,
 		avsReader:       *avsReader,
 		avsAddr:         common.HexToAddress(c.AVSAddress),
+		avsManager:      profile.AVSManagerPrecompile,
 		epochIdentifier: epochIdentifier,
-		contractABI:     *contractABI,
+		reportDir:       reportDir,
+		drainer:         core.NewDrainer(),
+		metrics:         challengerMetrics,
 	}
+	challenger.follower = chain.NewLogFollower(ethRpcClient, c.EthWsUrl, ethereum.FilterQuery{
+		Addresses: []common.Address{challenger.avsAddr},
+	}, checkpoint, logger)
+	challenger.follower.StartAt(c.BackfillStartBlock)
+	challenger.follower.OnConnectionChange(func(up bool) {
+		challenger.subscriptionUp.Set(up)
+		challenger.metrics.SetSubscriptionUp(challenger.avsAddr.Hex(), up)
+	})
+	challenger.events = challenger.newEventRouter(false)
+	challenger.backfillEvents = challenger.newEventRouter(true)
 	logger.Info("challenger info", "challengeAddr", c.AVSOwnerAddress)
 
 	return challenger, nil
@@ -155,15 +199,18 @@ func (o *Challenger) Exec(ctx context.Context, taskID, num uint64) error {
 		TaskTotalPower:    taskInfo.TaskTotalPower,
 	}
 	o.logger.Info("challenger info", "challenge-TaskResponse", taskInfo)
-	_, err := o.avsWriter.Challenge(
-		ctx,
-		*task)
-
+	created, err := chain.FindTaskCreated(ctx, o.ethClient, o.avsAddr, taskID, o.config.BackfillStartBlock)
 	if err != nil {
-		o.logger.Error("Challeger failed to raiseAndResolveChallenge", "err", err)
-		return fmt.Errorf("failed to raiseAndResolveChallenge: %w", err)
+		return fmt.Errorf("cannot look up the TaskCreated event of task %d: %w", taskID, err)
 	}
-	return nil
+	if created == nil {
+		return fmt.Errorf("no TaskCreated event of task %d since block %d", taskID, o.config.BackfillStartBlock)
+	}
+	if o.challengeResolved(ctx, *task, created.BlockNumber) {
+		return nil
+	}
+	_, err = o.raiseChallenge(ctx, ctx, *task, taskInfo, created.BlockNumber)
+	return err
 }
 func (o *Challenger) Start(ctx context.Context) error {
 	// 1. First, the task reaches the challenge period and the module is verified
@@ -176,141 +223,384 @@ func (o *Challenger) Start(ctx context.Context) error {
 	// 5. Compare whether the above proportion is greater than or equal to thresholdPercentage. If it is greater than, isExpected is true; otherwise, it is false
 	o.logger.Infof("Starting Challenge.")
 	if o.config.EnableNodeApi {
-		o.nodeApi.Start()
+		monitor := health.NewMonitor(AvsName, SemVer, o.logger, o.healthChecks()...)
+		go monitor.Serve(ctx, o.config.NodeApiIpPortAddress)
+		go monitor.Run(ctx, health.DefaultInterval)
 	}
-	query := ethereum.FilterQuery{
-		Addresses: []common.Address{o.avsAddr},
+	if o.config.EnableMetrics {
+		go o.metrics.Serve(ctx, o.config.ChallengerMetricsIpPortAddress, o.logger)
+		go o.metrics.Poll(ctx, metrics.PollConfig{
+			Client:           o.ethClient,
+			Account:          common.HexToAddress(o.config.AVSOwnerAddress),
+			EpochIdentifiers: []string{o.epochIdentifier},
+			CurrentEpoch: func(ctx context.Context, epochIdentifier string) (int64, error) {
+				return o.avsReader.GetCurrentEpoch(&bind.CallOpts{Context: ctx}, epochIdentifier)
+			},
+		})
 	}
-	logs := make(chan ethtypes.Log)
+	return o.follower.Run(ctx, o.events, o.backfillEvents)
+}
 
-	sub, err := o.ethWsClient.SubscribeFilterLogs(context.Background(), query, logs)
-	if err != nil {
-		o.logger.Error("Subscribe failed", "err", err)
+// newEventRouter routes the TaskCreated events of the live subscription or, if backfilled, of the backfill.
+func (o *Challenger) newEventRouter(backfilled bool) *chain.EventRouter {
+	router := chain.NewEventRouter()
+	chain.OnEvent(router, func(ctx context.Context, e *avs.ContracthelloWorldTaskCreated) error {
+		return o.handleTaskCreated(ctx, e, backfilled)
+	})
+	return router
+}
 
+// handleTaskCreated starts watching a task until it can be challenged, its log stays pending in
+// the checkpoint until the challenge is done so a restart picks the task up again. Backfilled
+// tasks whose challenge period is over are skipped.
+func (o *Challenger) handleTaskCreated(ctx context.Context, e *avs.ContracthelloWorldTaskCreated, backfilled bool) error {
+	done := func() { o.follower.Done(e.Raw) }
+	task, err := o.ProcessNewTaskCreatedLog(e)
+	if err != nil {
+		o.logger.Error("Unsupported task type, skipping task", "name", e.Name, "err", err)
+		done()
+		return nil
 	}
-	defer sub.Unsubscribe()
-
-	o.logger.Infof("Starting event monitoring...")
-
-	for {
-		select {
-		case err := <-sub.Err():
-			o.logger.Error("Subscription error:", err)
-		case vLog := <-logs:
-			event, err := o.parseEvent(vLog)
-			if err != nil {
-				o.logger.Info("Not as expected TaskCreated log ，parse err:", "err", err)
-			}
-			if event != nil {
-				e := event.(*avs.ContracthelloWorldTaskCreated)
-				task := o.ProcessNewTaskCreatedLog(e)
-				taskInfo, err := o.avsReader.GetTaskInfo(&bind.CallOpts{}, o.avsAddr.String(), task.TaskId)
-				if err != nil {
-					o.logger.Error("Failed to GetTaskInfo", "err", err)
-					return err
-				}
-				go func() {
-					_, err := o.TriggerChallenge(context.Background(), *task, taskInfo)
-					if err != nil {
-
-					}
-				}()
-			}
+	taskInfo, err := o.avsReader.GetTaskInfo(&bind.CallOpts{Context: ctx}, o.avsAddr.String(), task.TaskId)
+	if err != nil {
+		// the log is left pending in the checkpoint so the task is picked up again on restart
+		return fmt.Errorf("failed to get info of task %d: %w", task.TaskId, err)
+	}
+	if backfilled {
+		num, err := o.avsReader.GetCurrentEpoch(&bind.CallOpts{Context: ctx}, o.epochIdentifier)
+		if err != nil {
+			o.logger.Error("Cannot exec GetCurrentEpoch", "err", err)
+		} else if uint64(num) > taskInfo.StartingEpoch+taskInfo.TaskResponsePeriod+
+			taskInfo.TaskStatisticalPeriod+taskInfo.TaskChallengePeriod {
+			o.logger.Info("Backfilled task is past its challenge period, skipping", "taskId", task.TaskId)
+			done()
+			return nil
 		}
 	}
+	o.drainer.Go(func() {
+		if _, err := o.TriggerChallenge(ctx, *task, taskInfo, e.Raw.BlockNumber); err != nil {
+			// left pending in the checkpoint so the task is picked up again on restart
+			o.logger.Info("Stopped waiting for challenge window", "taskId", task.TaskId, "err", err)
+			return
+		}
+		done()
+	})
+	return nil
 }
 
-// ProcessNewTaskCreatedLog TaskResponse is the struct that is signed and sent to the chain as a task response.
-func (o *Challenger) ProcessNewTaskCreatedLog(e *avs.ContracthelloWorldTaskCreated) *avs.AvsServiceContractChallengeReq {
+// Shutdown waits up to timeout for challenges being sent, then flushes the logs. Tasks still
+// waiting for their challenge window stay pending in the checkpoint and are picked up again on
+// the next start.
+func (o *Challenger) Shutdown(timeout time.Duration) error {
+	o.logger.Info("Shutting down challenger, draining in-flight challenges", "timeout", timeout)
+	err := o.drainer.Wait(timeout)
+	if err != nil {
+		o.logger.Warn("Abandoning in-flight challenges", "err", err)
+	}
+	o.logger.Info("Challenger shut down")
+	if serr := core.SyncLogger(o.logger); serr != nil && err == nil {
+		err = serr
+	}
+	return err
+}
+
+// ProcessNewTaskCreatedLog builds the challenge request skeleton for the task announced by the TaskCreated event.
+// It returns an error if the task type named in the task name is not registered.
+func (o *Challenger) ProcessNewTaskCreatedLog(e *avs.ContracthelloWorldTaskCreated) (*avs.AvsServiceContractChallengeReq, error) {
 	o.logger.Info("New Task Created", "TaskID", e.TaskId.Uint64(),
 		"Issuer", e.Issuer.String(), "Name", e.Name, "NumberToBeSquared", e.NumberToBeSquared)
+	if _, err := core.TaskTypeForName(e.Name); err != nil {
+		return nil, err
+	}
 	task := &avs.AvsServiceContractChallengeReq{
 		TaskId:            e.TaskId.Uint64(),
 		NumberToBeSquared: e.NumberToBeSquared,
 	}
-	return task
+	return task, nil
+}
+
+// verifyTaskResponses checks every submitted response: the reveal against the commitment,
+// the BLS signature against the registered public key and the answer with the verifier of
+// the task type. It logs and returns the checks each operator failed. The contract applies its
+// own rules when the challenge is resolved, so this is informational only.
+func (o *Challenger) verifyTaskResponses(ctx context.Context, task avs.AvsServiceContractChallengeReq, taskInfo avs.TaskInfo) []verify.Failure {
+	failures := verify.Responses(task.Infos, o.registeredPubkey(ctx))
+	taskType, err := core.TaskTypeForName(taskInfo.Name)
+	if err != nil {
+		o.logger.Error("Unsupported task type", "name", taskInfo.Name, "err", err)
+	} else if input, err := taskType.DecodeInput(task.NumberToBeSquared); err != nil {
+		o.logger.Error("Cannot decode task input", "taskType", taskType.Name(), "err", err)
+	} else {
+		for _, info := range task.Infos {
+			ok, err := taskType.Verify(task.TaskId, input, info.TaskResponse)
+			if err == nil && !ok {
+				err = fmt.Errorf("response is not a correct %s answer", taskType.Name())
+			}
+			if err != nil {
+				failures = append(failures, verify.Failure{Operator: info.OperatorAddress, Check: verify.CheckAnswer, Reason: err.Error()})
+			}
+		}
+	}
+	for _, f := range failures {
+		if f.Check == verify.CheckLookup {
+			// not a fault of the response, its signature is reported unchecked
+			o.logger.Error("Cannot verify the signature of the task response",
+				"taskId", task.TaskId, "operator", f.Operator.String(), "reason", f.Reason)
+			continue
+		}
+		o.logger.Info("Operator task response failed a check",
+			"taskId", task.TaskId, "operator", f.Operator.String(), "check", f.Check, "reason", f.Reason)
+		o.metrics.ResponseCheckFailures.WithLabelValues(f.Check).Inc()
+	}
+	return failures
+}
+
+// registeredPubkey looks up the BLS public keys operators registered for the AVS.
+func (o *Challenger) registeredPubkey(ctx context.Context) verify.PubkeyLookup {
+	return func(op common.Address) ([]byte, error) {
+		return o.avsReader.GetRegisteredPubkey(&bind.CallOpts{Context: ctx}, op.String(), o.avsAddr.String())
+	}
+}
+
+// raiseChallenge checks the responses to the task, simulates the challenge and sends it
+// unless it would revert or only simulations are requested, then writes the verdict report
+// of the task. It returns the result the challenge counts as. sendCtx bounds the transaction,
+// createdAt is the block of the TaskCreated event of the task.
+func (o *Challenger) raiseChallenge(
+	ctx, sendCtx context.Context,
+	task avs.AvsServiceContractChallengeReq,
+	taskInfo avs.TaskInfo,
+	createdAt uint64,
+) (string, error) {
+	failures := o.verifyTaskResponses(ctx, task, taskInfo)
+	o.logger.Info("Challenge-task-req", "task", task, "failures", failures)
+	rep := report.Build(task, taskInfo, failures)
+
+	result, receipt, err := o.sendChallenge(ctx, sendCtx, task, taskInfo, createdAt)
+	rep.Result = result
+	if err != nil {
+		rep.Error = err.Error()
+	}
+	if receipt != nil {
+		rep.TxHash, rep.ReceiptStatus = receipt.TxHash.Hex(), "success"
+		if receipt.Status != ethtypes.ReceiptStatusSuccessful {
+			rep.ReceiptStatus = "reverted"
+		}
+	}
+	o.writeReport(ctx, rep)
+	return result, err
+}
+
+// sendChallenge simulates the challenge of task and sends it unless it would revert or only
+// simulations are requested. It returns the result the challenge counts as and the receipt of
+// the challenge, if one was sent. A challenge that reverts counts as resolved if the chain shows
+// that the task was challenged since createdAt.
+func (o *Challenger) sendChallenge(
+	ctx, sendCtx context.Context,
+	task avs.AvsServiceContractChallengeReq,
+	taskInfo avs.TaskInfo,
+	createdAt uint64,
+) (string, *ethtypes.Receipt, error) {
+	if err := o.preflightChallenge(ctx, task, taskInfo, createdAt); err != nil {
+		if errors.Is(err, errAlreadyChallenged) {
+			o.logger.Info("Task was already challenged", "taskId", task.TaskId, "err", err)
+			return metrics.ResultResolved, nil, nil
+		}
+		o.logger.Error("Challenge preflight failed, not sending", "taskId", task.TaskId, "err", err)
+		return metrics.ResultFailed, nil, err
+	}
+	if o.config.ChallengerSimulateOnly {
+		o.logger.Info("Simulate only, not sending the challenge", "taskId", task.TaskId)
+		return metrics.ResultSimulated, nil, nil
+	}
+
+	receipt, err := o.avsWriter.Challenge(sendCtx, task)
+	if (err != nil || receipt.Status != ethtypes.ReceiptStatusSuccessful) && o.challengeResolved(ctx, task, createdAt) {
+		// another challenger got there first, the task is resolved all the same
+		o.logger.Info("Task was already challenged", "taskId", task.TaskId, "err", err)
+		return metrics.ResultResolved, receipt, nil
+	}
+	if err != nil {
+		o.logger.Error("Challenger failed to raiseAndResolveChallenge", "err", err)
+		return metrics.ResultFailed, nil, fmt.Errorf("failed to raiseAndResolveChallenge: %w", err)
+	}
+	if receipt.Status != ethtypes.ReceiptStatusSuccessful {
+		o.logger.Error("Challenge transaction reverted", "taskId", task.TaskId, "tx", receipt.TxHash.Hex())
+		return metrics.ResultFailed, receipt, fmt.Errorf("%w: tx %s reverted", errChallengeReverts, receipt.TxHash.Hex())
+	}
+	return metrics.ResultRaised, receipt, nil
+}
+
+// writeReport writes the verdict report of a task and the evidence bundles of the operators it
+// slashes to challenge_report_dir. Failures are logged, they do not affect the challenge.
+func (o *Challenger) writeReport(ctx context.Context, rep report.Report) {
+	evidence := rep.Evidence(o.registeredPubkey(ctx))
+	for _, e := range evidence {
+		if e.PubkeyError != "" {
+			o.logger.Error("Evidence bundle lacks the registered public key", "taskId", rep.Task.ID, "operator", e.Operator.String(), "err", e.PubkeyError)
+		}
+	}
+	if err := report.Write(o.reportDir, rep, evidence); err != nil {
+		o.logger.Error("Cannot write challenge report", "taskId", rep.Task.ID, "dir", o.reportDir, "err", err)
+		return
+	}
+	o.logger.Info("Wrote challenge report", "taskId", rep.Task.ID, "dir", o.reportDir, "result", rep.Result, "slashed", len(evidence))
 }
 
+// TriggerChallenge waits for the challenge window of the task and raises the challenge, unless
+// the task is already resolved. createdAt is the block of its TaskCreated event, challenge
+// events are looked for from there. The epoch is polled every epochPollInterval, and steps that
+// fail for a reason other than the challenge reverting are retried until the challenge period
+// of the task is over. It only returns an error if ctx is canceled before the task is finished.
 func (o *Challenger) TriggerChallenge(
 	ctx context.Context,
 	task avs.AvsServiceContractChallengeReq,
-	taskInfo avs.TaskInfo) (string, error) {
+	taskInfo avs.TaskInfo,
+	createdAt uint64) (string, error) {
 	o.logger.Info("TriggerChallenge", "taskInfo", taskInfo)
-	epochIdentifier, err := o.avsReader.GetAVSEpochIdentifier(&bind.CallOpts{}, o.avsAddr.String())
-	startingEpoch := taskInfo.StartingEpoch
-	taskResponsePeriod := taskInfo.TaskResponsePeriod
-	taskStatisticalPeriod := taskInfo.TaskStatisticalPeriod
+
+	ticker := time.NewTicker(epochPollInterval)
+	defer ticker.Stop()
+
 	for {
+		result, finished, err := o.stepChallenge(ctx, task, taskInfo, createdAt)
+		if finished {
+			return result, nil
+		}
+		if err != nil {
+			o.logger.Error("Challenge step failed, retrying", "taskId", task.TaskId, "err", err)
+		}
+
 		select {
 		case <-ctx.Done():
 			return "", ctx.Err() // Gracefully exit if context is canceled
-		default:
-			// Fetch the current epoch information
-			if err != nil {
-				o.logger.Error("Cannot GetAVSEpochIdentifier", "err", err)
-				return "", fmt.Errorf("failed to get AVS info: %w", err) // Stop on persistent error
-			}
-
-			num, err := o.avsReader.GetCurrentEpoch(&bind.CallOpts{}, epochIdentifier)
-			if err != nil {
-				o.logger.Error("Cannot exec GetCurrentEpoch", "err", err)
-				return "", fmt.Errorf("failed to get current epoch: %w", err) // Stop on persistent error
-			}
-
-			currentEpoch := uint64(num)
-			// o.logger.Info("current epoch  is :", "currentEpoch", currentEpoch)
-			if currentEpoch > startingEpoch+taskResponsePeriod+taskStatisticalPeriod {
-				// ReQuery the latest taskInfo
-				taskInfo, err = o.avsReader.GetTaskInfo(&bind.CallOpts{}, taskInfo.TaskContractAddress.String(), taskInfo.TaskID)
-				if err != nil {
-					o.logger.Error("Failed to GetTaskInfo", "err", err)
-					return "", nil
-				}
-				o.logger.Info("latest-taskInfo", "taskInfo", taskInfo)
-
-				infos, _ := o.avsReader.GetOperatorTaskResponseList(&bind.CallOpts{}, taskInfo.TaskContractAddress.String(), taskInfo.TaskID)
-				task.TaskAddress = taskInfo.TaskContractAddress
-				task.Infos = infos
-				task.SignedOperators = taskInfo.SignedOperators
-				task.NoSignedOperators = taskInfo.NoSignedOperators
-				task.TaskTotalPower = taskInfo.TaskTotalPower
-
-				if taskInfo.IsExpected {
-					o.logger.Infof("Task %d is expected. Skipping challenge", task.TaskId)
-					return "", nil
-				}
-				if len(taskInfo.OptInOperators) < 1 {
-					o.logger.Infof("Task %d does not have any optIn operators. Skipping challenge", task.TaskId)
-					return "", nil
-				}
-
-				o.logger.Info("Execute raiseAndResolveChallenge", "currentEpoch", currentEpoch,
-					"startingEpoch", startingEpoch, "taskResponsePeriod", taskResponsePeriod, "taskStatisticalPeriod", taskStatisticalPeriod)
-				o.logger.Info("Challenge-task-req", "task", task)
-
-				_, err := o.avsWriter.Challenge(
-					ctx,
-					task)
-				if err != nil {
-					o.logger.Error("Challenger failed to raiseAndResolveChallenge", "err", err)
-					return "", fmt.Errorf("failed to raiseAndResolveChallenge: %w", err)
-				}
-				o.logger.Infof("The current task %s has been challenged:",
-					taskInfo.TaskContractAddress.String()+"--"+strconv.FormatUint(taskInfo.TaskID, 10))
-				return "The current task has been challenged .", nil
-			}
+		case <-ticker.C:
 		}
 	}
 }
-func (o *Challenger) parseEvent(vLog ethtypes.Log) (interface{}, error) {
 
-	vLog.Topics[0] = o.contractABI.Events["TaskCreated"].ID
+// stepChallenge checks the current epoch once and challenges the task if its statistical period
+// is over. It reports whether the task is finished: challenged, skipped, already resolved, its
+// challenge reverts or its challenge period is over. An error means the step is to be retried.
+func (o *Challenger) stepChallenge(
+	ctx context.Context,
+	task avs.AvsServiceContractChallengeReq,
+	taskInfo avs.TaskInfo,
+	createdAt uint64) (string, bool, error) {
+	startingEpoch := taskInfo.StartingEpoch
+	taskResponsePeriod := taskInfo.TaskResponsePeriod
+	taskStatisticalPeriod := taskInfo.TaskStatisticalPeriod
 
-	var event avs.ContracthelloWorldTaskCreated
+	num, err := o.avsReader.GetCurrentEpoch(&bind.CallOpts{Context: ctx}, o.epochIdentifier)
+	if err != nil {
+		return "", false, fmt.Errorf("failed to get current epoch: %w", err)
+	}
+	currentEpoch := uint64(num)
+	if currentEpoch <= startingEpoch+taskResponsePeriod+taskStatisticalPeriod {
+		return "", false, nil
+	}
+	if currentEpoch > startingEpoch+taskResponsePeriod+taskStatisticalPeriod+taskInfo.TaskChallengePeriod {
+		o.logger.Error("Task is past its challenge period, giving up", "taskId", task.TaskId, "currentEpoch", currentEpoch)
+		o.metrics.Challenges.WithLabelValues(metrics.ResultSkipped).Inc()
+		return "", true, nil
+	}
 
-	err := o.contractABI.UnpackIntoInterface(&event, "TaskCreated", vLog.Data)
+	// ReQuery the latest taskInfo
+	taskInfo, err = o.avsReader.GetTaskInfo(&bind.CallOpts{Context: ctx}, taskInfo.TaskContractAddress.String(), taskInfo.TaskID)
 	if err != nil {
-		return nil, err
+		return "", false, fmt.Errorf("failed to get task info: %w", err)
+	}
+	o.logger.Info("latest-taskInfo", "taskInfo", taskInfo)
+
+	infos, err := o.avsReader.GetOperatorTaskResponseList(&bind.CallOpts{Context: ctx}, taskInfo.TaskContractAddress.String(), taskInfo.TaskID)
+	if err != nil {
+		return "", false, fmt.Errorf("failed to get task responses: %w", err)
+	}
+	task.TaskAddress = taskInfo.TaskContractAddress
+	task.Infos = infos
+	task.SignedOperators = taskInfo.SignedOperators
+	task.NoSignedOperators = taskInfo.NoSignedOperators
+	task.TaskTotalPower = taskInfo.TaskTotalPower
+
+	if taskInfo.IsExpected {
+		o.logger.Infof("Task %d is expected. Skipping challenge", task.TaskId)
+		o.metrics.Challenges.WithLabelValues(metrics.ResultSkipped).Inc()
+		return "", true, nil
+	}
+	if len(taskInfo.OptInOperators) < 1 {
+		o.logger.Infof("Task %d does not have any optIn operators. Skipping challenge", task.TaskId)
+		o.metrics.Challenges.WithLabelValues(metrics.ResultSkipped).Inc()
+		return "", true, nil
+	}
+
+	if o.challengeResolved(ctx, task, createdAt) {
+		o.metrics.Challenges.WithLabelValues(metrics.ResultResolved).Inc()
+		return "", true, nil
+	}
+
+	o.logger.Info("Execute raiseAndResolveChallenge", "currentEpoch", currentEpoch,
+		"startingEpoch", startingEpoch, "taskResponsePeriod", taskResponsePeriod, "taskStatisticalPeriod", taskStatisticalPeriod)
+	// a challenge already being sent is given until the shutdown drain deadline
+	result, err := o.raiseChallenge(ctx, o.drainer.Context(), task, taskInfo, createdAt)
+	o.metrics.Challenges.WithLabelValues(result).Inc()
+	switch {
+	case errors.Is(err, errChallengeReverts):
+		// the contract does not accept the challenge, sending it again does not change that
+		return "", true, nil
+	case err != nil:
+		return "", false, err
+	case result != metrics.ResultRaised:
+		return "", true, nil
+	}
+	o.logger.Infof("The current task %s has been challenged:",
+		taskInfo.TaskContractAddress.String()+"--"+strconv.FormatUint(taskInfo.TaskID, 10))
+	return "The current task has been challenged .", true, nil
+}
+
+// challengeResolved reports whether the task was already challenged, by another challenger or
+// by this one before a restart: GetChallengeInfo records a challenger, or a TaskResolved or
+// ChallengeInitiated event of the task was emitted since block from. Challenge events are not
+// looked for if from is 0. Lookup errors are logged and the task is treated as unresolved, the
+// contract has the last word.
+func (o *Challenger) challengeResolved(ctx context.Context, task avs.AvsServiceContractChallengeReq, from uint64) bool {
+	challenger, err := o.avsReader.GetChallengeInfo(&bind.CallOpts{Context: ctx}, o.avsAddr.String(), task.TaskId)
+	switch {
+	case err != nil:
+		o.logger.Error("Cannot GetChallengeInfo", "taskId", task.TaskId, "err", err)
+	case challenger != (common.Address{}):
+		o.logger.Info("Task is already resolved", "taskId", task.TaskId, "challenger", challenger.String())
+		return true
+	}
+	if from == 0 {
+		return false
+	}
+	event, err := chain.FindChallengeEvent(ctx, o.ethClient, o.avsAddr, o.avsManager, task.TaskId, from)
+	if err != nil {
+		o.logger.Error("Cannot look up challenge events", "taskId", task.TaskId, "err", err)
+		return false
+	}
+	if event != nil {
+		o.logger.Info("Task is already resolved",
+			"taskId", task.TaskId, "block", event.BlockNumber, "tx", event.TxHash.String())
+		return true
+	}
+	return false
+}
+
+// healthChecks are the readiness conditions the node api reports for the challenger.
+func (o *Challenger) healthChecks() []health.Check {
+	return []health.Check{
+		{
+			ID:          "ws-subscription",
+			Name:        "Log subscription",
+			Description: "websocket subscription to the AVS contract logs is established",
+			Probe:       o.subscriptionUp.Probe("log subscription is not established"),
+		},
+		{
+			ID:          "chain-head",
+			Name:        "Chain head",
+			Description: "the node answers and its head block is recent",
+			Probe:       health.HeadRecent(o.ethClient, health.MaxHeadAge(o.config.HealthMaxHeadAge)),
+		},
 	}
-	return &event, nil
 }
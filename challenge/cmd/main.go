@@ -3,6 +3,7 @@ package main
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"os"
@@ -10,15 +11,21 @@ import (
 	"github.com/urfave/cli"
 
 	"github.com/imua-xyz/imua-avs/challenge"
+	"github.com/imua-xyz/imua-avs/core"
 	"github.com/imua-xyz/imua-avs/core/config"
 	"github.com/imua-xyz/imua-avs/types"
 
 	sdkutils "github.com/imua-xyz/imua-avs-sdk/utils"
 )
 
+var simulateOnlyFlag = cli.BoolFlag{
+	Name:  "simulate-only",
+	Usage: "simulate challenges with eth_call and log the outcome without sending them, overrides challenger_simulate_only",
+}
+
 func main() {
 	app := cli.NewApp()
-	app.Flags = []cli.Flag{config.FileFlag, config.TaskIDFlag, config.NumberToBeSquaredFlag, config.ExecTypeFlag}
+	app.Flags = []cli.Flag{config.FileFlag, config.TaskIDFlag, config.NumberToBeSquaredFlag, config.ExecTypeFlag, simulateOnlyFlag}
 	app.Name = "hello-world-demo-challenge"
 	app.Usage = "hello-world-demo Challenge"
 	app.Description = "Service that challenger listens to AVS contract events, Initiate challenges and validate the tasks already submitted by the operator."
@@ -38,6 +45,9 @@ func challengeMain(ctx *cli.Context) error {
 	if err != nil {
 		return err
 	}
+	if ctx.GlobalBool(simulateOnlyFlag.Name) {
+		nodeConfig.ChallengerSimulateOnly = true
+	}
 	configJson, err := json.MarshalIndent(nodeConfig, "", "  ")
 	if err != nil {
 		log.Fatalf(err.Error())
@@ -50,13 +60,14 @@ func challengeMain(ctx *cli.Context) error {
 		return err
 	}
 	log.Println("initialized challenge")
+	runCtx, stop := core.SignalContext(context.Background())
+	defer stop()
+
+	var runErr error
 	execType := ctx.Int(config.ExecTypeFlag.Name)
 	if execType == 1 {
-		err = challenger.Start(context.Background())
-		if err != nil {
-			return err
-		}
 		log.Println("challenger started")
+		runErr = challenger.Start(runCtx)
 	}
 	if execType == 2 {
 		taskID := ctx.Uint64(config.TaskIDFlag.Name)
@@ -65,11 +76,14 @@ func challengeMain(ctx *cli.Context) error {
 			return fmt.Errorf("task ID and Number to be squared must be provided")
 		}
 		log.Println("starting manual challenge")
-		err = challenger.Exec(context.Background(), taskID, numBeSquared)
-		if err != nil {
-			return err
+		runErr = challenger.Exec(runCtx, taskID, numBeSquared)
+		if runErr == nil {
+			log.Println("challenger completed successfully")
 		}
-		log.Println("challenger completed successfully")
+	}
+	shutdownErr := challenger.Shutdown(core.ShutdownTimeout(nodeConfig.ShutdownTimeout))
+	if code := core.ExitCode(runErr, shutdownErr); code != core.ExitOK {
+		return cli.NewExitError(fmt.Sprintf("challenger stopped: %v", errors.Join(runErr, shutdownErr)), code)
 	}
 	return nil
 }
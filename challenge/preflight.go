@@ -0,0 +1,77 @@
+package challenge
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/imua-xyz/imua-avs/challenge/resolve"
+	avs "github.com/imua-xyz/imua-avs/contracts/bindings/avs"
+	chain "github.com/imua-xyz/imua-avs/core/chainio"
+)
+
+var (
+	// errAlreadyChallenged is returned by the preflight if the challenge reverts and the chain
+	// shows that the task was already challenged.
+	errAlreadyChallenged = errors.New("task was already challenged")
+	// errChallengeReverts is returned by the preflight if the challenge reverts for another reason.
+	errChallengeReverts = errors.New("challenge reverts")
+)
+
+// Preflight is the outcome of simulating the challenge of a task before it is sent: the
+// simulated call, and the approval rate and the reward and slash lists the contract computes
+// and hands to the AVS manager, as predicted by package resolve.
+type Preflight struct {
+	TaskID uint64 `json:"taskId"`
+	chain.ChallengeSimulation
+	// Outcome is nil if resolve predicts that the challenge reverts.
+	Outcome *resolve.Outcome `json:"outcome,omitempty"`
+}
+
+// Preflight simulates raiseAndResolveChallenge for task with eth_call, with the calldata and
+// the sender a challenge would be sent with.
+func (o *Challenger) Preflight(ctx context.Context, task avs.AvsServiceContractChallengeReq, taskInfo avs.TaskInfo) (Preflight, error) {
+	sim, err := o.avsWriter.SimulateChallenge(ctx, task)
+	if err != nil {
+		return Preflight{}, err
+	}
+	p := Preflight{TaskID: task.TaskId, ChallengeSimulation: sim}
+	out, err := resolve.Resolve(taskInfo, task.NumberToBeSquared, task.Infos)
+	if err == nil {
+		p.Outcome = &out
+	}
+	if (err != nil) != sim.Reverted {
+		o.logger.Warn("Predicted challenge outcome disagrees with the simulation, the contract may have changed",
+			"taskId", task.TaskId, "reverted", sim.Reverted, "revertReason", sim.RevertReason, "prediction", err)
+	}
+	return p, nil
+}
+
+// preflightChallenge simulates the challenge of task and logs the outcome. It returns nil if
+// the challenge should be sent, errAlreadyChallenged or errChallengeReverts if it reverts.
+// Challenge events of the task are looked for from createdAt, the block it was created in.
+func (o *Challenger) preflightChallenge(
+	ctx context.Context,
+	task avs.AvsServiceContractChallengeReq,
+	taskInfo avs.TaskInfo,
+	createdAt uint64,
+) error {
+	p, err := o.Preflight(ctx, task, taskInfo)
+	if err != nil {
+		return fmt.Errorf("failed to simulate challenge: %w", err)
+	}
+	fields := []interface{}{"taskId", p.TaskID, "reverted", p.Reverted, "revertReason", p.RevertReason, "gas", p.Gas, "result", p.Result}
+	if p.Outcome != nil {
+		fields = append(fields, "approvalRate", p.Outcome.ApprovalRate, "meetsThreshold", p.Outcome.MeetsThreshold,
+			"rewardOperators", p.Outcome.RewardOperators, "slashOperators", p.Outcome.SlashOperators)
+	}
+	o.logger.Info("Challenge preflight", fields...)
+	switch {
+	case !p.Reverted:
+		return nil
+	case o.challengeResolved(ctx, task, createdAt):
+		return fmt.Errorf("%w: %s", errAlreadyChallenged, p.RevertReason)
+	default:
+		return fmt.Errorf("%w: %s", errChallengeReverts, p.RevertReason)
+	}
+}
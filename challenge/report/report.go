@@ -0,0 +1,349 @@
+// Package report records the verdict of every task the challenger handles, as a JSON report and
+// a CSV table with one row per operator, and an evidence bundle for every operator the challenge
+// slashes that can be handed to the operator if it disputes the result.
+package report
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/imua-xyz/imua-avs/challenge/resolve"
+	"github.com/imua-xyz/imua-avs/challenge/verify"
+	avs "github.com/imua-xyz/imua-avs/contracts/bindings/avs"
+	"github.com/imua-xyz/imua-avs/core"
+)
+
+// Signature is the outcome of checking the BLS signature of a response.
+type Signature string
+
+const (
+	// SignatureValid means the signature verifies against the registered public key.
+	SignatureValid Signature = "valid"
+	// SignatureInvalid means it does not, see the failures of the operator for why.
+	SignatureInvalid Signature = "invalid"
+	// SignatureUnchecked means the response was not revealed, does not decode or the public key
+	// of the operator could not be looked up.
+	SignatureUnchecked Signature = "unchecked"
+)
+
+// Classification is what the challenge does with an operator.
+type Classification string
+
+const (
+	// Reward and Slash are the lists of raiseAndResolveChallenge the operator is in.
+	Reward Classification = "reward"
+	Slash  Classification = "slash"
+	// None is used for operators in neither list, and for everyone if the challenge reverts.
+	None Classification = "none"
+)
+
+// slashRule explains in evidence bundles why a response is slashed.
+const slashRule = "raiseAndResolveChallenge decodes the response as abi (uint64, uint64) and slashes the operator " +
+	"unless the second word equals numberToBeSquared squared"
+
+// Task are the parameters of the challenged task.
+type Task struct {
+	ID                  uint64         `json:"id"`
+	Address             common.Address `json:"address"`
+	Name                string         `json:"name"`
+	Hash                hexutil.Bytes  `json:"hash"`
+	NumberToBeSquared   uint64         `json:"number_to_be_squared"`
+	ExpectedAnswer      string         `json:"expected_answer"`
+	ThresholdPercentage uint8          `json:"threshold_percentage"`
+	TotalPower          string         `json:"total_power"`
+	StartingEpoch       uint64         `json:"starting_epoch"`
+	ResponsePeriod      uint64         `json:"response_period"`
+	StatisticalPeriod   uint64         `json:"statistical_period"`
+	ChallengePeriod     uint64         `json:"challenge_period"`
+}
+
+// Operator is the verdict on the response of one operator.
+type Operator struct {
+	Operator     common.Address `json:"operator"`
+	Phase        uint8          `json:"phase"`
+	Power        string         `json:"power"`
+	Response     hexutil.Bytes  `json:"response"`
+	ResponseHash string         `json:"response_hash"`
+	BlsSignature hexutil.Bytes  `json:"bls_signature"`
+	// Answer is the numberSquared the response decodes to, empty if it does not decode.
+	Answer         string           `json:"answer"`
+	AnswerCorrect  bool             `json:"answer_correct"`
+	Signature      Signature        `json:"signature"`
+	Classification Classification   `json:"classification"`
+	Failures       []verify.Failure `json:"failures"`
+}
+
+// Report is the verdict of the challenge of one task.
+type Report struct {
+	Task      Task       `json:"task"`
+	Operators []Operator `json:"operators"`
+	// ApprovalRate and MeetsThreshold are predicted by package resolve, Reverts is the reason
+	// it predicts the challenge to revert for instead.
+	ApprovalRate   uint8  `json:"approval_rate"`
+	MeetsThreshold bool   `json:"meets_threshold"`
+	Reverts        string `json:"reverts,omitempty"`
+	// Result is what the challenge counts as in challenger_challenges_total: raised, resolved,
+	// simulated or failed, and Error why it failed.
+	Result string `json:"result"`
+	Error  string `json:"error,omitempty"`
+	// TxHash and ReceiptStatus ("success" or "reverted") are set if a challenge was sent.
+	TxHash        string    `json:"tx_hash,omitempty"`
+	ReceiptStatus string    `json:"receipt_status,omitempty"`
+	GeneratedAt   time.Time `json:"generated_at"`
+}
+
+// Build assembles the report of the challenge req of the task described by taskInfo, from the
+// checks the responses failed.
+func Build(req avs.AvsServiceContractChallengeReq, taskInfo avs.TaskInfo, failures []verify.Failure) Report {
+	r := Report{
+		Task: Task{
+			ID:                  req.TaskId,
+			Address:             req.TaskAddress,
+			Name:                taskInfo.Name,
+			Hash:                taskInfo.Hash,
+			NumberToBeSquared:   req.NumberToBeSquared,
+			ExpectedAnswer:      expectedAnswer(req, taskInfo),
+			ThresholdPercentage: taskInfo.ThresholdPercentage,
+			TotalPower:          req.TaskTotalPower,
+			StartingEpoch:       taskInfo.StartingEpoch,
+			ResponsePeriod:      taskInfo.TaskResponsePeriod,
+			StatisticalPeriod:   taskInfo.TaskStatisticalPeriod,
+			ChallengePeriod:     taskInfo.TaskChallengePeriod,
+		},
+		Operators: []Operator{},
+	}
+	classifications := map[common.Address]Classification{}
+	outcome, err := resolve.Request(req)
+	if err != nil {
+		r.Reverts = err.Error()
+	} else {
+		r.ApprovalRate = outcome.ApprovalRate
+		r.MeetsThreshold = outcome.ApprovalRate >= taskInfo.ThresholdPercentage
+		for _, op := range outcome.RewardOperators {
+			classifications[op] = Reward
+		}
+		for _, op := range outcome.SlashOperators {
+			classifications[op] = Slash
+		}
+	}
+
+	for _, info := range req.Infos {
+		op := Operator{
+			Operator:       info.OperatorAddress,
+			Phase:          info.Phase,
+			Power:          "0",
+			Response:       info.TaskResponse,
+			ResponseHash:   info.TaskResponseHash,
+			BlsSignature:   info.BlsSignature,
+			Signature:      SignatureUnchecked,
+			Classification: None,
+			Failures:       []verify.Failure{},
+		}
+		if info.Power != nil {
+			op.Power = info.Power.String()
+		}
+		if res, _, err := core.DecodeBigTaskResponse(info.TaskResponse); err == nil {
+			op.Answer = res.NumberSquared.String()
+			op.AnswerCorrect = res.TaskID == req.TaskId && op.Answer == r.Task.ExpectedAnswer
+		}
+		if c, ok := classifications[info.OperatorAddress]; ok {
+			op.Classification = c
+		}
+		if info.Phase >= core.TaskPhaseTwo {
+			op.Signature = SignatureValid
+		}
+		for _, f := range failures {
+			if f.Operator != info.OperatorAddress {
+				continue
+			}
+			op.Failures = append(op.Failures, f)
+			switch f.Check {
+			case verify.CheckSignature:
+				op.Signature = SignatureInvalid
+			case verify.CheckDecode, verify.CheckLookup:
+				op.Signature = SignatureUnchecked
+			}
+		}
+		r.Operators = append(r.Operators, op)
+	}
+	return r
+}
+
+// expectedAnswer is the correct answer to the task, empty if its task type cannot solve it.
+func expectedAnswer(req avs.AvsServiceContractChallengeReq, taskInfo avs.TaskInfo) string {
+	taskType, err := core.TaskTypeForName(taskInfo.Name)
+	if err != nil {
+		return ""
+	}
+	input, err := taskType.DecodeInput(req.NumberToBeSquared)
+	if err != nil {
+		return ""
+	}
+	output, err := taskType.Solve(req.TaskId, input)
+	if err != nil {
+		return ""
+	}
+	return fmt.Sprint(output)
+}
+
+// Evidence is what a slashed operator is shown of the challenge that slashed it: its own
+// submission as recorded on chain, what it was checked against and the checks it failed.
+type Evidence struct {
+	Task     Task           `json:"task"`
+	Operator common.Address `json:"operator"`
+	Phase    uint8          `json:"phase"`
+	Power    string         `json:"power"`
+	// Response, ResponseHash and BlsSignature are the submission on chain, Digest the keccak256
+	// of the response, which the signature is over, and RegisteredPubkey the BLS public key of
+	// the operator it is verified with, empty if it could not be looked up, PubkeyError then
+	// says why.
+	Response         hexutil.Bytes    `json:"response"`
+	ResponseHash     string           `json:"response_hash"`
+	BlsSignature     hexutil.Bytes    `json:"bls_signature"`
+	Digest           hexutil.Bytes    `json:"digest"`
+	RegisteredPubkey hexutil.Bytes    `json:"registered_pubkey"`
+	PubkeyError      string           `json:"pubkey_error,omitempty"`
+	Answer           string           `json:"answer"`
+	ExpectedAnswer   string           `json:"expected_answer"`
+	Signature        Signature        `json:"signature"`
+	Failures         []verify.Failure `json:"failures"`
+	Rule             string           `json:"rule"`
+	TxHash           string           `json:"tx_hash,omitempty"`
+	ReceiptStatus    string           `json:"receipt_status,omitempty"`
+	GeneratedAt      time.Time        `json:"generated_at"`
+}
+
+// Evidence returns the evidence bundles of the operators r slashes, looking up their public
+// keys. A key that cannot be looked up is recorded in the bundle of its operator.
+func (r Report) Evidence(pubkey verify.PubkeyLookup) []Evidence {
+	var bundles []Evidence
+	for _, op := range r.Operators {
+		if op.Classification != Slash {
+			continue
+		}
+		var pubkeyErr string
+		key, err := pubkey(op.Operator)
+		if err != nil {
+			key, pubkeyErr = nil, fmt.Sprintf("failed to get the registered public key: %v", err)
+		}
+		bundles = append(bundles, Evidence{
+			Task:             r.Task,
+			Operator:         op.Operator,
+			Phase:            op.Phase,
+			Power:            op.Power,
+			Response:         op.Response,
+			ResponseHash:     op.ResponseHash,
+			BlsSignature:     op.BlsSignature,
+			Digest:           crypto.Keccak256(op.Response),
+			RegisteredPubkey: key,
+			PubkeyError:      pubkeyErr,
+			Answer:           op.Answer,
+			ExpectedAnswer:   r.Task.ExpectedAnswer,
+			Signature:        op.Signature,
+			Failures:         op.Failures,
+			Rule:             slashRule,
+			TxHash:           r.TxHash,
+			ReceiptStatus:    r.ReceiptStatus,
+			GeneratedAt:      r.GeneratedAt,
+		})
+	}
+	return bundles
+}
+
+// csvHeader are the columns of the CSV report, one row per operator.
+var csvHeader = []string{
+	"task_id", "task_address", "operator", "phase", "power", "response", "response_hash", "answer",
+	"expected_answer", "answer_correct", "signature", "classification", "failures", "approval_rate",
+	"result", "tx_hash", "receipt_status",
+}
+
+// Write writes r to dir as task-<id>.json and task-<id>.csv, and each evidence bundle as
+// task-<id>-evidence/<operator>.json. Existing files of the task are replaced, each file is
+// written to a temporary file first so that a reader never sees a partial one.
+func Write(dir string, r Report, evidence []Evidence) error {
+	if r.GeneratedAt.IsZero() {
+		r.GeneratedAt = time.Now().UTC()
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create report directory: %w", err)
+	}
+	base := filepath.Join(dir, "task-"+strconv.FormatUint(r.Task.ID, 10))
+	if err := writeJSON(base+".json", r); err != nil {
+		return err
+	}
+	if err := writeCSV(base+".csv", r); err != nil {
+		return err
+	}
+	if len(evidence) == 0 {
+		return nil
+	}
+	if err := os.MkdirAll(base+"-evidence", 0o755); err != nil {
+		return fmt.Errorf("failed to create evidence directory: %w", err)
+	}
+	for _, e := range evidence {
+		if e.GeneratedAt.IsZero() {
+			e.GeneratedAt = r.GeneratedAt
+		}
+		if err := writeJSON(filepath.Join(base+"-evidence", e.Operator.Hex()+".json"), e); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeJSON(path string, v interface{}) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFile(path, append(data, '\n'))
+}
+
+func writeCSV(path string, r Report) error {
+	var b strings.Builder
+	w := csv.NewWriter(&b)
+	if err := w.Write(csvHeader); err != nil {
+		return err
+	}
+	for _, op := range r.Operators {
+		failures := make([]string, 0, len(op.Failures))
+		for _, f := range op.Failures {
+			failures = append(failures, f.Check+": "+f.Reason)
+		}
+		if err := w.Write([]string{
+			strconv.FormatUint(r.Task.ID, 10), r.Task.Address.Hex(), op.Operator.Hex(),
+			strconv.Itoa(int(op.Phase)), op.Power, op.Response.String(), op.ResponseHash, op.Answer,
+			r.Task.ExpectedAnswer, strconv.FormatBool(op.AnswerCorrect), string(op.Signature),
+			string(op.Classification), strings.Join(failures, "; "), strconv.Itoa(int(r.ApprovalRate)),
+			r.Result, r.TxHash, r.ReceiptStatus,
+		}); err != nil {
+			return err
+		}
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return err
+	}
+	return writeFile(path, []byte(b.String()))
+}
+
+// writeFile replaces the file at path with data through a rename.
+func writeFile(path string, data []byte) error {
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
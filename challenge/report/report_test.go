@@ -0,0 +1,123 @@
+package report_test
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/imua-xyz/imua-avs/challenge/report"
+	"github.com/imua-xyz/imua-avs/challenge/verify"
+	avs "github.com/imua-xyz/imua-avs/contracts/bindings/avs"
+	"github.com/imua-xyz/imua-avs/core"
+)
+
+func TestReport(t *testing.T) {
+	honest, wrong := common.Address{1}, common.Address{2}
+	info := func(op common.Address, numberSquared uint64) avs.OperatorResInfo {
+		data, err := core.AbiEncode(core.TaskResponse{TaskID: 10, NumberSquared: numberSquared})
+		if err != nil {
+			t.Fatalf("Error encoding response: %v", err)
+		}
+		return avs.OperatorResInfo{OperatorAddress: op, TaskResponse: data, BlsSignature: []byte{7}, Power: big.NewInt(50), Phase: core.TaskPhaseTwo}
+	}
+	req := avs.AvsServiceContractChallengeReq{
+		TaskId:            10,
+		NumberToBeSquared: 12,
+		Infos:             []avs.OperatorResInfo{info(honest, 144), info(wrong, 143)},
+		SignedOperators:   []common.Address{honest, wrong},
+		TaskTotalPower:    "100",
+	}
+	taskInfo := avs.TaskInfo{TaskID: 10, Name: "square:task", ThresholdPercentage: 50, TaskTotalPower: "100"}
+	failures := []verify.Failure{
+		{Operator: wrong, Check: verify.CheckSignature, Reason: "does not verify"},
+		{Operator: wrong, Check: verify.CheckAnswer, Reason: "not the square"},
+	}
+
+	r := report.Build(req, taskInfo, failures)
+	r.Result, r.TxHash, r.ReceiptStatus = "raised", "0xabc", "success"
+	if r.Task.ExpectedAnswer != "144" || r.ApprovalRate != 50 || !r.MeetsThreshold || len(r.Operators) != 2 {
+		t.Fatalf("Unexpected report %+v", r)
+	}
+	if op := r.Operators[0]; op.Classification != report.Reward || !op.AnswerCorrect || op.Signature != report.SignatureValid {
+		t.Errorf("Expected the honest operator to be rewarded with a valid signature, but got %+v", op)
+	}
+	if op := r.Operators[1]; op.Classification != report.Slash || op.AnswerCorrect || op.Answer != "143" ||
+		op.Signature != report.SignatureInvalid || len(op.Failures) != 2 {
+		t.Errorf("Expected the wrong operator to be slashed with an invalid signature, but got %+v", op)
+	}
+
+	evidence := r.Evidence(func(common.Address) ([]byte, error) { return []byte{9}, nil })
+	if len(evidence) != 1 || evidence[0].Operator != wrong || evidence[0].BlsSignature[0] != 7 || evidence[0].TxHash != "0xabc" ||
+		evidence[0].RegisteredPubkey[0] != 9 || evidence[0].PubkeyError != "" {
+		t.Fatalf("Expected evidence for the slashed operator only, but got %+v", evidence)
+	}
+	// a bundle carries the signature recorded for its own operator, whatever the order of the rows
+	r.Operators[0].BlsSignature = []byte{8}
+	r.Operators[0], r.Operators[1] = r.Operators[1], r.Operators[0]
+	if reordered := r.Evidence(func(common.Address) ([]byte, error) { return []byte{9}, nil }); len(reordered) != 1 || reordered[0].BlsSignature[0] != 7 {
+		t.Errorf("Expected the signature of the slashed operator in its bundle, but got %+v", reordered)
+	}
+	r.Operators[0], r.Operators[1] = r.Operators[1], r.Operators[0]
+	failed := r.Evidence(func(common.Address) ([]byte, error) { return nil, errors.New("rpc down") })
+	if len(failed) != 1 || len(failed[0].RegisteredPubkey) != 0 || !strings.Contains(failed[0].PubkeyError, "rpc down") {
+		t.Errorf("Expected the lookup failure in the evidence bundle, but got %+v", failed)
+	}
+
+	dir := t.TempDir()
+	if err := report.Write(dir, r, evidence); err != nil {
+		t.Fatalf("Error writing report: %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "task-10.json"))
+	if err != nil {
+		t.Fatalf("Error reading report: %v", err)
+	}
+	var written report.Report
+	if err := json.Unmarshal(data, &written); err != nil || written.TxHash != "0xabc" || written.GeneratedAt.IsZero() {
+		t.Errorf("Expected the JSON report with the transaction, but got %+v (err %v)", written, err)
+	}
+	file, err := os.Open(filepath.Join(dir, "task-10.csv"))
+	if err != nil {
+		t.Fatalf("Error opening CSV report: %v", err)
+	}
+	defer file.Close()
+	rows, err := csv.NewReader(file).ReadAll()
+	if err != nil || len(rows) != 3 || rows[2][2] != wrong.Hex() || rows[2][11] != "slash" {
+		t.Errorf("Expected a header and a row per operator, but got %v (err %v)", rows, err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "task-10-evidence", wrong.Hex()+".json")); err != nil {
+		t.Errorf("Expected an evidence bundle for the slashed operator: %v", err)
+	}
+	if tmps, _ := filepath.Glob(filepath.Join(dir, "*.tmp")); len(tmps) != 0 {
+		t.Errorf("Expected no temporary files to be left, but got %v", tmps)
+	}
+}
+
+func TestReportSignatureUnchecked(t *testing.T) {
+	unreachable, garbled, forged := common.Address{1}, common.Address{2}, common.Address{3}
+	req := avs.AvsServiceContractChallengeReq{
+		TaskId: 10,
+		Infos: []avs.OperatorResInfo{
+			{OperatorAddress: unreachable, Phase: core.TaskPhaseTwo},
+			{OperatorAddress: garbled, Phase: core.TaskPhaseTwo},
+			{OperatorAddress: forged, Phase: core.TaskPhaseTwo},
+		},
+	}
+	failures := []verify.Failure{
+		{Operator: unreachable, Check: verify.CheckLookup, Reason: "rpc down"},
+		{Operator: garbled, Check: verify.CheckDecode, Reason: "response does not decode"},
+		{Operator: forged, Check: verify.CheckSignature, Reason: "does not verify"},
+	}
+	r := report.Build(req, avs.TaskInfo{TaskID: 10}, failures)
+	want := []report.Signature{report.SignatureUnchecked, report.SignatureUnchecked, report.SignatureInvalid}
+	for i, op := range r.Operators {
+		if op.Signature != want[i] {
+			t.Errorf("Expected the signature of %s to be %s, but got %s", op.Operator, want[i], op.Signature)
+		}
+	}
+}
@@ -0,0 +1,160 @@
+// Package resolve predicts how raiseAndResolveChallenge of AVS.sol resolves the challenge of a
+// task, so that its outcome can be explained before anything is sent. It mirrors the contract,
+// quirks included, and has to be kept in step with it.
+package resolve
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"math/bits"
+
+	"github.com/ethereum/go-ethereum/common"
+	avs "github.com/imua-xyz/imua-avs/contracts/bindings/avs"
+)
+
+// ErrReverts is returned if raiseAndResolveChallenge reverts for the request.
+var ErrReverts = errors.New("raiseAndResolveChallenge reverts")
+
+// The revert reasons of the contract, the Solidity panics as abi.UnpackRevert reports them.
+const (
+	reasonNoResponses    = "taskResponse length must be greater than 0"
+	reasonOverflow       = "arithmetic underflow or overflow"
+	reasonDivisionByZero = "division or modulo by zero"
+	reasonOutOfBounds    = "array out-of-bounds access"
+	reasonDecode         = "task response does not decode as (uint64,uint64)"
+)
+
+// wordSize is the size of an ABI word.
+const wordSize = 32
+
+// maxUint256 bounds the uint256 arithmetic of the contract.
+var maxUint256 = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 256), big.NewInt(1))
+
+// Outcome is what raiseAndResolveChallenge computes and hands to the challenge of the AVS manager.
+type Outcome struct {
+	// ApprovalRate is the power of the correct responses in percent of the total power of the
+	// task, truncated to uint8 as the contract does.
+	ApprovalRate uint8 `json:"approvalRate"`
+	// IsExpected is the isExpected the contract passes, which it always sets.
+	IsExpected bool `json:"isExpected"`
+	// MeetsThreshold is set if ApprovalRate reaches the threshold percentage of the task.
+	MeetsThreshold bool `json:"meetsThreshold"`
+	// RewardOperators responded with the square of the task input, SlashOperators did not, in
+	// the order of the responses. The zero addresses padding the fixed-size arrays of the
+	// contract are left out. Operators that did not sign are not slashed: the contract merges
+	// them into the slash list but discards the result.
+	RewardOperators []common.Address `json:"rewardOperators"`
+	SlashOperators  []common.Address `json:"slashOperators"`
+	// ApprovedPower is the power of the rewarded operators, TotalPower the total power of the task.
+	ApprovedPower *big.Int `json:"approvedPower"`
+	TotalPower    *big.Int `json:"totalPower"`
+}
+
+// Resolve predicts the challenge of the task described by taskInfo, whose input is
+// numberToBeSquared, with the responses infos, as the challenger builds the request.
+func Resolve(taskInfo avs.TaskInfo, numberToBeSquared uint64, infos []avs.OperatorResInfo) (Outcome, error) {
+	out, err := Request(avs.AvsServiceContractChallengeReq{
+		TaskId:            taskInfo.TaskID,
+		TaskAddress:       taskInfo.TaskContractAddress,
+		NumberToBeSquared: numberToBeSquared,
+		Infos:             infos,
+		SignedOperators:   taskInfo.SignedOperators,
+		NoSignedOperators: taskInfo.NoSignedOperators,
+		TaskTotalPower:    taskInfo.TaskTotalPower,
+	})
+	if err != nil {
+		return Outcome{}, err
+	}
+	out.MeetsThreshold = out.ApprovalRate >= taskInfo.ThresholdPercentage
+	return out, nil
+}
+
+// Request predicts raiseAndResolveChallenge for req as it is sent. MeetsThreshold is left
+// unset, the threshold is not part of the request.
+func Request(req avs.AvsServiceContractChallengeReq) (Outcome, error) {
+	if len(req.Infos) == 0 {
+		return Outcome{}, revert(reasonNoResponses)
+	}
+	totalPower, err := stringToUint(req.TaskTotalPower)
+	if err != nil {
+		return Outcome{}, err
+	}
+	rewardSize := len(req.SignedOperators)
+	slashSize := len(req.SignedOperators) + len(req.NoSignedOperators)
+	hi, expected := bits.Mul64(req.NumberToBeSquared, req.NumberToBeSquared)
+	if hi != 0 {
+		return Outcome{}, revert(reasonOverflow)
+	}
+
+	out := Outcome{ApprovedPower: new(big.Int), TotalPower: totalPower}
+	for _, info := range req.Infos {
+		numberSquared, err := decodeTaskRes(info.TaskResponse)
+		if err != nil {
+			return Outcome{}, err
+		}
+		if numberSquared != expected {
+			if len(out.SlashOperators) == slashSize {
+				return Outcome{}, revert(reasonOutOfBounds)
+			}
+			out.SlashOperators = append(out.SlashOperators, info.OperatorAddress)
+			continue
+		}
+		if len(out.RewardOperators) == rewardSize {
+			return Outcome{}, revert(reasonOutOfBounds)
+		}
+		out.RewardOperators = append(out.RewardOperators, info.OperatorAddress)
+		if info.Power != nil {
+			out.ApprovedPower.Add(out.ApprovedPower, info.Power)
+		}
+		if out.ApprovedPower.Cmp(maxUint256) > 0 {
+			return Outcome{}, revert(reasonOverflow)
+		}
+	}
+
+	rate := new(big.Int).Mul(out.ApprovedPower, big.NewInt(100))
+	if rate.Cmp(maxUint256) > 0 {
+		return Outcome{}, revert(reasonOverflow)
+	}
+	if totalPower.Sign() == 0 {
+		return Outcome{}, revert(reasonDivisionByZero)
+	}
+	rate.Div(rate, totalPower)
+	out.ApprovalRate = uint8(rate.And(rate, big.NewInt(0xff)).Uint64())
+	out.IsExpected = true
+	return out, nil
+}
+
+// decodeTaskRes returns the numberSquared decodeTaskRes of the contract decodes from data: the
+// second of two uint64 words, trailing data is ignored. Words that do not fit in uint64 revert.
+func decodeTaskRes(data []byte) (uint64, error) {
+	if len(data) < 2*wordSize {
+		return 0, revert(reasonDecode)
+	}
+	for _, word := range [][]byte{data[:wordSize], data[wordSize : 2*wordSize]} {
+		if !new(big.Int).SetBytes(word).IsUint64() {
+			return 0, revert(reasonDecode)
+		}
+	}
+	return new(big.Int).SetBytes(data[wordSize : 2*wordSize]).Uint64(), nil
+}
+
+// stringToUint parses s as stringToUint of the contract does, skipping every character that
+// is not a digit. Values beyond uint256 revert.
+func stringToUint(s string) (*big.Int, error) {
+	n := new(big.Int)
+	for _, c := range []byte(s) {
+		if c >= '0' && c <= '9' {
+			n.Mul(n, big.NewInt(10))
+			n.Add(n, big.NewInt(int64(c-'0')))
+			if n.Cmp(maxUint256) > 0 {
+				return nil, revert(reasonOverflow)
+			}
+		}
+	}
+	return n, nil
+}
+
+func revert(reason string) error {
+	return fmt.Errorf("%w: %s", ErrReverts, reason)
+}
@@ -0,0 +1,141 @@
+package resolve_test
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"math/big"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/imua-xyz/imua-avs/challenge/resolve"
+	avs "github.com/imua-xyz/imua-avs/contracts/bindings/avs"
+	"github.com/imua-xyz/imua-avs/core"
+)
+
+// contractFunctions are the functions of AVS.sol the package mirrors, with the sha256 of their
+// source. A change to one of them fails TestContractUnchanged until the package is updated.
+var contractFunctions = map[string]string{
+	"raiseAndResolveChallenge": "827b7b13bd5d8b3b74f54c4740ae3ea3fc0b1a28627205e5ca196373c0566d0e",
+	"decodeTaskRes":            "9b8d8610b178baca48c54b4aa4c99e7f881f6d8a183d00da63518c751952d8db",
+	"stringToUint":             "a295830d91735ccf021b104f88818acf2cc46c52105ca0f3ef62b54fdab05832",
+}
+
+func response(t *testing.T, op common.Address, numberSquared uint64, power int64) avs.OperatorResInfo {
+	t.Helper()
+	data, err := core.AbiEncode(core.TaskResponse{TaskID: 10, NumberSquared: numberSquared})
+	if err != nil {
+		t.Fatalf("Error encoding response: %v", err)
+	}
+	return avs.OperatorResInfo{OperatorAddress: op, TaskResponse: data, Power: big.NewInt(power), Phase: core.TaskPhaseTwo}
+}
+
+func TestResolve(t *testing.T) {
+	ops := []common.Address{{1}, {2}, {3}, {4}}
+	taskInfo := avs.TaskInfo{
+		TaskID:              10,
+		ThresholdPercentage: 60,
+		SignedOperators:     ops[:3],
+		NoSignedOperators:   ops[3:],
+		TaskTotalPower:      "400",
+	}
+	infos := []avs.OperatorResInfo{response(t, ops[0], 144, 150), response(t, ops[1], 143, 100), response(t, ops[2], 144, 100)}
+
+	out, err := resolve.Resolve(taskInfo, 12, infos)
+	if err != nil {
+		t.Fatalf("Error resolving: %v", err)
+	}
+	// 250 of 400 approved, the operator that did not sign is not slashed
+	if out.ApprovalRate != 62 || !out.MeetsThreshold || !out.IsExpected ||
+		len(out.RewardOperators) != 2 || out.RewardOperators[0] != ops[0] || out.RewardOperators[1] != ops[2] ||
+		len(out.SlashOperators) != 1 || out.SlashOperators[0] != ops[1] {
+		t.Errorf("Unexpected outcome %+v", out)
+	}
+
+	taskInfo.ThresholdPercentage = 63
+	if out, _ := resolve.Resolve(taskInfo, 12, infos); out.MeetsThreshold {
+		t.Error("Expected 62% to miss a threshold of 63%")
+	}
+
+	// the rate is truncated to uint8: 250 of 50 is 500%, 500 mod 256 is 244
+	taskInfo.TaskTotalPower = "5x0"
+	if out, err := resolve.Resolve(taskInfo, 12, infos); err != nil || out.ApprovalRate != 244 {
+		t.Errorf("Expected an approval rate of 244, but got %d (err %v)", out.ApprovalRate, err)
+	}
+
+	// a version 2 response decodes its taskID as numberSquared and is slashed
+	encoded, err := core.EncodeBigTaskResponse(core.BigTaskResponse{TaskID: 10, NumberSquared: new(big.Int).Lsh(big.NewInt(1), 64)})
+	if err != nil {
+		t.Fatalf("Error encoding response: %v", err)
+	}
+	v2 := avs.OperatorResInfo{OperatorAddress: ops[0], TaskResponse: encoded, Power: big.NewInt(1)}
+	if out, err := resolve.Resolve(taskInfo, 12, []avs.OperatorResInfo{v2}); err != nil || len(out.SlashOperators) != 1 {
+		t.Errorf("Expected the version 2 response to be slashed, but got %+v (err %v)", out, err)
+	}
+}
+
+func TestResolveReverts(t *testing.T) {
+	ops := []common.Address{{1}, {2}}
+	taskInfo := avs.TaskInfo{SignedOperators: ops[:1], TaskTotalPower: "100"}
+	correct := response(t, ops[0], 144, 10)
+	malformed := correct
+	malformed.TaskResponse = correct.TaskResponse[:40]
+
+	cases := map[string]struct {
+		taskInfo avs.TaskInfo
+		number   uint64
+		infos    []avs.OperatorResInfo
+	}{
+		"no responses":                        {taskInfo, 12, nil},
+		"input squared overflow":              {taskInfo, 1 << 32, []avs.OperatorResInfo{correct}},
+		"malformed response":                  {taskInfo, 12, []avs.OperatorResInfo{malformed}},
+		"zero total power":                    {avs.TaskInfo{SignedOperators: ops[:1]}, 12, []avs.OperatorResInfo{correct}},
+		"more correct responses than signers": {taskInfo, 12, []avs.OperatorResInfo{correct, response(t, ops[1], 144, 10)}},
+	}
+	for name, c := range cases {
+		if _, err := resolve.Resolve(c.taskInfo, c.number, c.infos); !errors.Is(err, resolve.ErrReverts) {
+			t.Errorf("%s: expected ErrReverts, but got %v", name, err)
+		}
+	}
+}
+
+func TestContractUnchanged(t *testing.T) {
+	source, err := os.ReadFile("../../contracts/src/helloWorld/AVS.sol")
+	if err != nil {
+		t.Fatalf("Error reading contract: %v", err)
+	}
+	for name, want := range contractFunctions {
+		body := functionSource(string(source), name)
+		if body == "" {
+			t.Errorf("Function %s not found in AVS.sol", name)
+			continue
+		}
+		sum := sha256.Sum256([]byte(body))
+		if got := hex.EncodeToString(sum[:]); got != want {
+			t.Errorf("Function %s of AVS.sol changed (sha256 %s), update the resolve package and its hash", name, got)
+		}
+	}
+}
+
+// functionSource returns the source of the Solidity function name, up to its closing brace.
+func functionSource(source, name string) string {
+	start := strings.Index(source, "function "+name+"(")
+	if start < 0 {
+		return ""
+	}
+	depth := 0
+	for i := start; i < len(source); i++ {
+		switch source[i] {
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				return source[start : i+1]
+			}
+		}
+	}
+	return ""
+}
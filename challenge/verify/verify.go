@@ -0,0 +1,123 @@
+// Package verify checks the task responses operators submitted before the challenger raises a
+// challenge, and records which operator failed which check and why.
+package verify
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	avs "github.com/imua-xyz/imua-avs/contracts/bindings/avs"
+	"github.com/imua-xyz/imua-avs/core"
+	"github.com/prysmaticlabs/prysm/v5/crypto/bls/blst"
+)
+
+// The checks a response can fail, the values of Failure.Check.
+const (
+	// CheckCommitment is the phase two reveal hashing to the phase one commitment.
+	CheckCommitment = "commit_reveal"
+	// CheckSignature is the BLS signature verifying against the registered public key.
+	CheckSignature = "bls_signature"
+	// CheckDecode is the revealed response decoding to the message the signature is over.
+	CheckDecode = "response_decode"
+	// CheckLookup is the registered public key being looked up, the signature is not checked
+	// if it fails.
+	CheckLookup = "lookup"
+	// CheckAnswer is the revealed response being a correct answer for the task.
+	CheckAnswer = "answer"
+)
+
+// Failure is a check the response of an operator failed.
+type Failure struct {
+	Operator common.Address `json:"operator"`
+	Check    string         `json:"check"`
+	Reason   string         `json:"reason"`
+}
+
+var (
+	// errUndecodable and errUnencodable are returned by Signature if the signed digest of the
+	// response cannot be computed.
+	errUndecodable = errors.New("response does not decode")
+	errUnencodable = errors.New("response does not encode")
+)
+
+// PubkeyLookup returns the BLS public key operator registered for the AVS, empty if it has none.
+type PubkeyLookup func(operator common.Address) ([]byte, error)
+
+// Responses checks the commitment and the signature of every response and returns the failures.
+// An operator whose public key cannot be looked up fails CheckLookup, the others are still checked.
+func Responses(infos []avs.OperatorResInfo, pubkey PubkeyLookup) []Failure {
+	var failures []Failure
+	for _, info := range infos {
+		fail := func(check string, err error) {
+			failures = append(failures, Failure{Operator: info.OperatorAddress, Check: check, Reason: err.Error()})
+		}
+		if err := Commitment(info); err != nil {
+			fail(CheckCommitment, err)
+		}
+		if info.Phase < core.TaskPhaseTwo {
+			// the signed message is only known once it is revealed
+			continue
+		}
+		key, err := pubkey(info.OperatorAddress)
+		if err != nil {
+			fail(CheckLookup, fmt.Errorf("failed to get the registered public key: %w", err))
+			continue
+		}
+		switch err := Signature(info, key); {
+		case errors.Is(err, errUndecodable), errors.Is(err, errUnencodable):
+			fail(CheckDecode, err)
+		case err != nil:
+			fail(CheckSignature, err)
+		}
+	}
+	return failures
+}
+
+// Commitment checks that the response was revealed in phase two and that it hashes to the
+// TaskResponseHash recorded for the operator.
+func Commitment(info avs.OperatorResInfo) error {
+	if info.Phase < core.TaskPhaseTwo {
+		return fmt.Errorf("response was not revealed, the last phase submitted is %d", info.Phase)
+	}
+	if info.TaskResponseHash == "" {
+		return errors.New("no phase one commitment is recorded")
+	}
+	revealed := crypto.Keccak256Hash(info.TaskResponse)
+	committed := strings.TrimPrefix(strings.ToLower(info.TaskResponseHash), "0x")
+	if committed != strings.TrimPrefix(revealed.Hex(), "0x") {
+		return fmt.Errorf("revealed response hashes to %s, the commitment is %s", revealed.Hex(), info.TaskResponseHash)
+	}
+	return nil
+}
+
+// Signature checks that the BLS signature of the response verifies against pubkey over the ABI
+// digest of the response, the digest of core.GetTaskResponseDigestEncodeByAbi for responses that
+// fit in uint64 and of core.GetBigTaskResponseDigest otherwise.
+func Signature(info avs.OperatorResInfo, pubkey []byte) error {
+	if len(pubkey) == 0 {
+		return errors.New("no BLS public key is registered")
+	}
+	res, _, err := core.DecodeBigTaskResponse(info.TaskResponse)
+	if err != nil {
+		return fmt.Errorf("%w: %v", errUndecodable, err)
+	}
+	digest, _, err := core.GetBigTaskResponseDigest(res)
+	if err != nil {
+		return fmt.Errorf("%w: %v", errUnencodable, err)
+	}
+	key, err := blst.PublicKeyFromBytes(pubkey)
+	if err != nil {
+		return fmt.Errorf("registered BLS public key is invalid: %w", err)
+	}
+	sig, err := blst.SignatureFromBytes(info.BlsSignature)
+	if err != nil {
+		return fmt.Errorf("BLS signature is invalid: %w", err)
+	}
+	if !sig.Verify(key, digest[:]) {
+		return errors.New("BLS signature does not verify against the registered public key")
+	}
+	return nil
+}
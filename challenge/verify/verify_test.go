@@ -0,0 +1,107 @@
+package verify_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/imua-xyz/imua-avs/challenge/verify"
+	avs "github.com/imua-xyz/imua-avs/contracts/bindings/avs"
+	"github.com/imua-xyz/imua-avs/core"
+	"github.com/prysmaticlabs/prysm/v5/crypto/bls/blst"
+	blscommon "github.com/prysmaticlabs/prysm/v5/crypto/bls/common"
+)
+
+// signedResponse is the response of operator to task 10 signed with key as the operator does it.
+func signedResponse(t *testing.T, operator common.Address, key blscommon.SecretKey, numberSquared uint64) avs.OperatorResInfo {
+	t.Helper()
+	_, response, err := core.GetTaskResponseDigestEncodeByAbi(core.TaskResponse{TaskID: 10, NumberSquared: numberSquared})
+	if err != nil {
+		t.Fatalf("Error encoding response: %v", err)
+	}
+	hash := crypto.Keccak256Hash(response)
+	return avs.OperatorResInfo{
+		TaskID:           10,
+		OperatorAddress:  operator,
+		TaskResponseHash: hash.Hex(),
+		TaskResponse:     response,
+		BlsSignature:     key.Sign(hash.Bytes()).Marshal(),
+		Phase:            core.TaskPhaseTwo,
+	}
+}
+
+func TestResponses(t *testing.T) {
+	keys := map[common.Address]blscommon.SecretKey{}
+	operator := func(i byte) common.Address {
+		addr := common.BytesToAddress([]byte{i})
+		key, err := blst.RandKey()
+		if err != nil {
+			t.Fatalf("Error generating bls key: %v", err)
+		}
+		keys[addr] = key
+		return addr
+	}
+	honest, tampered, unrevealed, forged, unregistered := operator(1), operator(2), operator(3), operator(4), operator(5)
+	garbled, unreachable := operator(6), operator(7)
+
+	tamperedInfo := signedResponse(t, tampered, keys[tampered], 56169)
+	_, tamperedInfo.TaskResponse, _ = core.GetTaskResponseDigestEncodeByAbi(core.TaskResponse{TaskID: 10, NumberSquared: 1})
+	forgedInfo := signedResponse(t, forged, keys[honest], 56169)
+	garbledInfo := signedResponse(t, garbled, keys[garbled], 56169)
+	garbledInfo.TaskResponse = []byte{1, 2, 3}
+	infos := []avs.OperatorResInfo{
+		signedResponse(t, honest, keys[honest], 56169),
+		tamperedInfo,
+		{OperatorAddress: unrevealed, BlsSignature: []byte{1}, Phase: core.TaskPhaseOne},
+		forgedInfo,
+		signedResponse(t, unregistered, keys[unregistered], 56169),
+		garbledInfo,
+		signedResponse(t, unreachable, keys[unreachable], 56169),
+	}
+	lookupErr := errors.New("rpc down")
+	lookup := func(op common.Address) ([]byte, error) {
+		switch op {
+		case unregistered:
+			return nil, nil
+		case unreachable:
+			return nil, lookupErr
+		}
+		return keys[op].PublicKey().Marshal(), nil
+	}
+
+	failures := verify.Responses(infos, lookup)
+	want := map[common.Address][]string{
+		tampered:     {verify.CheckCommitment, verify.CheckSignature},
+		unrevealed:   {verify.CheckCommitment},
+		forged:       {verify.CheckSignature},
+		unregistered: {verify.CheckSignature},
+		garbled:      {verify.CheckCommitment, verify.CheckDecode},
+		unreachable:  {verify.CheckLookup},
+	}
+	got := map[common.Address][]string{}
+	for _, f := range failures {
+		if f.Reason == "" {
+			t.Errorf("Failure %+v has no reason", f)
+		}
+		if f.Check == verify.CheckLookup && !strings.Contains(f.Reason, lookupErr.Error()) {
+			t.Errorf("Expected the lookup failure to carry the lookup error, but got %q", f.Reason)
+		}
+		got[f.Operator] = append(got[f.Operator], f.Check)
+	}
+	if len(got) != len(want) {
+		t.Fatalf("Expected failures %v, but got %v", want, got)
+	}
+	for op, checks := range want {
+		if len(got[op]) != len(checks) {
+			t.Errorf("Expected %s to fail %v, but got %v", op, checks, got[op])
+			continue
+		}
+		for i := range checks {
+			if got[op][i] != checks[i] {
+				t.Errorf("Expected %s to fail %v, but got %v", op, checks, got[op])
+			}
+		}
+	}
+}
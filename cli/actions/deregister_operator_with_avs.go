@@ -0,0 +1,43 @@
+package actions
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+
+	sdkutils "github.com/imua-xyz/imua-avs-sdk/utils"
+	"github.com/imua-xyz/imua-avs/core/config"
+	"github.com/imua-xyz/imua-avs/operator"
+	"github.com/imua-xyz/imua-avs/types"
+	"github.com/urfave/cli"
+)
+
+var ForceFlag = cli.BoolFlag{
+	Name:  "force",
+	Usage: "opt out even though the operator still owes task responses",
+}
+
+func DeregisterOperatorWithAvs(ctx *cli.Context) error {
+
+	configPath := ctx.GlobalString(config.FileFlag.Name)
+	nodeConfig := types.NodeConfig{}
+	err := sdkutils.ReadYamlConfig(configPath, &nodeConfig)
+	if err != nil {
+		return err
+	}
+	// need to make sure we don't register the operator on startup
+	// when using the cli commands to deregister the operator.
+	nodeConfig.RegisterOperatorOnStartup = false
+	configJson, err := json.MarshalIndent(nodeConfig, "", "  ")
+	if err != nil {
+		log.Fatalf(err.Error())
+	}
+	log.Println("Config:", string(configJson))
+
+	o, err := operator.NewCliOperatorFromConfig(nodeConfig)
+	if err != nil {
+		return err
+	}
+
+	return o.DeregisterOperatorWithAvs(context.Background(), ctx.String(AvsFlag.Name), ctx.Bool(ForceFlag.Name))
+}
@@ -0,0 +1,105 @@
+package actions
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	sdklogging "github.com/imua-xyz/imua-avs-sdk/logging"
+	sdkutils "github.com/imua-xyz/imua-avs-sdk/utils"
+	"github.com/imua-xyz/imua-avs/challenge/resolve"
+	chain "github.com/imua-xyz/imua-avs/core/chainio"
+	"github.com/imua-xyz/imua-avs/core/chainio/eth"
+	"github.com/imua-xyz/imua-avs/core/config"
+	"github.com/imua-xyz/imua-avs/types"
+	"github.com/urfave/cli"
+)
+
+// ExplainChallenge prints the outcome raiseAndResolveChallenge would have for a task, as
+// predicted from the responses on chain, without sending anything.
+func ExplainChallenge(ctx *cli.Context) error {
+	configPath := ctx.GlobalString(config.FileFlag.Name)
+	nodeConfig := types.NodeConfig{}
+	err := sdkutils.ReadYamlConfig(configPath, &nodeConfig)
+	if err != nil {
+		return err
+	}
+	output := ctx.String(OutputFlag.Name)
+	if output != "table" && output != "json" {
+		return fmt.Errorf("unknown --output %q, expected table or json", output)
+	}
+	taskID := ctx.Uint64(config.TaskIDFlag.Name)
+	numberToBeSquared := ctx.Uint64(config.NumberToBeSquaredFlag.Name)
+	if taskID == 0 {
+		return fmt.Errorf("--%s must be provided", config.TaskIDFlag.Name)
+	}
+
+	logger, err := sdklogging.NewZapLogger(sdklogging.Production)
+	if err != nil {
+		return err
+	}
+	ethClient, err := eth.NewClient(nodeConfig.EthRpcUrl)
+	if err != nil {
+		return err
+	}
+	avsAddr := common.HexToAddress(nodeConfig.AVSAddress)
+	reader, err := chain.BuildChainReader(avsAddr, ethClient, logger)
+	if err != nil {
+		return err
+	}
+	opts := &bind.CallOpts{Context: context.Background()}
+	taskInfo, err := reader.GetTaskInfo(opts, avsAddr.String(), taskID)
+	if err != nil {
+		return err
+	}
+	infos, err := reader.GetOperatorTaskResponseList(opts, avsAddr.String(), taskID)
+	if err != nil {
+		return err
+	}
+
+	out, resolveErr := resolve.Resolve(taskInfo, numberToBeSquared, infos)
+	if output == "json" {
+		report := struct {
+			TaskID  uint64           `json:"taskId"`
+			Outcome *resolve.Outcome `json:"outcome,omitempty"`
+			Reverts string           `json:"reverts,omitempty"`
+		}{TaskID: taskID}
+		if resolveErr != nil {
+			report.Reverts = resolveErr.Error()
+		} else {
+			report.Outcome = &out
+		}
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(report)
+	}
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "Task\t%d\n", taskID)
+	if resolveErr != nil {
+		fmt.Fprintf(tw, "Reverts\t%v\n", resolveErr)
+		return tw.Flush()
+	}
+	for _, row := range [][2]string{
+		{"Approval rate (%)", strconv.Itoa(int(out.ApprovalRate))},
+		{"Threshold (%)", strconv.Itoa(int(taskInfo.ThresholdPercentage))},
+		{"Meets threshold", strconv.FormatBool(out.MeetsThreshold)},
+		{"isExpected passed", strconv.FormatBool(out.IsExpected)},
+		{"Approved power", out.ApprovedPower.String()},
+		{"Total power", out.TotalPower.String()},
+	} {
+		fmt.Fprintf(tw, "%s\t%s\n", row[0], row[1])
+	}
+	for _, op := range out.RewardOperators {
+		fmt.Fprintf(tw, "Reward\t%s\n", op.Hex())
+	}
+	for _, op := range out.SlashOperators {
+		fmt.Fprintf(tw, "Slash\t%s\n", op.Hex())
+	}
+	return tw.Flush()
+}
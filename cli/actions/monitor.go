@@ -3,14 +3,17 @@ package actions
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/common"
 	ethtypes "github.com/ethereum/go-ethereum/core/types"
-	"github.com/ethereum/go-ethereum/ethclient"
+	sdklogging "github.com/imua-xyz/imua-avs-sdk/logging"
 	sdkutils "github.com/imua-xyz/imua-avs-sdk/utils"
 	avs "github.com/imua-xyz/imua-avs/contracts/bindings/avs"
+	chain "github.com/imua-xyz/imua-avs/core/chainio"
 	"github.com/imua-xyz/imua-avs/core/config"
+	"github.com/imua-xyz/imua-avs/core/network"
 	"github.com/imua-xyz/imua-avs/types"
 	"log"
 
@@ -33,97 +36,114 @@ func Monitor(ctx *cli.Context) error {
 	}
 	log.Println("Config:", string(configJson))
 
-	client, err := ethclient.Dial(nodeConfig.EthWsUrl)
+	logger, err := sdklogging.NewZapLogger(sdklogging.Development)
 	if err != nil {
-		log.Fatal("Connection failed:", err)
+		return err
+	}
+
+	profile, err := network.Load(nodeConfig.Network)
+	if err != nil {
+		return err
 	}
-	defer client.Close()
 
+	// the AVS contract emits TaskCreated and TaskResolved, the AVS manager precompile the events
+	// of every AVS, of which only the ones of this AVS are printed
 	contractAddress := common.HexToAddress(nodeConfig.AVSAddress)
 	query := ethereum.FilterQuery{
-		Addresses: []common.Address{contractAddress},
+		Addresses: []common.Address{contractAddress, profile.AVSManagerPrecompile},
 	}
 
 	logs := make(chan ethtypes.Log)
-	sub, err := client.SubscribeFilterLogs(context.Background(), query, logs)
-	if err != nil {
-		log.Fatal("Subscribe failed:", err)
-	}
-	defer sub.Unsubscribe()
+	subscriber := chain.NewLogSubscriber(nodeConfig.EthWsUrl, query, logger)
+	go subscriber.Run(context.Background(), logs)
 
 	fmt.Println("Starting event monitoring...")
 
-	for {
-		select {
-		case err := <-sub.Err():
-			log.Fatal("Subscription error:", err)
-		case vLog := <-logs:
-			// 解析日志
-			event, err := parseEvent(vLog)
-			if err != nil {
-				log.Printf("Parse error: %v", err)
-				continue
-			}
-
-			// 处理事件
-			switch e := event.(type) {
-			case *avs.ContracthelloWorldTaskCreated:
-				fmt.Printf("New Task Created:\n"+
-					"  TaskID: %v\n"+
-					"  Issuer: %s\n"+
-					"  Name: %s\n"+
-					"  Number: %d\n"+
-					"  Response Period: %d\n"+
-					"  Challenge Period: %d\n"+
-					"  Threshold: %d%%\n"+
-					"  Statistical Period: %d\n",
-					e.TaskId, e.Issuer.Hex(), e.Name, e.NumberToBeSquared,
-					e.TaskResponsePeriod, e.TaskChallengePeriod,
-					e.ThresholdPercentage, e.TaskStatisticalPeriod)
-
-			case *avs.ContracthelloWorldTaskResolved:
-				fmt.Printf("Task Resolved:\n"+
-					"  TaskID: %d\n"+
-					"  Address: %s\n",
-					e.TaskId, e.TaskAddress.Hex())
-			}
+	router := monitorEvents(contractAddress)
+	for vLog := range logs {
+		err := router.Dispatch(context.Background(), vLog)
+		if err != nil && !errors.Is(err, chain.ErrUnhandledEvent) {
+			log.Printf("Parse error: %v", err)
 		}
 	}
+	return nil
 }
 
-func parseEvent(vLog ethtypes.Log) (interface{}, error) {
-	contractABI, _ := avs.ContracthelloWorldMetaData.GetAbi()
-
-	switch vLog.Topics[0] {
-	case contractABI.Events["TaskCreated"].ID:
-		return parseTaskCreated(vLog)
-	case contractABI.Events["TaskResolved"].ID:
-		return parseTaskResolved(vLog)
-	default:
-		return nil, fmt.Errorf("unknown event type")
-	}
-}
-
-func parseTaskCreated(vLog ethtypes.Log) (*avs.ContracthelloWorldTaskCreated, error) {
-	var event avs.ContracthelloWorldTaskCreated
-	contractABI, _ := avs.ContracthelloWorldMetaData.GetAbi()
-
-	err := contractABI.UnpackIntoInterface(&event, "TaskCreated", vLog.Data)
-	if err != nil {
-		return nil, fmt.Errorf("failed to unpack TaskCreated: %v", err)
-	}
-
-	return &event, nil
-}
-
-func parseTaskResolved(vLog ethtypes.Log) (*avs.ContracthelloWorldTaskResolved, error) {
-	var event avs.ContracthelloWorldTaskResolved
-	contractABI, _ := avs.ContracthelloWorldMetaData.GetAbi()
-
-	err := contractABI.UnpackIntoInterface(&event, "TaskResolved", vLog.Data)
-	if err != nil {
-		return nil, fmt.Errorf("failed to unpack TaskResolved: %v", err)
-	}
-
-	return &event, nil
+// monitorEvents routes the events of the AVS at avsAddr to printers.
+func monitorEvents(avsAddr common.Address) *chain.EventRouter {
+	router := chain.NewEventRouter()
+	chain.OnEvent(router, func(_ context.Context, e *avs.ContracthelloWorldTaskCreated) error {
+		if e.Raw.Address != avsAddr {
+			return nil
+		}
+		fmt.Printf("New Task Created:\n"+
+			"  TaskID: %v\n"+
+			"  Issuer: %s\n"+
+			"  Name: %s\n"+
+			"  Number: %d\n"+
+			"  Response Period: %d\n"+
+			"  Challenge Period: %d\n"+
+			"  Threshold: %d%%\n"+
+			"  Statistical Period: %d\n",
+			e.TaskId, e.Issuer.Hex(), e.Name, e.NumberToBeSquared,
+			e.TaskResponsePeriod, e.TaskChallengePeriod,
+			e.ThresholdPercentage, e.TaskStatisticalPeriod)
+		return nil
+	})
+	chain.OnEvent(router, func(_ context.Context, e *avs.ContracthelloWorldTaskResolved) error {
+		if e.Raw.Address != avsAddr {
+			return nil
+		}
+		fmt.Printf("Task Resolved:\n"+
+			"  TaskID: %d\n"+
+			"  Address: %s\n",
+			e.TaskId, e.TaskAddress.Hex())
+		return nil
+	})
+	chain.OnEvent(router, func(_ context.Context, e *chain.TaskSubmittedByOperator) error {
+		if e.TaskContractAddress != avsAddr {
+			return nil
+		}
+		fmt.Printf("Task Submitted By Operator:\n"+
+			"  TaskID: %d\n"+
+			"  Operator: %s\n"+
+			"  Phase: %d\n"+
+			"  Response: 0x%x\n",
+			e.TaskID, e.Sender.Hex(), e.Phase, e.TaskResponse)
+		return nil
+	})
+	chain.OnEvent(router, func(_ context.Context, e *chain.ChallengeInitiated) error {
+		if e.TaskContractAddress != avsAddr {
+			return nil
+		}
+		fmt.Printf("Challenge Initiated:\n"+
+			"  TaskID: %d\n"+
+			"  Challenger: %s\n"+
+			"  Actual Threshold: %d%%\n"+
+			"  Expected: %t\n"+
+			"  Rewarded: %v\n"+
+			"  Slashed: %v\n",
+			e.TaskID, e.Sender.Hex(), e.ActualThreshold, e.IsExpected,
+			e.EligibleRewardOperators, e.EligibleSlashOperators)
+		return nil
+	})
+	chain.OnEvent(router, func(_ context.Context, e *chain.OperatorJoined) error {
+		if e.AvsAddress == avsAddr {
+			fmt.Printf("Operator Joined:\n  Operator: %s\n", e.Sender.Hex())
+		}
+		return nil
+	})
+	chain.OnEvent(router, func(_ context.Context, e *chain.OperatorLeft) error {
+		if e.AvsAddress == avsAddr {
+			fmt.Printf("Operator Left:\n  Operator: %s\n", e.Sender.Hex())
+		}
+		return nil
+	})
+	chain.OnEvent(router, func(_ context.Context, e *chain.PublicKeyRegistered) error {
+		if e.AvsAddress == avsAddr {
+			fmt.Printf("Public Key Registered:\n  Operator: %s\n", e.Sender.Hex())
+		}
+		return nil
+	})
+	return router
 }
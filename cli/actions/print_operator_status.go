@@ -1,8 +1,11 @@
 package actions
 
 import (
+	"context"
 	"encoding/json"
+	"fmt"
 	"log"
+	"os"
 
 	sdkutils "github.com/imua-xyz/imua-avs-sdk/utils"
 	"github.com/imua-xyz/imua-avs/core/config"
@@ -11,6 +14,17 @@ import (
 	"github.com/urfave/cli"
 )
 
+var OutputFlag = cli.StringFlag{
+	Name:  "output",
+	Usage: "output format, table or json",
+	Value: "table",
+}
+
+var AvsFlag = cli.StringFlag{
+	Name:  "avs",
+	Usage: "name or address of the AVS from operator_avs_list, required when the operator serves several",
+}
+
 func PrintOperatorStatus(ctx *cli.Context) error {
 
 	configPath := ctx.GlobalString(config.FileFlag.Name)
@@ -22,18 +36,24 @@ func PrintOperatorStatus(ctx *cli.Context) error {
 	// need to make sure we don't register the operator on startup
 	// when using the cli commands to register the operator.
 	nodeConfig.RegisterOperatorOnStartup = false
-	configJson, err := json.MarshalIndent(nodeConfig, "", "  ")
-	if err != nil {
-		log.Fatalf(err.Error())
+	output := ctx.String(OutputFlag.Name)
+	if output != "table" && output != "json" {
+		return fmt.Errorf("unknown --output %q, expected table or json", output)
+	}
+	if output != "json" {
+		configJson, err := json.MarshalIndent(nodeConfig, "", "  ")
+		if err != nil {
+			log.Fatalf(err.Error())
+		}
+		log.Println("Config:", string(configJson))
 	}
-	log.Println("Config:", string(configJson))
 
-	operator, err := operator.NewOperatorFromConfig(nodeConfig)
+	operator, err := operator.NewCliOperatorFromConfig(nodeConfig)
 	if err != nil {
 		return err
 	}
 
-	err = operator.PrintOperatorStatus()
+	err = operator.PrintOperatorStatus(context.Background(), os.Stdout, ctx.String(OutputFlag.Name), ctx.String(AvsFlag.Name))
 	if err != nil {
 		return err
 	}
@@ -30,7 +30,7 @@ func RegisterOperatorWithAvs(ctx *cli.Context) error {
 	}
 	log.Println("Config:", string(configJson))
 
-	o, err := operator.NewOperatorFromConfig(nodeConfig)
+	o, err := operator.NewCliOperatorFromConfig(nodeConfig)
 	if err != nil {
 		return err
 	}
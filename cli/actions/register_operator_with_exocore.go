@@ -28,7 +28,7 @@ func RegisterOperatorWithChain(ctx *cli.Context) error {
 	}
 	log.Println("Config:", string(configJson))
 
-	o, err := operator.NewOperatorFromConfig(nodeConfig)
+	o, err := operator.NewCliOperatorFromConfig(nodeConfig)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,291 @@
+package actions
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"os"
+
+	"github.com/ethereum/go-ethereum/common"
+	gethtypes "github.com/ethereum/go-ethereum/core/types"
+	sdklogging "github.com/imua-xyz/imua-avs-sdk/logging"
+	sdkutils "github.com/imua-xyz/imua-avs-sdk/utils"
+	chain "github.com/imua-xyz/imua-avs/core/chainio"
+	"github.com/imua-xyz/imua-avs/core/chainio/eth"
+	"github.com/imua-xyz/imua-avs/core/config"
+	"github.com/imua-xyz/imua-avs/core/network"
+	"github.com/imua-xyz/imua-avs/types"
+	"github.com/urfave/cli"
+)
+
+var (
+	StakerFlag = cli.StringFlag{
+		Name:  "staker",
+		Usage: "staker account, hex or bech32 address of the network",
+	}
+	OperatorFlag = cli.StringFlag{
+		Name:  "operator",
+		Usage: "operator account, hex or bech32 address of the network",
+	}
+	AssetIDFlag = cli.StringFlag{
+		Name:  "asset-id",
+		Usage: "asset as <asset address>_<client chain id>, defaults to staking_asset_id or the first of asset_ids",
+	}
+	AmountFlag = cli.StringFlag{
+		Name:  "amount",
+		Usage: "amount in the smallest unit of the asset",
+	}
+)
+
+// StakingCommand groups the staking subcommands, which send from the funding account of the config.
+var StakingCommand = cli.Command{
+	Name:  "staking",
+	Usage: "deposits, delegates and associates stake through the staking precompiles",
+	Subcommands: []cli.Command{
+		{
+			Name:   "deposit",
+			Usage:  "deposits --amount of the asset for --staker",
+			Flags:  []cli.Flag{StakerFlag, AssetIDFlag, AmountFlag},
+			Action: StakingDeposit,
+		},
+		{
+			Name:   "delegate",
+			Usage:  "delegates --amount of the asset of --staker to --operator",
+			Flags:  []cli.Flag{StakerFlag, OperatorFlag, AssetIDFlag, AmountFlag},
+			Action: StakingDelegate,
+		},
+		{
+			Name:   "undelegate",
+			Usage:  "undelegates --amount of the asset of --staker from --operator",
+			Flags:  []cli.Flag{StakerFlag, OperatorFlag, AssetIDFlag, AmountFlag},
+			Action: StakingUndelegate,
+		},
+		{
+			Name:   "associate",
+			Usage:  "associates --staker with --operator, so its stake counts as operator self delegation",
+			Flags:  []cli.Flag{StakerFlag, OperatorFlag, AssetIDFlag},
+			Action: StakingAssociate,
+		},
+		{
+			Name:   "dissociate",
+			Usage:  "dissociates --staker from its operator",
+			Flags:  []cli.Flag{StakerFlag, AssetIDFlag},
+			Action: StakingDissociate,
+		},
+	},
+}
+
+func StakingDeposit(ctx *cli.Context) error {
+	nodeConfig, profile, err := readStakingConfig(ctx)
+	if err != nil {
+		return err
+	}
+	staker, err := stakerArg(ctx, profile)
+	if err != nil {
+		return err
+	}
+	amount, err := amountArg(ctx)
+	if err != nil {
+		return err
+	}
+	return runStaking(ctx, nodeConfig, profile, func(c context.Context, s *chain.StakingClient) (*gethtypes.Receipt, error) {
+		return s.Deposit(c, staker, amount)
+	})
+}
+
+func StakingDelegate(ctx *cli.Context) error {
+	nodeConfig, profile, err := readStakingConfig(ctx)
+	if err != nil {
+		return err
+	}
+	staker, operator, amount, err := delegationArgs(ctx, profile)
+	if err != nil {
+		return err
+	}
+	return runStaking(ctx, nodeConfig, profile, func(c context.Context, s *chain.StakingClient) (*gethtypes.Receipt, error) {
+		return s.Delegate(c, staker, operator, amount)
+	})
+}
+
+func StakingUndelegate(ctx *cli.Context) error {
+	nodeConfig, profile, err := readStakingConfig(ctx)
+	if err != nil {
+		return err
+	}
+	staker, operator, amount, err := delegationArgs(ctx, profile)
+	if err != nil {
+		return err
+	}
+	return runStaking(ctx, nodeConfig, profile, func(c context.Context, s *chain.StakingClient) (*gethtypes.Receipt, error) {
+		return s.Undelegate(c, staker, operator, amount)
+	})
+}
+
+func StakingAssociate(ctx *cli.Context) error {
+	nodeConfig, profile, err := readStakingConfig(ctx)
+	if err != nil {
+		return err
+	}
+	staker, err := stakerArg(ctx, profile)
+	if err != nil {
+		return err
+	}
+	operator, err := operatorArg(ctx, profile)
+	if err != nil {
+		return err
+	}
+	return runStaking(ctx, nodeConfig, profile, func(c context.Context, s *chain.StakingClient) (*gethtypes.Receipt, error) {
+		return s.Associate(c, staker, operator)
+	})
+}
+
+func StakingDissociate(ctx *cli.Context) error {
+	nodeConfig, profile, err := readStakingConfig(ctx)
+	if err != nil {
+		return err
+	}
+	staker, err := stakerArg(ctx, profile)
+	if err != nil {
+		return err
+	}
+	return runStaking(ctx, nodeConfig, profile, func(c context.Context, s *chain.StakingClient) (*gethtypes.Receipt, error) {
+		return s.Dissociate(c, staker)
+	})
+}
+
+// readStakingConfig reads the config and the network profile it selects.
+func readStakingConfig(ctx *cli.Context) (types.NodeConfig, network.Profile, error) {
+	configPath := ctx.GlobalString(config.FileFlag.Name)
+	nodeConfig := types.NodeConfig{}
+	err := sdkutils.ReadYamlConfig(configPath, &nodeConfig)
+	if err != nil {
+		return nodeConfig, network.Profile{}, err
+	}
+	profile, err := network.Load(nodeConfig.Network)
+	return nodeConfig, profile, err
+}
+
+// runStaking builds the staking client from the config, sends one transaction with send
+// and prints its hash and receipt status.
+func runStaking(
+	ctx *cli.Context,
+	nodeConfig types.NodeConfig,
+	profile network.Profile,
+	send func(context.Context, *chain.StakingClient) (*gethtypes.Receipt, error),
+) error {
+	assetID := ctx.String(AssetIDFlag.Name)
+	if assetID == "" {
+		assetID = nodeConfig.StakingAssetID
+	}
+	if assetID == "" && len(nodeConfig.AssetIDs) > 0 {
+		assetID = nodeConfig.AssetIDs[0]
+	}
+	if _, err := chain.ParseAssetID(assetID); err != nil {
+		return err
+	}
+
+	logger, err := sdklogging.NewZapLogger(sdklogging.Development)
+	if err != nil {
+		return err
+	}
+	ethClient, err := eth.NewClient(nodeConfig.EthRpcUrl)
+	if err != nil {
+		return fmt.Errorf("cannot create eth client: %w", err)
+	}
+	fundingKeyPassword, ok := os.LookupEnv("FUNDING_ECDSA_KEY_PASSWORD")
+	if !ok {
+		log.Printf("FUNDING_ECDSA_KEY_PASSWORD env var not set. using empty string")
+	}
+	keystorePath := nodeConfig.FundingEcdsaPrivateKeyStorePath
+	if keystorePath == "" {
+		// without a funding account the operator account funds its own stake
+		keystorePath = nodeConfig.OperatorEcdsaPrivateKeyStorePath
+		fundingKeyPassword = os.Getenv("OPERATOR_ECDSA_KEY_PASSWORD")
+	}
+	staking, err := chain.BuildStakingClient(context.Background(), chain.StakingConfig{
+		Network: profile,
+		Signer: chain.FundingSignerConfig{
+			Type:         nodeConfig.FundingSigner,
+			KeystorePath: keystorePath,
+			Password:     fundingKeyPassword,
+			RemoteURL:    nodeConfig.FundingRemoteSignerUrl,
+			Address:      nodeConfig.FundingAddress,
+		},
+		AssetID: assetID,
+	}, ethClient, logger)
+	if err != nil {
+		return err
+	}
+
+	receipt, err := send(context.Background(), staking)
+	if receipt != nil {
+		status := "success"
+		if receipt.Status != gethtypes.ReceiptStatusSuccessful {
+			status = "reverted"
+		}
+		fmt.Printf("tx hash: %s\nstatus: %s (block %s, gas used %d)\n",
+			receipt.TxHash.Hex(), status, receipt.BlockNumber, receipt.GasUsed)
+	}
+	return err
+}
+
+func stakerArg(ctx *cli.Context, profile network.Profile) (common.Address, error) {
+	staker := ctx.String(StakerFlag.Name)
+	if staker == "" {
+		return common.Address{}, errors.New("--staker is required")
+	}
+	addr, err := profile.ParseAddress(staker)
+	if err != nil {
+		return common.Address{}, fmt.Errorf("invalid --staker: %w", err)
+	}
+	if addr == (common.Address{}) {
+		return common.Address{}, errors.New("--staker must not be the zero address")
+	}
+	return addr, nil
+}
+
+// operatorArg returns the operator as the bech32 address the staking precompiles expect.
+func operatorArg(ctx *cli.Context, profile network.Profile) (string, error) {
+	operator := ctx.String(OperatorFlag.Name)
+	if operator == "" {
+		return "", errors.New("--operator is required")
+	}
+	addr, err := profile.ParseAddress(operator)
+	if err != nil {
+		return "", fmt.Errorf("invalid --operator: %w", err)
+	}
+	if addr == (common.Address{}) {
+		return "", errors.New("--operator must not be the zero address")
+	}
+	return profile.Bech32Address(addr)
+}
+
+func amountArg(ctx *cli.Context) (*big.Int, error) {
+	amount := ctx.String(AmountFlag.Name)
+	if amount == "" {
+		return nil, errors.New("--amount is required")
+	}
+	value, err := chain.ParseStakingAmount(amount)
+	if err != nil {
+		return nil, fmt.Errorf("invalid --amount: %w", err)
+	}
+	return value, nil
+}
+
+func delegationArgs(ctx *cli.Context, profile network.Profile) (common.Address, string, *big.Int, error) {
+	staker, err := stakerArg(ctx, profile)
+	if err != nil {
+		return common.Address{}, "", nil, err
+	}
+	operator, err := operatorArg(ctx, profile)
+	if err != nil {
+		return common.Address{}, "", nil, err
+	}
+	amount, err := amountArg(ctx)
+	if err != nil {
+		return common.Address{}, "", nil, err
+	}
+	return staker, operator, amount, nil
+}
@@ -23,21 +23,21 @@ func main() {
 		{
 			Name:    "register-operator-with-avs",
 			Aliases: []string{"r"},
-			Usage:   "operator opt-in avs ",
+			Usage:   "operator opt-in every avs it serves",
 			Action:  actions.RegisterOperatorWithAvs,
 		},
 		{
 			Name:    "deregister-operator-with-avs",
 			Aliases: []string{"d"},
-			Action: func(ctx *cli.Context) error {
-				log.Fatal("Command not implemented.")
-				return nil
-			},
+			Usage:   "operator opt-out avs",
+			Flags:   []cli.Flag{actions.AvsFlag, actions.ForceFlag},
+			Action:  actions.DeregisterOperatorWithAvs,
 		},
 		{
 			Name:    "print-operator-status",
 			Aliases: []string{"s"},
 			Usage:   "prints operator status as viewed from avs contracts",
+			Flags:   []cli.Flag{actions.OutputFlag, actions.AvsFlag},
 			Action:  actions.PrintOperatorStatus,
 		},
 		{
@@ -46,6 +46,14 @@ func main() {
 			Usage:   "Subscribe to events using websocket,Monitor create and challenge tasks",
 			Action:  actions.Monitor,
 		},
+		{
+			Name:    "explain-challenge",
+			Aliases: []string{"e"},
+			Usage:   "predicts the rewards and slashes a challenge of the task would resolve to",
+			Flags:   []cli.Flag{config.TaskIDFlag, config.NumberToBeSquaredFlag, actions.OutputFlag},
+			Action:  actions.ExplainChallenge,
+		},
+		actions.StakingCommand,
 	}
 
 	err := app.Run(os.Args)
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/imua-xyz/imua-avs-sdk/crypto/bls"
+	"github.com/imua-xyz/imua-avs/core/blssigner"
+	"github.com/urfave/cli/v2"
+)
+
+var (
+	KeyFileFlag = &cli.StringFlag{
+		Name:  "key-file",
+		Usage: "bls keystore file of the key to sign with",
+		Value: "tests/keys/test.bls.key.json",
+	}
+	ListenFlag = &cli.StringFlag{
+		Name:  "listen",
+		Usage: "address to serve the signer API on",
+		Value: "127.0.0.1:9000",
+	}
+)
+
+func main() {
+	app := cli.NewApp()
+	app.Name = "bls-signer"
+	app.Description = "Local stand-in for a web3signer style remote BLS signer"
+	app.Usage = "Serve a BLS key over the remote signer API for testing purpose"
+	app.Flags = []cli.Flag{KeyFileFlag, ListenFlag}
+	app.Action = run
+
+	if err := app.Run(os.Args); err != nil {
+		fmt.Println("Error: ", err)
+		os.Exit(1)
+	}
+}
+
+func run(c *cli.Context) error {
+	key, err := bls.ReadPrivateKeyFromFile(c.String(KeyFileFlag.Name), os.Getenv("OPERATOR_BLS_KEY_PASSWORD"))
+	if err != nil {
+		return fmt.Errorf("failed to read bls keystore: %w", err)
+	}
+	log.Printf("Serving bls key %s on %s", hexutil.Encode(key.PublicKey().Marshal()), c.String(ListenFlag.Name))
+	return http.ListenAndServe(c.String(ListenFlag.Name), blssigner.NewServer(key))
+}
@@ -1,6 +1,8 @@
 pragma solidity >=0.8.17;
 
-/// @dev The avs-manager contract's address.
+/// @dev The avs-manager contract's address. It must equal avs_manager_precompile of the
+/// network profile (core/network) and chainio.ContractAVSManagerPrecompile, the binaries refuse
+/// to run on a network whose profile differs until this and the bindings are regenerated.
 address constant AVSMANAGER_PRECOMPILE_ADDRESS = 0x0000000000000000000000000000000000000901;
 
 /// @dev The avs-manager contract's instance.
@@ -0,0 +1,158 @@
+package blssigner
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/prysmaticlabs/prysm/v5/crypto/bls/blst"
+	blscommon "github.com/prysmaticlabs/prysm/v5/crypto/bls/common"
+)
+
+// Paths of the web3signer API served by a remote signer.
+const (
+	UpcheckPath    = "/upcheck"
+	PublicKeysPath = "/api/v1/eth2/publicKeys"
+	SignPath       = "/api/v1/eth2/sign/"
+)
+
+// remoteSignerTimeout bounds every request to a remote signer.
+const remoteSignerTimeout = 10 * time.Second
+
+// SignRequest is the body of a sign request, the signing root is the hex encoded digest.
+type SignRequest struct {
+	SigningRoot string `json:"signingRoot"`
+}
+
+// SignResponse is the body of a sign response, the signature is hex encoded.
+type SignResponse struct {
+	Signature string `json:"signature"`
+}
+
+// RemoteSigner signs through a remote signer speaking the web3signer HTTP API.
+// Every signature it returns is verified against the configured public key first,
+// so a misconfigured or compromised signer cannot make the operator submit garbage.
+type RemoteSigner struct {
+	url       string
+	publicKey blscommon.PublicKey
+	client    *http.Client
+}
+
+var _ BLSSigner = (*RemoteSigner)(nil)
+
+// NewRemoteSigner returns a RemoteSigner for the key with the hex encoded publicKey on the
+// signer at url, after checking that the signer is up and holds that key.
+func NewRemoteSigner(ctx context.Context, url, publicKey string) (*RemoteSigner, error) {
+	if url == "" {
+		return nil, fmt.Errorf("remote bls signer url is not configured")
+	}
+	pubBytes, err := hexutil.Decode(publicKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid remote bls signer public key %q: %w", publicKey, err)
+	}
+	pub, err := blst.PublicKeyFromBytes(pubBytes)
+	if err != nil {
+		return nil, fmt.Errorf("invalid remote bls signer public key %q: %w", publicKey, err)
+	}
+	s := &RemoteSigner{
+		url:       strings.TrimSuffix(url, "/"),
+		publicKey: pub,
+		client:    &http.Client{Timeout: remoteSignerTimeout},
+	}
+
+	if err := s.Ready(ctx); err != nil {
+		return nil, err
+	}
+	body, err := s.get(ctx, PublicKeysPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list keys of remote bls signer at %s: %w", s.url, err)
+	}
+	var keys []string
+	if err := json.Unmarshal(body, &keys); err != nil {
+		return nil, fmt.Errorf("failed to decode keys of remote bls signer at %s: %w", s.url, err)
+	}
+	for _, key := range keys {
+		if strings.EqualFold(key, hexutil.Encode(pubBytes)) {
+			return s, nil
+		}
+	}
+	return nil, fmt.Errorf("remote bls signer at %s does not hold key %s", s.url, hexutil.Encode(pubBytes))
+}
+
+func (s *RemoteSigner) PublicKey() []byte {
+	return s.publicKey.Marshal()
+}
+
+func (s *RemoteSigner) Sign(ctx context.Context, digest []byte) ([]byte, error) {
+	reqBody, err := json.Marshal(SignRequest{SigningRoot: hexutil.Encode(digest)})
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url+SignPath+hexutil.Encode(s.PublicKey()), bytes.NewReader(reqBody))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	body, err := s.do(req)
+	if err != nil {
+		return nil, fmt.Errorf("remote bls signer failed to sign: %w", err)
+	}
+
+	// web3signer answers with JSON when asked to, and with the bare hex signature otherwise
+	encoded := strings.TrimSpace(string(body))
+	var resp SignResponse
+	if err := json.Unmarshal(body, &resp); err == nil {
+		encoded = resp.Signature
+	}
+	sigBytes, err := hexutil.Decode(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("remote bls signer returned an invalid signature %q: %w", encoded, err)
+	}
+	sig, err := blst.SignatureFromBytes(sigBytes)
+	if err != nil {
+		return nil, fmt.Errorf("remote bls signer returned an invalid signature: %w", err)
+	}
+	if !sig.Verify(s.publicKey, digest) {
+		return nil, fmt.Errorf("remote bls signer returned a signature that does not verify against %s", hexutil.Encode(s.PublicKey()))
+	}
+	return sigBytes, nil
+}
+
+// Ready asks the remote signer whether it is up.
+func (s *RemoteSigner) Ready(ctx context.Context) error {
+	if _, err := s.get(ctx, UpcheckPath); err != nil {
+		return fmt.Errorf("remote bls signer at %s is not up: %w", s.url, err)
+	}
+	return nil
+}
+
+func (s *RemoteSigner) get(ctx context.Context, path string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.url+path, nil)
+	if err != nil {
+		return nil, err
+	}
+	return s.do(req)
+}
+
+func (s *RemoteSigner) do(req *http.Request) ([]byte, error) {
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s %s: %s: %s", req.Method, req.URL.Path, resp.Status, strings.TrimSpace(string(body)))
+	}
+	return body, nil
+}
@@ -0,0 +1,73 @@
+package blssigner
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	blscommon "github.com/prysmaticlabs/prysm/v5/crypto/bls/common"
+)
+
+// NewServer returns a handler serving the subset of the web3signer API RemoteSigner uses,
+// signing with keys. It stands in for a real remote signer in local setups and tests
+// and keeps the keys in memory, so it is not meant for production keys.
+func NewServer(keys ...blscommon.SecretKey) http.Handler {
+	byPub := make(map[string]blscommon.SecretKey, len(keys))
+	pubs := make([]string, 0, len(keys))
+	for _, key := range keys {
+		pub := hexutil.Encode(key.PublicKey().Marshal())
+		byPub[pub] = key
+		pubs = append(pubs, pub)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(UpcheckPath, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		_, _ = w.Write([]byte("OK"))
+	})
+	mux.HandleFunc(PublicKeysPath, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		writeJSON(w, pubs)
+	})
+	mux.HandleFunc(SignPath, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		key, ok := byPub[strings.ToLower(strings.TrimPrefix(r.URL.Path, SignPath))]
+		if !ok {
+			http.Error(w, "key not found", http.StatusNotFound)
+			return
+		}
+		var req SignRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "invalid request body: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		root, err := hexutil.Decode(req.SigningRoot)
+		if err != nil || len(root) != 32 {
+			http.Error(w, "signingRoot must be 32 hex encoded bytes", http.StatusBadRequest)
+			return
+		}
+		sig := hexutil.Encode(key.Sign(root).Marshal())
+		if strings.Contains(r.Header.Get("Accept"), "application/json") {
+			writeJSON(w, SignResponse{Signature: sig})
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain")
+		_, _ = w.Write([]byte(sig))
+	})
+	return mux
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,82 @@
+// Package blssigner signs with the operator BLS key, either held in a local keystore
+// or kept on a remote signer service so that it never touches the operator host.
+package blssigner
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/imua-xyz/imua-avs-sdk/crypto/bls"
+	blscommon "github.com/prysmaticlabs/prysm/v5/crypto/bls/common"
+)
+
+const (
+	// TypeLocal signs with a key decrypted from a local keystore file.
+	TypeLocal = "local"
+	// TypeRemote signs through a web3signer style HTTP signer.
+	TypeRemote = "remote"
+)
+
+// BLSSigner signs digests with an operator BLS key.
+type BLSSigner interface {
+	// PublicKey returns the compressed public key of the signing key.
+	PublicKey() []byte
+	// Sign signs a 32 byte digest and returns the compressed signature.
+	Sign(ctx context.Context, digest []byte) ([]byte, error)
+	// Ready returns an error if the signer cannot sign right now.
+	Ready(ctx context.Context) error
+}
+
+// Config selects and configures a BLSSigner.
+type Config struct {
+	// Type is TypeLocal or TypeRemote, empty means TypeLocal.
+	Type string
+	// KeystorePath and Password locate the key of a local signer.
+	KeystorePath string
+	Password     string
+	// RemoteURL and RemotePublicKey locate the key of a remote signer,
+	// the public key is hex encoded and identifies the key on the signer.
+	RemoteURL       string
+	RemotePublicKey string
+}
+
+// SignerFromConfig returns the BLSSigner selected by c.
+func SignerFromConfig(c Config) (BLSSigner, error) {
+	switch c.Type {
+	case "", TypeLocal:
+		key, err := bls.ReadPrivateKeyFromFile(c.KeystorePath, c.Password)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read bls keystore %s: %w", c.KeystorePath, err)
+		}
+		return NewLocalSigner(key), nil
+	case TypeRemote:
+		return NewRemoteSigner(context.Background(), c.RemoteURL, c.RemotePublicKey)
+	default:
+		return nil, fmt.Errorf("unknown bls signer type %q, expected %q or %q", c.Type, TypeLocal, TypeRemote)
+	}
+}
+
+// LocalSigner signs with a secret key held in memory.
+type LocalSigner struct {
+	key blscommon.SecretKey
+}
+
+var _ BLSSigner = (*LocalSigner)(nil)
+
+// NewLocalSigner returns a LocalSigner for key.
+func NewLocalSigner(key blscommon.SecretKey) *LocalSigner {
+	return &LocalSigner{key: key}
+}
+
+func (s *LocalSigner) PublicKey() []byte {
+	return s.key.PublicKey().Marshal()
+}
+
+func (s *LocalSigner) Sign(_ context.Context, digest []byte) ([]byte, error) {
+	return s.key.Sign(digest).Marshal(), nil
+}
+
+// Ready always succeeds, the key is loaded when the signer is created.
+func (s *LocalSigner) Ready(context.Context) error {
+	return nil
+}
@@ -0,0 +1,62 @@
+package blssigner_test
+
+import (
+	"bytes"
+	"context"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/imua-xyz/imua-avs/core/blssigner"
+	"github.com/prysmaticlabs/prysm/v5/crypto/bls/blst"
+)
+
+func TestRemoteSignerMatchesLocalSigner(t *testing.T) {
+	key, err := blst.RandKey()
+	if err != nil {
+		t.Fatalf("Error generating bls key: %v", err)
+	}
+	server := httptest.NewServer(blssigner.NewServer(key))
+	defer server.Close()
+
+	ctx := context.Background()
+	remote, err := blssigner.NewRemoteSigner(ctx, server.URL, hexutil.Encode(key.PublicKey().Marshal()))
+	if err != nil {
+		t.Fatalf("Error creating remote signer: %v", err)
+	}
+	local := blssigner.NewLocalSigner(key)
+	if !bytes.Equal(remote.PublicKey(), local.PublicKey()) {
+		t.Fatalf("Expected the remote and local public keys to match")
+	}
+
+	digest := crypto.Keccak256([]byte("hello"))
+	remoteSig, err := remote.Sign(ctx, digest)
+	if err != nil {
+		t.Fatalf("Error signing remotely: %v", err)
+	}
+	localSig, err := local.Sign(ctx, digest)
+	if err != nil {
+		t.Fatalf("Error signing locally: %v", err)
+	}
+	if !bytes.Equal(remoteSig, localSig) {
+		t.Fatalf("Expected the remote signature to equal the local one")
+	}
+}
+
+func TestRemoteSignerRejectsUnknownKey(t *testing.T) {
+	served, err := blst.RandKey()
+	if err != nil {
+		t.Fatalf("Error generating bls key: %v", err)
+	}
+	other, err := blst.RandKey()
+	if err != nil {
+		t.Fatalf("Error generating bls key: %v", err)
+	}
+	server := httptest.NewServer(blssigner.NewServer(served))
+	defer server.Close()
+
+	if _, err := blssigner.NewRemoteSigner(context.Background(), server.URL, hexutil.Encode(other.PublicKey().Marshal())); err == nil {
+		t.Fatalf("Expected an error for a key the signer does not hold")
+	}
+}
@@ -51,12 +51,22 @@ type AvsWriter interface {
 		req avs.AvsServiceContractChallengeReq,
 	) (*gethtypes.Receipt, error)
 
+	SimulateChallenge(
+		ctx context.Context,
+		req avs.AvsServiceContractChallengeReq,
+	) (ChallengeSimulation, error)
+
 	RegisterOperatorToAVS(
 		ctx context.Context,
 	) (*gethtypes.Receipt, error)
+
+	DeregisterOperatorFromAVS(
+		ctx context.Context,
+	) (*gethtypes.Receipt, error)
 }
 
 type ChainWriter struct {
+	avsAddr     gethcommon.Address
 	avsManager  avs.ContracthelloWorld
 	chainReader AvsReader
 	ethClient   eth.EthClient
@@ -67,6 +77,7 @@ type ChainWriter struct {
 var _ AvsWriter = (*ChainWriter)(nil)
 
 func NewChainWriter(
+	avsAddr gethcommon.Address,
 	avsManager avs.ContracthelloWorld,
 	chainReader AvsReader,
 	ethClient eth.EthClient,
@@ -74,6 +85,7 @@ func NewChainWriter(
 	txMgr txmgr.TxManager,
 ) *ChainWriter {
 	return &ChainWriter{
+		avsAddr:     avsAddr,
 		avsManager:  avsManager,
 		chainReader: chainReader,
 		logger:      logger,
@@ -102,6 +114,7 @@ func BuildChainWriter(
 		ethClient,
 	)
 	return NewChainWriter(
+		avsAddr,
 		*contractBindings.AVSManager,
 		chainReader,
 		ethClient,
@@ -129,7 +142,7 @@ func (w *ChainWriter) RegisterAVSToChain(
 	if err != nil {
 		return nil, errors.New("failed to send tx with err: " + err.Error())
 	}
-	w.logger.Infof("tx hash: %s", tx.Hash().String())
+	w.logger.Infof("tx hash: %s", receipt.TxHash.String())
 
 	return receipt, nil
 }
@@ -155,7 +168,7 @@ func (w *ChainWriter) RegisterBLSPublicKey(
 	if err != nil {
 		return nil, errors.New("failed to send tx with err: " + err.Error())
 	}
-	w.logger.Infof("tx hash: %s", tx.Hash().String())
+	w.logger.Infof("tx hash: %s", receipt.TxHash.String())
 
 	return receipt, nil
 }
@@ -187,7 +200,7 @@ func (w *ChainWriter) CreateNewTask(
 	if err != nil {
 		return nil, errors.New("failed to send tx with err: " + err.Error())
 	}
-	w.logger.Infof("tx hash: %s", tx.Hash().String())
+	w.logger.Infof("tx hash: %s", receipt.TxHash.String())
 
 	return receipt, nil
 }
@@ -218,7 +231,7 @@ func (w *ChainWriter) OperatorSubmitTask(
 	if err != nil {
 		return nil, errors.New("failed to send tx with err: " + err.Error())
 	}
-	w.logger.Infof("tx hash: %s", tx.Hash().String())
+	w.logger.Infof("tx hash: %s", receipt.TxHash.String())
 
 	return receipt, nil
 }
@@ -237,7 +250,7 @@ func (w *ChainWriter) Challenge(ctx context.Context, req avs.AvsServiceContractC
 	if err != nil {
 		return nil, errors.New("failed to send tx with err: " + err.Error())
 	}
-	w.logger.Infof("tx hash: %s", tx.Hash().String())
+	w.logger.Infof("tx hash: %s", receipt.TxHash.String())
 
 	return receipt, nil
 }
@@ -258,7 +271,28 @@ func (w *ChainWriter) RegisterOperatorToAVS(
 	if err != nil {
 		return nil, errors.New("failed to send tx with err: " + err.Error())
 	}
-	w.logger.Infof("tx hash: %s", tx.Hash().String())
+	w.logger.Infof("tx hash: %s", receipt.TxHash.String())
+
+	return receipt, nil
+}
+
+func (w *ChainWriter) DeregisterOperatorFromAVS(
+	ctx context.Context,
+) (*gethtypes.Receipt, error) {
+	noSendTxOpts, err := w.txMgr.GetNoSendTxOpts()
+	if err != nil {
+		return nil, err
+	}
+	tx, err := w.avsManager.DeregisterOperatorFromAVS(
+		noSendTxOpts)
+	if err != nil {
+		return nil, err
+	}
+	receipt, err := w.txMgr.Send(ctx, tx)
+	if err != nil {
+		return nil, errors.New("failed to send tx with err: " + err.Error())
+	}
+	w.logger.Infof("tx hash: %s", receipt.TxHash.String())
 
 	return receipt, nil
 }
@@ -0,0 +1,61 @@
+package chainio
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+)
+
+// BackfillBatchSize is the number of blocks requested per eth_getLogs call while backfilling.
+const BackfillBatchSize uint64 = 2000
+
+// LogFilterer is the part of an eth client needed to fetch past logs.
+type LogFilterer interface {
+	BlockNumber(ctx context.Context) (uint64, error)
+	FilterLogs(ctx context.Context, q ethereum.FilterQuery) ([]ethtypes.Log, error)
+}
+
+// BackfillLogs returns the logs matching query from position from up to the current head,
+// in chain order, requesting at most batchSize blocks at a time. Logs of the first block that
+// come before from are left out. It also returns the head block the logs were fetched up to,
+// a live subscription started before the call only has to handle logs after that block.
+func BackfillLogs(
+	ctx context.Context,
+	client LogFilterer,
+	query ethereum.FilterQuery,
+	from LogPosition,
+	batchSize uint64,
+) ([]ethtypes.Log, uint64, error) {
+	head, err := client.BlockNumber(ctx)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get head block: %w", err)
+	}
+	if batchSize == 0 {
+		batchSize = BackfillBatchSize
+	}
+
+	var logs []ethtypes.Log
+	for start := from.BlockNumber; start <= head; start += batchSize {
+		end := start + batchSize - 1
+		if end > head {
+			end = head
+		}
+		q := query
+		q.FromBlock = new(big.Int).SetUint64(start)
+		q.ToBlock = new(big.Int).SetUint64(end)
+		batch, err := client.FilterLogs(ctx, q)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to filter logs in blocks %d-%d: %w", start, end, err)
+		}
+		for _, l := range batch {
+			if l.Removed || PositionOf(l).Less(from) {
+				continue
+			}
+			logs = append(logs, l)
+		}
+	}
+	return logs, head, nil
+}
@@ -44,7 +44,7 @@ func BuildAll(
 		return nil, err
 	}
 
-	txMgr := txmgr.NewSimpleTxManager(ethHttpClient, logger, signerFn, signerAddr)
+	txMgr := NewTxQueue(ethHttpClient, logger, signerFn, signerAddr)
 	// creating  clients: Reader, Writer and Subscriber
 	chainReader, chainWriter, avsRegistrySubscriber, err := config.buildClients(
 		ethHttpClient,
@@ -89,6 +89,7 @@ func (config *BuildAllConfig) buildClients(
 	)
 
 	chainWriter := NewChainWriter(
+		contractBindings.AvsAddr,
 		*contractBindings.AVSManager,
 		chainReader,
 		ethHttpClient,
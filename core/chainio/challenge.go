@@ -0,0 +1,184 @@
+package chainio
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+	avs "github.com/imua-xyz/imua-avs/contracts/bindings/avs"
+)
+
+// ChallengeSimulation is the outcome of raiseAndResolveChallenge simulated with eth_call.
+type ChallengeSimulation struct {
+	// Reverted is set if the call reverts, RevertReason is then its decoded reason.
+	Reverted     bool   `json:"reverted"`
+	RevertReason string `json:"revertReason,omitempty"`
+	// Gas is the gas the transaction is estimated to use, zero if the call reverts.
+	Gas uint64 `json:"gas"`
+	// Result is what raiseAndResolveChallenge returns, the result of the AVS manager challenge.
+	Result bool `json:"result"`
+}
+
+// SimulateChallenge calls raiseAndResolveChallenge with the calldata Challenge would send, from
+// the same sender, without sending a transaction. A revert is reported in the simulation, an
+// error is only returned if the call cannot be made.
+func (w *ChainWriter) SimulateChallenge(ctx context.Context, req avs.AvsServiceContractChallengeReq) (ChallengeSimulation, error) {
+	contractABI, err := avs.ContracthelloWorldMetaData.GetAbi()
+	if err != nil {
+		return ChallengeSimulation{}, err
+	}
+	data, err := contractABI.Pack("raiseAndResolveChallenge", req)
+	if err != nil {
+		return ChallengeSimulation{}, fmt.Errorf("failed to pack raiseAndResolveChallenge: %w", err)
+	}
+	opts, err := w.txMgr.GetNoSendTxOpts()
+	if err != nil {
+		return ChallengeSimulation{}, err
+	}
+	msg := ethereum.CallMsg{From: opts.From, To: &w.avsAddr, Data: data}
+
+	out, err := w.ethClient.CallContract(ctx, msg, nil)
+	if err != nil {
+		if reason, ok := RevertReason(err); ok {
+			return ChallengeSimulation{Reverted: true, RevertReason: reason}, nil
+		}
+		return ChallengeSimulation{}, fmt.Errorf("failed to simulate raiseAndResolveChallenge: %w", err)
+	}
+	var sim ChallengeSimulation
+	if err := contractABI.UnpackIntoInterface(&sim.Result, "raiseAndResolveChallenge", out); err != nil {
+		return ChallengeSimulation{}, fmt.Errorf("failed to unpack raiseAndResolveChallenge result: %w", err)
+	}
+	sim.Gas, err = w.ethClient.EstimateGas(ctx, msg)
+	if err != nil {
+		if reason, ok := RevertReason(err); ok {
+			return ChallengeSimulation{Reverted: true, RevertReason: reason}, nil
+		}
+		return ChallengeSimulation{}, fmt.Errorf("failed to estimate raiseAndResolveChallenge gas: %w", err)
+	}
+	return sim, nil
+}
+
+// RevertReason returns the reason of a call that reverted: the Error(string) or Panic(uint256)
+// decoded from the revert data the node returns, or else its message. It reports false if err
+// is not a revert.
+func RevertReason(err error) (string, bool) {
+	var dataErr rpc.DataError
+	if errors.As(err, &dataErr) {
+		if hexData, ok := dataErr.ErrorData().(string); ok {
+			if data, decodeErr := hexutil.Decode(hexData); decodeErr == nil {
+				if reason, unpackErr := abi.UnpackRevert(data); unpackErr == nil {
+					return reason, true
+				}
+			}
+		}
+	}
+	msg := err.Error()
+	if !strings.Contains(strings.ToLower(msg), "revert") {
+		return "", false
+	}
+	return strings.TrimPrefix(msg, "execution reverted: "), true
+}
+
+// FindChallengeEvent returns the TaskResolved log of the AVS contract or the ChallengeInitiated
+// log of the AVS manager precompile for the task, scanning from block from up to the head.
+// It returns nil if the task has not been challenged in that range.
+func FindChallengeEvent(
+	ctx context.Context,
+	client LogFilterer,
+	avsAddr, avsManager common.Address,
+	taskID uint64,
+	from uint64,
+) (*ethtypes.Log, error) {
+	query := ethereum.FilterQuery{
+		Addresses: []common.Address{avsAddr, avsManager},
+		Topics: [][]common.Hash{{
+			EventTopic[avs.ContracthelloWorldTaskResolved](),
+			EventTopic[ChallengeInitiated](),
+		}},
+	}
+	logs, _, err := BackfillLogs(ctx, client, query, LogPosition{BlockNumber: from}, BackfillBatchSize)
+	if err != nil {
+		return nil, err
+	}
+	for i := range logs {
+		event, err := DecodeEvent(logs[i])
+		if err != nil {
+			continue
+		}
+		switch e := event.(type) {
+		case *avs.ContracthelloWorldTaskResolved:
+			if logs[i].Address == avsAddr && e.TaskId == taskID && e.TaskAddress == avsAddr {
+				return &logs[i], nil
+			}
+		case *ChallengeInitiated:
+			if logs[i].Address == avsManager && e.TaskID == taskID && e.TaskContractAddress == avsAddr {
+				return &logs[i], nil
+			}
+		}
+	}
+	return nil, nil
+}
+
+// FindTaskCreated returns the TaskCreated log of the task, scanning back from the head to block
+// from. Task ids grow with every task, so the scan stops at the first batch of blocks holding an
+// older task. It returns nil if the task was not created in that range.
+func FindTaskCreated(
+	ctx context.Context,
+	client LogFilterer,
+	avsAddr common.Address,
+	taskID uint64,
+	from uint64,
+) (*ethtypes.Log, error) {
+	head, err := client.BlockNumber(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get head block: %w", err)
+	}
+	for to := head; to >= from; {
+		batchFrom := from
+		if to >= from+BackfillBatchSize {
+			batchFrom = to - BackfillBatchSize + 1
+		}
+		logs, err := client.FilterLogs(ctx, ethereum.FilterQuery{
+			FromBlock: new(big.Int).SetUint64(batchFrom),
+			ToBlock:   new(big.Int).SetUint64(to),
+			Addresses: []common.Address{avsAddr},
+			Topics:    [][]common.Hash{{EventTopic[avs.ContracthelloWorldTaskCreated]()}},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get TaskCreated logs of blocks %d to %d: %w", batchFrom, to, err)
+		}
+		older := false
+		for i := range logs {
+			if logs[i].Address != avsAddr {
+				continue
+			}
+			event, err := DecodeEvent(logs[i])
+			if err != nil {
+				continue
+			}
+			e, ok := event.(*avs.ContracthelloWorldTaskCreated)
+			if !ok {
+				continue
+			}
+			switch id := e.TaskId.Uint64(); {
+			case id == taskID:
+				return &logs[i], nil
+			case id < taskID:
+				older = true
+			}
+		}
+		if older || batchFrom <= from {
+			break
+		}
+		to = batchFrom - 1
+	}
+	return nil, nil
+}
@@ -0,0 +1,157 @@
+package chainio_test
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	avs "github.com/imua-xyz/imua-avs/contracts/bindings/avs"
+	chain "github.com/imua-xyz/imua-avs/core/chainio"
+)
+
+func TestFindChallengeEvent(t *testing.T) {
+	contractABI, err := avs.ContracthelloWorldMetaData.GetAbi()
+	if err != nil {
+		t.Fatalf("Error loading ABI: %v", err)
+	}
+	avsAddr := common.HexToAddress("0x10Ed22D975453A5D4031440D51624552E4f204D5")
+	otherAvs := common.HexToAddress("0x3e108c058e8066DA635321Dc3018294cA82ddEdf")
+	manager := chain.ContractAVSManagerPrecompile
+
+	taskResolved := func(block, taskID uint64, taskAddr common.Address) ethtypes.Log {
+		data, err := contractABI.Events["TaskResolved"].Inputs.Pack(taskID, taskAddr)
+		if err != nil {
+			t.Fatalf("Error packing TaskResolved: %v", err)
+		}
+		l := testLog(block, 0)
+		l.Address, l.Topics, l.Data = taskAddr, []common.Hash{chain.EventTopic[avs.ContracthelloWorldTaskResolved]()}, data
+		return l
+	}
+	newType := func(s string) abi.Type {
+		typ, err := abi.NewType(s, "", nil)
+		if err != nil {
+			t.Fatalf("Error building type %s: %v", s, err)
+		}
+		return typ
+	}
+	challengeInitiated := func(block, taskID uint64, taskAddr common.Address) ethtypes.Log {
+		args := abi.Arguments{{Type: newType("address")}, {Type: newType("uint8")}, {Type: newType("bool")},
+			{Type: newType("address[]")}, {Type: newType("address[]")}}
+		data, err := args.Pack(avsAddr, uint8(100), true, []common.Address{}, []common.Address{})
+		if err != nil {
+			t.Fatalf("Error packing ChallengeInitiated: %v", err)
+		}
+		l := testLog(block, 1)
+		l.Address, l.Data = manager, data
+		l.Topics = []common.Hash{chain.EventTopic[chain.ChallengeInitiated](),
+			common.BigToHash(new(big.Int).SetUint64(taskID)), common.BytesToHash(taskAddr.Bytes())}
+		return l
+	}
+
+	ctx := context.Background()
+	history := []ethtypes.Log{
+		taskResolved(3, 8, avsAddr),
+		challengeInitiated(4, 7, otherAvs),
+		taskResolved(5, 7, otherAvs),
+		challengeInitiated(6, 7, avsAddr),
+	}
+	conn := &fakeConn{chain: &fakeChain{head: 10, history: history}}
+	found, err := chain.FindChallengeEvent(ctx, conn, avsAddr, manager, 7, 1)
+	if err != nil {
+		t.Fatalf("Error finding challenge: %v", err)
+	}
+	if found == nil || found.BlockNumber != 6 {
+		t.Fatalf("Expected the ChallengeInitiated log of block 6, but got %+v", found)
+	}
+	if found, err := chain.FindChallengeEvent(ctx, conn, avsAddr, manager, 7, 7); err != nil || found != nil {
+		t.Errorf("Expected no challenge after block 6, but got %+v (err %v)", found, err)
+	}
+	if found, err := chain.FindChallengeEvent(ctx, conn, avsAddr, manager, 8, 1); err != nil || found == nil || found.BlockNumber != 3 {
+		t.Errorf("Expected the TaskResolved log of block 3, but got %+v (err %v)", found, err)
+	}
+}
+
+// revertError is a node error carrying revert data, as the rpc client returns it.
+type revertError struct{ data string }
+
+func (e revertError) Error() string          { return "execution reverted" }
+func (e revertError) ErrorData() interface{} { return e.data }
+
+func TestRevertReason(t *testing.T) {
+	str, err := abi.NewType("string", "", nil)
+	if err != nil {
+		t.Fatalf("Error building type: %v", err)
+	}
+	packed, err := abi.Arguments{{Type: str}}.Pack("taskResponse length must be greater than 0")
+	if err != nil {
+		t.Fatalf("Error packing reason: %v", err)
+	}
+	data := hexutil.Encode(append([]byte{0x08, 0xc3, 0x79, 0xa0}, packed...))
+
+	cases := []struct {
+		err    error
+		reason string
+		ok     bool
+	}{
+		{revertError{data}, "taskResponse length must be greater than 0", true},
+		{errors.New("execution reverted: totalPower is zero"), "totalPower is zero", true},
+		{errors.New("connection refused"), "", false},
+	}
+	for _, c := range cases {
+		if reason, ok := chain.RevertReason(c.err); reason != c.reason || ok != c.ok {
+			t.Errorf("RevertReason(%v) = %q, %v, want %q, %v", c.err, reason, ok, c.reason, c.ok)
+		}
+	}
+}
+
+func TestFindTaskCreated(t *testing.T) {
+	contractABI, err := avs.ContracthelloWorldMetaData.GetAbi()
+	if err != nil {
+		t.Fatalf("Error loading ABI: %v", err)
+	}
+	avsAddr := common.HexToAddress("0x10Ed22D975453A5D4031440D51624552E4f204D5")
+	otherAvs := common.HexToAddress("0x3e108c058e8066DA635321Dc3018294cA82ddEdf")
+	taskCreated := func(block, taskID uint64, avsAddr common.Address) ethtypes.Log {
+		data, err := contractABI.Events["TaskCreated"].Inputs.Pack(new(big.Int).SetUint64(taskID), avsAddr, "task",
+			uint64(3), uint64(2), uint64(2), uint8(60), uint64(2))
+		if err != nil {
+			t.Fatalf("Error packing TaskCreated: %v", err)
+		}
+		l := testLog(block, 0)
+		l.Address, l.Topics, l.Data = avsAddr, []common.Hash{chain.EventTopic[avs.ContracthelloWorldTaskCreated]()}, data
+		return l
+	}
+
+	ctx := context.Background()
+	head := 3 * chain.BackfillBatchSize
+	history := []ethtypes.Log{
+		taskCreated(5, 1, avsAddr),
+		taskCreated(6, 2, otherAvs),
+		taskCreated(chain.BackfillBatchSize+10, 2, avsAddr),
+		taskCreated(head-1, 3, avsAddr),
+	}
+	conn := &fakeConn{chain: &fakeChain{head: head, history: history}}
+	for _, c := range []struct {
+		taskID, from, block uint64
+		found               bool
+	}{
+		{2, 1, chain.BackfillBatchSize + 10, true},
+		{1, 1, 5, true},
+		{3, 1, head - 1, true},
+		{1, 6, 0, false},
+		{4, 1, 0, false},
+	} {
+		found, err := chain.FindTaskCreated(ctx, conn, avsAddr, c.taskID, c.from)
+		if err != nil {
+			t.Fatalf("Error finding task %d: %v", c.taskID, err)
+		}
+		if (found != nil) != c.found || found != nil && found.BlockNumber != c.block {
+			t.Errorf("Expected the TaskCreated log of task %d in block %d (found %v), but got %+v", c.taskID, c.block, c.found, found)
+		}
+	}
+}
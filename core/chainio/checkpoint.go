@@ -0,0 +1,119 @@
+package chainio
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+)
+
+// LogPosition is the position of a log in the chain.
+type LogPosition struct {
+	BlockNumber uint64 `json:"block_number"`
+	Index       uint   `json:"log_index"`
+}
+
+// PositionOf returns the position of a log.
+func PositionOf(l ethtypes.Log) LogPosition {
+	return LogPosition{BlockNumber: l.BlockNumber, Index: l.Index}
+}
+
+// Less reports whether p comes before q in the chain.
+func (p LogPosition) Less(q LogPosition) bool {
+	if p.BlockNumber != q.BlockNumber {
+		return p.BlockNumber < q.BlockNumber
+	}
+	return p.Index < q.Index
+}
+
+// next returns the position right after p.
+func (p LogPosition) next() LogPosition {
+	return LogPosition{BlockNumber: p.BlockNumber, Index: p.Index + 1}
+}
+
+// LogCheckpoint persists the position from which a restarted process has to rescan logs.
+// Logs are marked with Begin when their handling starts and Done when it ends, which may be much
+// later for tasks that wait for an epoch window. The persisted position is the oldest log whose
+// handling has not ended, or the position after the newest handled log if nothing is pending,
+// so no task is lost however long it waits.
+type LogCheckpoint struct {
+	mu        sync.Mutex
+	path      string
+	position  LogPosition
+	persisted bool
+	pending   map[LogPosition]struct{}
+}
+
+// OpenLogCheckpoint loads the checkpoint stored at path, if any.
+func OpenLogCheckpoint(path string) (*LogCheckpoint, error) {
+	c := &LogCheckpoint{
+		path:    path,
+		pending: map[LogPosition]struct{}{},
+	}
+	data, err := os.ReadFile(filepath.Clean(path))
+	if os.IsNotExist(err) {
+		return c, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read checkpoint: %w", err)
+	}
+	if err := json.Unmarshal(data, &c.position); err != nil {
+		return nil, fmt.Errorf("failed to parse checkpoint %s: %w", path, err)
+	}
+	c.persisted = true
+	return c, nil
+}
+
+// Position returns the position to resume from and whether one was persisted.
+func (c *LogCheckpoint) Position() (LogPosition, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.position, c.persisted
+}
+
+// Begin marks the handling of a log as started.
+func (c *LogCheckpoint) Begin(l ethtypes.Log) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.pending[PositionOf(l)] = struct{}{}
+}
+
+// Done marks the handling of a log as ended and persists the new resume position.
+func (c *LogCheckpoint) Done(l ethtypes.Log) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	pos := PositionOf(l)
+	delete(c.pending, pos)
+	if c.position.Less(pos.next()) {
+		c.position = pos.next()
+	}
+	return c.save()
+}
+
+func (c *LogCheckpoint) save() error {
+	resume := c.position
+	for pos := range c.pending {
+		if pos.Less(resume) {
+			resume = pos
+		}
+	}
+	data, err := json.Marshal(resume)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(c.path), 0o755); err != nil {
+		return fmt.Errorf("failed to create checkpoint directory: %w", err)
+	}
+	tmp := c.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write checkpoint: %w", err)
+	}
+	if err := os.Rename(tmp, c.path); err != nil {
+		return fmt.Errorf("failed to write checkpoint: %w", err)
+	}
+	c.persisted = true
+	return nil
+}
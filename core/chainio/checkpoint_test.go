@@ -0,0 +1,48 @@
+package chainio_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	chain "github.com/imua-xyz/imua-avs/core/chainio"
+)
+
+func TestLogCheckpointKeepsOldestPendingLog(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "checkpoint.json")
+	c, err := chain.OpenLogCheckpoint(path)
+	if err != nil {
+		t.Fatalf("Error opening checkpoint: %v", err)
+	}
+	if _, ok := c.Position(); ok {
+		t.Fatalf("Expected no position before anything is persisted")
+	}
+
+	waiting := ethtypes.Log{BlockNumber: 10, Index: 2}
+	handled := ethtypes.Log{BlockNumber: 12, Index: 0}
+	c.Begin(waiting)
+	c.Begin(handled)
+	if err := c.Done(handled); err != nil {
+		t.Fatalf("Error persisting checkpoint: %v", err)
+	}
+
+	// a restart must rescan the log still waiting for its window
+	reopened, err := chain.OpenLogCheckpoint(path)
+	if err != nil {
+		t.Fatalf("Error reopening checkpoint: %v", err)
+	}
+	if pos, ok := reopened.Position(); !ok || pos != (chain.LogPosition{BlockNumber: 10, Index: 2}) {
+		t.Fatalf("Expected resume position 10/2, but got %+v (persisted %v)", pos, ok)
+	}
+
+	if err := c.Done(waiting); err != nil {
+		t.Fatalf("Error persisting checkpoint: %v", err)
+	}
+	reopened, err = chain.OpenLogCheckpoint(path)
+	if err != nil {
+		t.Fatalf("Error reopening checkpoint: %v", err)
+	}
+	if pos, _ := reopened.Position(); pos != (chain.LogPosition{BlockNumber: 12, Index: 1}) {
+		t.Fatalf("Expected resume position 12/1, but got %+v", pos)
+	}
+}
@@ -0,0 +1,70 @@
+package eth
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// ObserveFunc records the latency and the outcome of an RPC call started at start.
+type ObserveFunc func(method string, start time.Time, err error)
+
+// ObservedClient is an EthClient that reports the calls the task flows make, the contract
+// reads, the transactions and their receipts, to an ObserveFunc, labeled with their JSON-RPC
+// method. The other calls go straight to the wrapped client.
+type ObservedClient struct {
+	EthClient
+	observe ObserveFunc
+}
+
+var _ EthClient = (*ObservedClient)(nil)
+
+// NewObservedClient returns client reporting its calls to observe.
+func NewObservedClient(client EthClient, observe ObserveFunc) *ObservedClient {
+	return &ObservedClient{EthClient: client, observe: observe}
+}
+
+func (c *ObservedClient) CallContract(ctx context.Context, msg ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	start := time.Now()
+	out, err := c.EthClient.CallContract(ctx, msg, blockNumber)
+	c.observe("eth_call", start, err)
+	return out, err
+}
+
+func (c *ObservedClient) EstimateGas(ctx context.Context, msg ethereum.CallMsg) (uint64, error) {
+	start := time.Now()
+	gas, err := c.EthClient.EstimateGas(ctx, msg)
+	c.observe("eth_estimateGas", start, err)
+	return gas, err
+}
+
+func (c *ObservedClient) FilterLogs(ctx context.Context, q ethereum.FilterQuery) ([]types.Log, error) {
+	start := time.Now()
+	logs, err := c.EthClient.FilterLogs(ctx, q)
+	c.observe("eth_getLogs", start, err)
+	return logs, err
+}
+
+func (c *ObservedClient) SendTransaction(ctx context.Context, tx *types.Transaction) error {
+	start := time.Now()
+	err := c.EthClient.SendTransaction(ctx, tx)
+	c.observe("eth_sendRawTransaction", start, err)
+	return err
+}
+
+func (c *ObservedClient) TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error) {
+	start := time.Now()
+	receipt, err := c.EthClient.TransactionReceipt(ctx, txHash)
+	// a transaction that is not mined yet is the expected answer while waiting for it
+	observed := err
+	if errors.Is(err, ethereum.NotFound) {
+		observed = nil
+	}
+	c.observe("eth_getTransactionReceipt", start, observed)
+	return receipt, err
+}
@@ -0,0 +1,212 @@
+package chainio
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	avs "github.com/imua-xyz/imua-avs/contracts/bindings/avs"
+)
+
+// avsManagerEventsABI are the events of IAVSManager the AVS manager precompile emits for an AVS.
+const avsManagerEventsABI = `[
+	{"type":"event","name":"OperatorJoined","inputs":[
+		{"name":"avsAddress","type":"address","indexed":true},
+		{"name":"sender","type":"address","indexed":false}]},
+	{"type":"event","name":"OperatorLeft","inputs":[
+		{"name":"avsAddress","type":"address","indexed":true},
+		{"name":"sender","type":"address","indexed":false}]},
+	{"type":"event","name":"ChallengeInitiated","inputs":[
+		{"name":"taskID","type":"uint64","indexed":true},
+		{"name":"taskContractAddress","type":"address","indexed":true},
+		{"name":"sender","type":"address","indexed":false},
+		{"name":"actualThreshold","type":"uint8","indexed":false},
+		{"name":"isExpected","type":"bool","indexed":false},
+		{"name":"eligibleRewardOperators","type":"address[]","indexed":false},
+		{"name":"eligibleSlashOperators","type":"address[]","indexed":false}]},
+	{"type":"event","name":"PublicKeyRegistered","inputs":[
+		{"name":"sender","type":"address","indexed":false},
+		{"name":"avsAddress","type":"address","indexed":false}]},
+	{"type":"event","name":"TaskSubmittedByOperator","inputs":[
+		{"name":"taskContractAddress","type":"address","indexed":true},
+		{"name":"taskID","type":"uint64","indexed":true},
+		{"name":"sender","type":"address","indexed":false},
+		{"name":"taskResponse","type":"bytes","indexed":false},
+		{"name":"blsSignature","type":"bytes","indexed":false},
+		{"name":"phase","type":"uint8","indexed":false}]}
+]`
+
+// OperatorJoined is emitted by the AVS manager precompile when an operator opts in to an AVS.
+type OperatorJoined struct {
+	AvsAddress common.Address
+	Sender     common.Address
+	Raw        ethtypes.Log
+}
+
+// OperatorLeft is emitted by the AVS manager precompile when an operator opts out of an AVS.
+type OperatorLeft struct {
+	AvsAddress common.Address
+	Sender     common.Address
+	Raw        ethtypes.Log
+}
+
+// ChallengeInitiated is emitted by the AVS manager precompile when a challenge of a task is resolved.
+type ChallengeInitiated struct {
+	TaskID                  uint64
+	TaskContractAddress     common.Address
+	Sender                  common.Address
+	ActualThreshold         uint8
+	IsExpected              bool
+	EligibleRewardOperators []common.Address
+	EligibleSlashOperators  []common.Address
+	Raw                     ethtypes.Log
+}
+
+// PublicKeyRegistered is emitted by the AVS manager precompile when an operator registers its BLS public key.
+type PublicKeyRegistered struct {
+	Sender     common.Address
+	AvsAddress common.Address
+	Raw        ethtypes.Log
+}
+
+// TaskSubmittedByOperator is emitted by the AVS manager precompile for every phase an operator submits.
+type TaskSubmittedByOperator struct {
+	TaskContractAddress common.Address
+	TaskID              uint64
+	Sender              common.Address
+	TaskResponse        []byte
+	BlsSignature        []byte
+	Phase               uint8
+	Raw                 ethtypes.Log
+}
+
+var (
+	// ErrUnhandledEvent is returned by EventRouter.Dispatch for a log no handler is registered for.
+	ErrUnhandledEvent = errors.New("no handler for event")
+	// ErrInvalidEvent is returned for a log that is not a known event or does not decode as one.
+	ErrInvalidEvent = errors.New("invalid event log")
+)
+
+// eventType decodes the logs of one event.
+type eventType struct {
+	decode func(vLog ethtypes.Log) (interface{}, error)
+}
+
+var (
+	// eventTypes maps the topic of every known event to its decoder.
+	eventTypes = map[common.Hash]eventType{}
+	// eventTopics maps the Go type of every known event to its topic.
+	eventTopics = map[reflect.Type]common.Hash{}
+)
+
+func init() {
+	contractABI, err := avs.ContracthelloWorldMetaData.GetAbi()
+	if err != nil {
+		panic(err)
+	}
+	precompileABI, err := abi.JSON(strings.NewReader(avsManagerEventsABI))
+	if err != nil {
+		panic(err)
+	}
+	registerEvent(contractABI, "TaskCreated", func(e *avs.ContracthelloWorldTaskCreated) *ethtypes.Log { return &e.Raw })
+	registerEvent(contractABI, "TaskResolved", func(e *avs.ContracthelloWorldTaskResolved) *ethtypes.Log { return &e.Raw })
+	registerEvent(&precompileABI, "TaskSubmittedByOperator", func(e *TaskSubmittedByOperator) *ethtypes.Log { return &e.Raw })
+	registerEvent(&precompileABI, "ChallengeInitiated", func(e *ChallengeInitiated) *ethtypes.Log { return &e.Raw })
+	registerEvent(&precompileABI, "OperatorJoined", func(e *OperatorJoined) *ethtypes.Log { return &e.Raw })
+	registerEvent(&precompileABI, "OperatorLeft", func(e *OperatorLeft) *ethtypes.Log { return &e.Raw })
+	registerEvent(&precompileABI, "PublicKeyRegistered", func(e *PublicKeyRegistered) *ethtypes.Log { return &e.Raw })
+}
+
+// registerEvent makes the event name of contractABI decodable into E, raw locates its Raw field.
+func registerEvent[E any](contractABI *abi.ABI, name string, raw func(*E) *ethtypes.Log) {
+	contract := bind.NewBoundContract(common.Address{}, *contractABI, nil, nil, nil)
+	topic := contractABI.Events[name].ID
+	eventTypes[topic] = eventType{
+		decode: func(vLog ethtypes.Log) (interface{}, error) {
+			e := new(E)
+			if err := contract.UnpackLog(e, name, vLog); err != nil {
+				return nil, fmt.Errorf("%w: failed to unpack %s: %v", ErrInvalidEvent, name, err)
+			}
+			*raw(e) = vLog
+			return e, nil
+		},
+	}
+	eventTopics[reflect.TypeOf((*E)(nil))] = topic
+}
+
+// EventTopic returns the topic of the event decoded into E.
+func EventTopic[E any]() common.Hash {
+	topic, ok := eventTopics[reflect.TypeOf((*E)(nil))]
+	if !ok {
+		panic(fmt.Sprintf("%T is not a known event", (*E)(nil)))
+	}
+	return topic
+}
+
+// DecodeEvent decodes a log of the AVS contract or of the AVS manager precompile into its typed
+// event, *avs.ContracthelloWorldTaskCreated, *avs.ContracthelloWorldTaskResolved or one of the
+// precompile events of this package, chosen by the topic of the log.
+func DecodeEvent(vLog ethtypes.Log) (interface{}, error) {
+	if len(vLog.Topics) == 0 {
+		return nil, fmt.Errorf("%w: log has no topics", ErrInvalidEvent)
+	}
+	t, ok := eventTypes[vLog.Topics[0]]
+	if !ok {
+		return nil, fmt.Errorf("%w: unknown event topic %s", ErrInvalidEvent, vLog.Topics[0].Hex())
+	}
+	return t.decode(vLog)
+}
+
+// EventRouter decodes logs and passes them to the handler registered for their event type.
+// Handlers are registered with OnEvent before the router is used.
+type EventRouter struct {
+	handlers map[common.Hash]func(ctx context.Context, event interface{}) error
+}
+
+// NewEventRouter returns a router without handlers.
+func NewEventRouter() *EventRouter {
+	return &EventRouter{handlers: map[common.Hash]func(ctx context.Context, event interface{}) error{}}
+}
+
+// OnEvent registers handler for the logs of the event decoded into E, replacing any handler
+// registered before. It panics if E is not a known event.
+func OnEvent[E any](r *EventRouter, handler func(ctx context.Context, event *E) error) {
+	r.handlers[EventTopic[E]()] = func(ctx context.Context, event interface{}) error {
+		return handler(ctx, event.(*E))
+	}
+}
+
+// Topics returns the topics of the events handlers are registered for.
+func (r *EventRouter) Topics() []common.Hash {
+	topics := make([]common.Hash, 0, len(r.handlers))
+	for topic := range r.handlers {
+		topics = append(topics, topic)
+	}
+	sort.Slice(topics, func(i, j int) bool { return bytes.Compare(topics[i][:], topics[j][:]) < 0 })
+	return topics
+}
+
+// Dispatch decodes vLog and runs the handler of its event type, returning the error of the
+// handler. Logs without a handler return ErrUnhandledEvent, logs that do not decode ErrInvalidEvent.
+func (r *EventRouter) Dispatch(ctx context.Context, vLog ethtypes.Log) error {
+	if len(vLog.Topics) == 0 {
+		return fmt.Errorf("%w: log has no topics", ErrInvalidEvent)
+	}
+	handler, ok := r.handlers[vLog.Topics[0]]
+	if !ok {
+		return fmt.Errorf("%w with topic %s", ErrUnhandledEvent, vLog.Topics[0].Hex())
+	}
+	event, err := DecodeEvent(vLog)
+	if err != nil {
+		return err
+	}
+	return handler(ctx, event)
+}
@@ -0,0 +1,87 @@
+package chainio_test
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	avs "github.com/imua-xyz/imua-avs/contracts/bindings/avs"
+	chain "github.com/imua-xyz/imua-avs/core/chainio"
+)
+
+func TestEventRouter(t *testing.T) {
+	contractABI, err := avs.ContracthelloWorldMetaData.GetAbi()
+	if err != nil {
+		t.Fatalf("Error loading ABI: %v", err)
+	}
+	avsAddr := common.HexToAddress("0x10Ed22D975453A5D4031440D51624552E4f204D5")
+	operator := common.HexToAddress("0x3e108c058e8066DA635321Dc3018294cA82ddEdf")
+
+	data, err := contractABI.Events["TaskCreated"].Inputs.Pack(
+		big.NewInt(7), operator, "square:aB3dE", uint64(12), uint64(3), uint64(5), uint8(80), uint64(2))
+	if err != nil {
+		t.Fatalf("Error packing TaskCreated: %v", err)
+	}
+	taskCreated := ethtypes.Log{Address: avsAddr, Topics: []common.Hash{contractABI.Events["TaskCreated"].ID}, Data: data, BlockNumber: 9}
+
+	// the AVS address is indexed, the sender is the only data word
+	joinedTopic := crypto.Keccak256Hash([]byte("OperatorJoined(address,address)"))
+	if topic := chain.EventTopic[chain.OperatorJoined](); topic != joinedTopic {
+		t.Fatalf("OperatorJoined topic = %s, want %s", topic.Hex(), joinedTopic.Hex())
+	}
+	operatorJoined := ethtypes.Log{
+		Topics: []common.Hash{joinedTopic, common.BytesToHash(avsAddr.Bytes())},
+		Data:   common.LeftPadBytes(operator.Bytes(), 32),
+	}
+
+	router := chain.NewEventRouter()
+	var created *avs.ContracthelloWorldTaskCreated
+	var joined *chain.OperatorJoined
+	chain.OnEvent(router, func(_ context.Context, e *avs.ContracthelloWorldTaskCreated) error {
+		created = e
+		return nil
+	})
+	chain.OnEvent(router, func(_ context.Context, e *chain.OperatorJoined) error {
+		joined = e
+		return nil
+	})
+
+	ctx := context.Background()
+	if err := router.Dispatch(ctx, taskCreated); err != nil {
+		t.Fatalf("Error dispatching TaskCreated: %v", err)
+	}
+	if created == nil || created.TaskId.Uint64() != 7 || created.Name != "square:aB3dE" || created.NumberToBeSquared != 12 ||
+		created.ThresholdPercentage != 80 || created.Raw.BlockNumber != 9 {
+		t.Errorf("TaskCreated = %+v, want the packed fields and the raw log", created)
+	}
+	if err := router.Dispatch(ctx, operatorJoined); err != nil {
+		t.Fatalf("Error dispatching OperatorJoined: %v", err)
+	}
+	if joined == nil || joined.AvsAddress != avsAddr || joined.Sender != operator {
+		t.Errorf("OperatorJoined = %+v, want avs %s and sender %s", joined, avsAddr, operator)
+	}
+
+	resolved := ethtypes.Log{Topics: []common.Hash{contractABI.Events["TaskResolved"].ID}}
+	if err := router.Dispatch(ctx, resolved); !errors.Is(err, chain.ErrUnhandledEvent) {
+		t.Errorf("Dispatch of an unhandled event returned %v, want ErrUnhandledEvent", err)
+	}
+	// a log whose data does not match its topic is rejected, not decoded as another event
+	truncated := taskCreated
+	truncated.Data = data[:32]
+	if err := router.Dispatch(ctx, truncated); !errors.Is(err, chain.ErrInvalidEvent) {
+		t.Errorf("Dispatch of a truncated log returned %v, want ErrInvalidEvent", err)
+	}
+	if _, err := chain.DecodeEvent(ethtypes.Log{Topics: []common.Hash{{1}}}); !errors.Is(err, chain.ErrInvalidEvent) {
+		t.Errorf("DecodeEvent of an unknown topic returned %v, want ErrInvalidEvent", err)
+	}
+
+	handlerErr := errors.New("handler failed")
+	chain.OnEvent(router, func(context.Context, *avs.ContracthelloWorldTaskCreated) error { return handlerErr })
+	if err := router.Dispatch(ctx, taskCreated); !errors.Is(err, handlerErr) {
+		t.Errorf("Dispatch returned %v, want the error of the handler", err)
+	}
+}
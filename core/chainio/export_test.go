@@ -0,0 +1,8 @@
+package chainio
+
+import "time"
+
+// SetMineTimeout shortens the mine timeout of q for tests. It has to be called before q sends.
+func (q *TxQueue) SetMineTimeout(d time.Duration) {
+	q.mineTimeout = d
+}
@@ -0,0 +1,132 @@
+package chainio
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	gethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	gethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/imua-xyz/imua-avs-sdk/signer"
+)
+
+const (
+	// FundingSignerLocal signs with a key decrypted from a local keystore file.
+	FundingSignerLocal = "local"
+	// FundingSignerRemote signs through the eth_signTransaction method of a remote
+	// signer such as web3signer or clef.
+	FundingSignerRemote = "remote"
+)
+
+// FundingSignerConfig selects the signer of the account that funds deposits and delegations.
+type FundingSignerConfig struct {
+	// Type is FundingSignerLocal or FundingSignerRemote, empty means FundingSignerLocal.
+	Type         string
+	KeystorePath string
+	Password     string
+	RemoteURL    string
+	// Address is the funding account on the remote signer, unused by a local signer.
+	Address string
+}
+
+// NewFundingSigner returns the signer and the address of the funding account selected by c.
+func NewFundingSigner(
+	ctx context.Context,
+	c FundingSignerConfig,
+	chainID *big.Int,
+) (signer.SignerFn, gethcommon.Address, error) {
+	switch c.Type {
+	case "", FundingSignerLocal:
+		if c.KeystorePath == "" {
+			return nil, gethcommon.Address{}, fmt.Errorf("funding keystore path is not configured")
+		}
+		return signer.SignerFromConfig(signer.Config{KeystorePath: c.KeystorePath, Password: c.Password}, chainID)
+	case FundingSignerRemote:
+		if c.RemoteURL == "" || !gethcommon.IsHexAddress(c.Address) {
+			return nil, gethcommon.Address{}, fmt.Errorf("a remote funding signer needs a url and a hex funding address")
+		}
+		client, err := rpc.DialContext(ctx, c.RemoteURL)
+		if err != nil {
+			return nil, gethcommon.Address{}, fmt.Errorf("failed to dial remote funding signer: %w", err)
+		}
+		from := gethcommon.HexToAddress(c.Address)
+		signerFn := func(ctx context.Context, address gethcommon.Address) (bind.SignerFn, error) {
+			return remoteTxSignerFn(ctx, client, from, chainID), nil
+		}
+		return signerFn, from, nil
+	default:
+		return nil, gethcommon.Address{}, fmt.Errorf("unknown funding signer type %q, expected %q or %q",
+			c.Type, FundingSignerLocal, FundingSignerRemote)
+	}
+}
+
+// signTxArgs are the eth_signTransaction arguments of an EIP-1559 transaction.
+type signTxArgs struct {
+	From                 gethcommon.Address  `json:"from"`
+	To                   *gethcommon.Address `json:"to,omitempty"`
+	Gas                  hexutil.Uint64      `json:"gas"`
+	MaxFeePerGas         *hexutil.Big        `json:"maxFeePerGas"`
+	MaxPriorityFeePerGas *hexutil.Big        `json:"maxPriorityFeePerGas"`
+	Value                *hexutil.Big        `json:"value"`
+	Nonce                hexutil.Uint64      `json:"nonce"`
+	Data                 hexutil.Bytes       `json:"data"`
+	ChainID              *hexutil.Big        `json:"chainId"`
+}
+
+// remoteTxSignerFn signs transactions of from through eth_signTransaction. Web3signer answers
+// with the raw signed transaction, clef with an object holding it as "raw", both are accepted.
+func remoteTxSignerFn(ctx context.Context, client *rpc.Client, from gethcommon.Address, chainID *big.Int) bind.SignerFn {
+	return func(address gethcommon.Address, tx *gethtypes.Transaction) (*gethtypes.Transaction, error) {
+		if address != from {
+			return nil, bind.ErrNotAuthorized
+		}
+		args := signTxArgs{
+			From:                 from,
+			To:                   tx.To(),
+			Gas:                  hexutil.Uint64(tx.Gas()),
+			MaxFeePerGas:         (*hexutil.Big)(tx.GasFeeCap()),
+			MaxPriorityFeePerGas: (*hexutil.Big)(tx.GasTipCap()),
+			Value:                (*hexutil.Big)(tx.Value()),
+			Nonce:                hexutil.Uint64(tx.Nonce()),
+			Data:                 tx.Data(),
+			ChainID:              (*hexutil.Big)(chainID),
+		}
+		var result json.RawMessage
+		if err := client.CallContext(ctx, &result, "eth_signTransaction", args); err != nil {
+			return nil, fmt.Errorf("remote funding signer failed to sign: %w", err)
+		}
+		var raw hexutil.Bytes
+		if strings.HasPrefix(strings.TrimSpace(string(result)), "{") {
+			var signed struct {
+				Raw hexutil.Bytes `json:"raw"`
+			}
+			if err := json.Unmarshal(result, &signed); err != nil {
+				return nil, err
+			}
+			raw = signed.Raw
+		} else if err := json.Unmarshal(result, &raw); err != nil {
+			return nil, err
+		}
+		signedTx := new(gethtypes.Transaction)
+		if err := signedTx.UnmarshalBinary(raw); err != nil {
+			return nil, fmt.Errorf("remote funding signer returned an invalid transaction: %w", err)
+		}
+		// the signer must have signed what was asked, from the expected account
+		sender, err := gethtypes.Sender(gethtypes.LatestSignerForChainID(chainID), signedTx)
+		if err != nil {
+			return nil, err
+		}
+		sameTo := (signedTx.To() == nil) == (tx.To() == nil) && (tx.To() == nil || *signedTx.To() == *tx.To())
+		if sender != from || signedTx.Nonce() != tx.Nonce() || !sameTo ||
+			!bytes.Equal(signedTx.Data(), tx.Data()) || signedTx.Value().Cmp(tx.Value()) != 0 {
+			return nil, fmt.Errorf("remote funding signer returned a transaction that differs from the request")
+		}
+		return signedTx, nil
+	}
+}
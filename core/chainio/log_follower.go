@@ -0,0 +1,147 @@
+package chainio
+
+import (
+	"context"
+	"errors"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/imua-xyz/imua-avs-sdk/logging"
+)
+
+// LogFollower handles the logs of a contract across restarts. Run first backfills the logs
+// emitted since the checkpoint, then hands over to a live subscription that resumes from the
+// backfilled head. Every log is pending in the checkpoint while it is handled: a handler that
+// returns nil owns its log and marks it with Done once the work it started ends, logs without
+// a handler or that do not decode are done right away, and logs whose handler fails stay
+// pending, so the next start handles them again.
+type LogFollower struct {
+	client     LogFilterer
+	dial       LogDialer
+	query      ethereum.FilterQuery
+	checkpoint *LogCheckpoint
+	logger     logging.Logger
+
+	// startBlock is where the first start backfills from, 0 only follows new logs
+	startBlock         uint64
+	onConnectionChange func(up bool)
+}
+
+// NewLogFollower returns a LogFollower that backfills through client and subscribes over wsURL
+// to the logs matching query.
+func NewLogFollower(client LogFilterer, wsURL string, query ethereum.FilterQuery, checkpoint *LogCheckpoint, logger logging.Logger) *LogFollower {
+	return NewLogFollowerWithDialer(client, func(ctx context.Context) (LogSource, error) {
+		return ethclient.DialContext(ctx, wsURL)
+	}, query, checkpoint, logger)
+}
+
+// NewLogFollowerWithDialer returns a LogFollower that subscribes through the connections of dial.
+func NewLogFollowerWithDialer(client LogFilterer, dial LogDialer, query ethereum.FilterQuery, checkpoint *LogCheckpoint, logger logging.Logger) *LogFollower {
+	return &LogFollower{
+		client:     client,
+		dial:       dial,
+		query:      query,
+		checkpoint: checkpoint,
+		logger:     logger,
+	}
+}
+
+// StartAt makes a first start, without a persisted checkpoint, backfill the logs from block
+// onwards. It must be called before Run.
+func (f *LogFollower) StartAt(block uint64) {
+	f.startBlock = block
+}
+
+// OnConnectionChange is passed to the live subscription, see LogSubscriber.OnConnectionChange.
+// It must be called before Run.
+func (f *LogFollower) OnConnectionChange(fn func(up bool)) {
+	f.onConnectionChange = fn
+}
+
+// Run handles the backfilled logs with backfillEvents, whose events are the only ones fetched,
+// then the logs of the subscription with events, until ctx is done. It returns the context error.
+func (f *LogFollower) Run(ctx context.Context, events, backfillEvents *EventRouter) error {
+	// missed events are backfilled first, the subscription then resumes from the backfilled head
+	// block, whose logs are already handled when they arrive again
+	backfilledTo, err := f.backfill(ctx, backfillEvents)
+	if err != nil {
+		f.logger.Error("Backfill of missed events failed", "err", err)
+	}
+
+	logs := make(chan ethtypes.Log)
+	subscriber := NewLogSubscriberWithDialer(f.dial, f.query, f.logger)
+	if backfilledTo > 0 {
+		subscriber.ResumeFrom(backfilledTo)
+	}
+	if f.onConnectionChange != nil {
+		subscriber.OnConnectionChange(f.onConnectionChange)
+	}
+	go subscriber.Run(ctx, logs)
+
+	f.logger.Infof("Starting event monitoring...")
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case vLog := <-logs:
+			if vLog.BlockNumber <= backfilledTo {
+				continue
+			}
+			f.handle(ctx, vLog, events)
+		}
+	}
+}
+
+// backfill handles the events of router emitted while the process was not running. It scans
+// from the persisted checkpoint, or from the start block on a first start, up to the head
+// block, which it returns.
+func (f *LogFollower) backfill(ctx context.Context, router *EventRouter) (uint64, error) {
+	from, ok := f.checkpoint.Position()
+	if !ok {
+		if f.startBlock == 0 {
+			return 0, nil
+		}
+		from = LogPosition{BlockNumber: f.startBlock}
+	}
+	query := f.query
+	query.Topics = [][]common.Hash{router.Topics()}
+	logs, head, err := BackfillLogs(ctx, f.client, query, from, BackfillBatchSize)
+	if err != nil {
+		return 0, err
+	}
+	f.logger.Info("Backfilling missed events", "fromBlock", from.BlockNumber, "toBlock", head, "events", len(logs))
+	for _, vLog := range logs {
+		f.handle(ctx, vLog, router)
+	}
+	return head, nil
+}
+
+// handle dispatches one log to router, it stays pending in the checkpoint until its handler
+// is done with it.
+func (f *LogFollower) handle(ctx context.Context, vLog ethtypes.Log, router *EventRouter) {
+	f.checkpoint.Begin(vLog)
+	err := router.Dispatch(ctx, vLog)
+	switch {
+	case err == nil:
+		return
+	case errors.Is(err, ErrUnhandledEvent):
+		f.logger.Debug("Ignoring event of the contract", "block", vLog.BlockNumber, "err", err)
+	case errors.Is(err, ErrInvalidEvent):
+		f.logger.Info("Ignoring log that does not decode", "block", vLog.BlockNumber, "err", err)
+	default:
+		f.logger.Error("Failed to handle log, it is handled again on restart", "block", vLog.BlockNumber, "err", err)
+		return
+	}
+	f.Done(vLog)
+}
+
+// Done marks a log handled in the checkpoint. A failure to persist it is logged, the log is
+// then handled again on restart.
+func (f *LogFollower) Done(vLog ethtypes.Log) {
+	if err := f.checkpoint.Done(vLog); err != nil {
+		f.logger.Error("Failed to persist log checkpoint", "block", vLog.BlockNumber, "err", err)
+	}
+}
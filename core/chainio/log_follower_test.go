@@ -0,0 +1,103 @@
+package chainio_test
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	sdklogging "github.com/imua-xyz/imua-avs-sdk/logging"
+	avs "github.com/imua-xyz/imua-avs/contracts/bindings/avs"
+	chain "github.com/imua-xyz/imua-avs/core/chainio"
+)
+
+func TestLogFollowerKeepsUnfinishedLogsPending(t *testing.T) {
+	contractABI, err := avs.ContracthelloWorldMetaData.GetAbi()
+	if err != nil {
+		t.Fatalf("Error loading ABI: %v", err)
+	}
+	avsAddr := common.HexToAddress("0x10Ed22D975453A5D4031440D51624552E4f204D5")
+	taskResolved := func(block, taskID uint64) ethtypes.Log {
+		data, err := contractABI.Events["TaskResolved"].Inputs.Pack(taskID, avsAddr)
+		if err != nil {
+			t.Fatalf("Error packing TaskResolved: %v", err)
+		}
+		l := testLog(block, 0)
+		l.Address, l.Topics, l.Data = avsAddr, []common.Hash{chain.EventTopic[avs.ContracthelloWorldTaskResolved]()}, data
+		return l
+	}
+	unhandled := testLog(7, 0)
+	unhandled.Topics = []common.Hash{{1}}
+	// task 1 is finished right away, task 2 is still being worked on and task 3 fails
+	history := []ethtypes.Log{taskResolved(3, 1), taskResolved(4, 2), taskResolved(5, 3), unhandled}
+
+	path := filepath.Join(t.TempDir(), "checkpoint.json")
+	checkpoint, err := chain.OpenLogCheckpoint(path)
+	if err != nil {
+		t.Fatalf("Error opening checkpoint: %v", err)
+	}
+	logger, err := sdklogging.NewZapLogger(sdklogging.Development)
+	if err != nil {
+		t.Fatalf("Error creating logger: %v", err)
+	}
+	fake := &fakeChain{head: 10, history: history}
+	f := chain.NewLogFollowerWithDialer(&fakeConn{chain: fake}, fake.dial, ethereum.FilterQuery{Addresses: []common.Address{avsAddr}}, checkpoint, logger)
+	f.StartAt(1)
+
+	handled := make(chan uint64, len(history))
+	router := chain.NewEventRouter()
+	chain.OnEvent(router, func(ctx context.Context, e *avs.ContracthelloWorldTaskResolved) error {
+		handled <- e.TaskId
+		switch e.TaskId {
+		case 1:
+			f.Done(e.Raw)
+		case 3:
+			return errors.New("rpc down")
+		}
+		return nil
+	})
+	if topics := router.Topics(); len(topics) != 1 || topics[0] != chain.EventTopic[avs.ContracthelloWorldTaskResolved]() {
+		t.Fatalf("Expected the router to report the TaskResolved topic, but got %v", topics)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	stopped := make(chan error)
+	go func() { stopped <- f.Run(ctx, chain.NewEventRouter(), router) }()
+	for want := uint64(1); want <= 3; want++ {
+		select {
+		case got := <-handled:
+			if got != want {
+				t.Fatalf("Expected task %d to be handled, but got %d", want, got)
+			}
+		case <-ctx.Done():
+			t.Fatalf("Timed out waiting for task %d", want)
+		}
+	}
+	cancel()
+	if err := <-stopped; !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected Run to return the context error, but got %v", err)
+	}
+
+	// task 2 is still pending, so a restart resumes from its log
+	reopened, err := chain.OpenLogCheckpoint(path)
+	if err != nil {
+		t.Fatalf("Error reopening checkpoint: %v", err)
+	}
+	if pos, ok := reopened.Position(); !ok || pos != chain.PositionOf(history[1]) {
+		t.Errorf("Expected to resume from %+v, but got %+v (persisted %v)", chain.PositionOf(history[1]), pos, ok)
+	}
+	// once it is done, the failed task 3 holds the checkpoint back
+	f.Done(history[1])
+	reopened, err = chain.OpenLogCheckpoint(path)
+	if err != nil {
+		t.Fatalf("Error reopening checkpoint: %v", err)
+	}
+	if pos, _ := reopened.Position(); pos != chain.PositionOf(history[2]) {
+		t.Errorf("Expected to resume from %+v, but got %+v", chain.PositionOf(history[2]), pos)
+	}
+}
@@ -0,0 +1,202 @@
+package chainio
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/imua-xyz/imua-avs-sdk/logging"
+)
+
+const (
+	minResubscribeBackoff = 1 * time.Second
+	maxResubscribeBackoff = 1 * time.Minute
+	// dedupeDepth is how many blocks below the last delivered one are remembered for deduplication.
+	// It covers the logs a fresh subscription delivers again after a gap fill.
+	dedupeDepth = 1024
+)
+
+// LogSource is a connection logs can be fetched from and subscribed to.
+type LogSource interface {
+	LogFilterer
+	SubscribeFilterLogs(ctx context.Context, q ethereum.FilterQuery, ch chan<- ethtypes.Log) (ethereum.Subscription, error)
+	Close()
+}
+
+// LogDialer opens a new LogSource.
+type LogDialer func(ctx context.Context) (LogSource, error)
+
+type logKey struct {
+	blockHash common.Hash
+	index     uint
+}
+
+// LogSubscriber delivers the logs matching a filter query over a websocket connection that heals itself.
+// When the subscription fails it redials with exponential backoff, subscribes again and fills the gap
+// with eth_getLogs from the last block it delivered. Logs are deduplicated by block hash and log index,
+// so a consumer never sees a log twice. Removed logs of reorged blocks are not delivered.
+type LogSubscriber struct {
+	dial   LogDialer
+	query  ethereum.FilterQuery
+	logger logging.Logger
+
+	// cursor is the last block logs were delivered from, every log before it has been delivered
+	cursor    uint64
+	cursorSet bool
+	seen      map[logKey]uint64
+
+	onConnectionChange func(up bool)
+	connected          bool
+}
+
+// NewLogSubscriber returns a LogSubscriber that dials wsURL.
+func NewLogSubscriber(wsURL string, query ethereum.FilterQuery, logger logging.Logger) *LogSubscriber {
+	return NewLogSubscriberWithDialer(func(ctx context.Context) (LogSource, error) {
+		return ethclient.DialContext(ctx, wsURL)
+	}, query, logger)
+}
+
+// NewLogSubscriberWithDialer returns a LogSubscriber that connects with dial.
+func NewLogSubscriberWithDialer(dial LogDialer, query ethereum.FilterQuery, logger logging.Logger) *LogSubscriber {
+	return &LogSubscriber{
+		dial:   dial,
+		query:  query,
+		logger: logger,
+		seen:   map[logKey]uint64{},
+	}
+}
+
+// ResumeFrom makes the first subscription start by delivering the logs from block onwards.
+// Without it the first subscription only delivers logs emitted after it is established.
+// It must be called before Run.
+func (s *LogSubscriber) ResumeFrom(block uint64) {
+	s.cursor = block
+	s.cursorSet = true
+}
+
+// OnConnectionChange makes the subscriber call f with true whenever a subscription is established,
+// and with false whenever it is lost. It must be called before Run.
+func (s *LogSubscriber) OnConnectionChange(f func(up bool)) {
+	s.onConnectionChange = f
+}
+
+func (s *LogSubscriber) setConnected(up bool) {
+	if up == s.connected {
+		return
+	}
+	s.connected = up
+	if s.onConnectionChange != nil {
+		s.onConnectionChange(up)
+	}
+}
+
+// Run delivers logs to out until ctx is done, and returns the context error.
+func (s *LogSubscriber) Run(ctx context.Context, out chan<- ethtypes.Log) error {
+	backoff := minResubscribeBackoff
+	for {
+		subscribed, err := s.session(ctx, out)
+		s.setConnected(false)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if subscribed {
+			backoff = minResubscribeBackoff
+		}
+		s.logger.Error("Log subscription lost, resubscribing", "err", err, "backoff", backoff)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+		if !subscribed {
+			backoff *= 2
+			if backoff > maxResubscribeBackoff {
+				backoff = maxResubscribeBackoff
+			}
+		}
+	}
+}
+
+// session runs one subscription until it fails. It reports whether the subscription was established.
+func (s *LogSubscriber) session(ctx context.Context, out chan<- ethtypes.Log) (bool, error) {
+	client, err := s.dial(ctx)
+	if err != nil {
+		return false, fmt.Errorf("failed to dial: %w", err)
+	}
+	defer client.Close()
+
+	logs := make(chan ethtypes.Log, 64)
+	sub, err := client.SubscribeFilterLogs(ctx, s.query, logs)
+	if err != nil {
+		return false, fmt.Errorf("failed to subscribe: %w", err)
+	}
+	defer sub.Unsubscribe()
+	s.setConnected(true)
+
+	// the subscription is established first, so the gap fill overlaps it instead of leaving a hole
+	if s.cursorSet {
+		missed, head, err := BackfillLogs(ctx, client, s.query, LogPosition{BlockNumber: s.cursor}, BackfillBatchSize)
+		if err != nil {
+			return false, fmt.Errorf("failed to fill gap from block %d: %w", s.cursor, err)
+		}
+		s.logger.Info("Subscribed to logs", "gapFromBlock", s.cursor, "headBlock", head, "missedLogs", len(missed))
+		for _, l := range missed {
+			if err := s.deliver(ctx, out, l); err != nil {
+				return true, err
+			}
+		}
+	} else {
+		head, err := client.BlockNumber(ctx)
+		if err != nil {
+			return false, fmt.Errorf("failed to get head block: %w", err)
+		}
+		s.cursor = head
+		s.cursorSet = true
+		s.logger.Info("Subscribed to logs", "headBlock", head)
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return true, ctx.Err()
+		case err := <-sub.Err():
+			if err == nil {
+				err = fmt.Errorf("subscription closed")
+			}
+			return true, err
+		case l := <-logs:
+			if err := s.deliver(ctx, out, l); err != nil {
+				return true, err
+			}
+		}
+	}
+}
+
+func (s *LogSubscriber) deliver(ctx context.Context, out chan<- ethtypes.Log, l ethtypes.Log) error {
+	if l.Removed {
+		return nil
+	}
+	key := logKey{blockHash: l.BlockHash, index: l.Index}
+	if _, ok := s.seen[key]; ok {
+		return nil
+	}
+	select {
+	case out <- l:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	s.seen[key] = l.BlockNumber
+	if l.BlockNumber > s.cursor {
+		s.cursor = l.BlockNumber
+		for k, block := range s.seen {
+			if block+dedupeDepth < s.cursor {
+				delete(s.seen, k)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,128 @@
+package chainio_test
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/event"
+	sdklogging "github.com/imua-xyz/imua-avs-sdk/logging"
+	chain "github.com/imua-xyz/imua-avs/core/chainio"
+)
+
+// fakeChain serves a fixed log history. Each connection sees the next head block, replays live
+// the logs listed for it and then drops the subscription. The second dial fails.
+type fakeChain struct {
+	mu      sync.Mutex
+	head    uint64
+	heads   []uint64
+	history []ethtypes.Log
+	live    [][]ethtypes.Log
+	dials   int
+}
+
+type fakeConn struct {
+	chain *fakeChain
+	live  []ethtypes.Log
+}
+
+func (c *fakeChain) dial(ctx context.Context) (chain.LogSource, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.dials++
+	if c.dials == 2 {
+		return nil, errors.New("connection refused")
+	}
+	if len(c.heads) > 0 {
+		c.head, c.heads = c.heads[0], c.heads[1:]
+	}
+	var live []ethtypes.Log
+	if len(c.live) > 0 {
+		live, c.live = c.live[0], c.live[1:]
+	}
+	return &fakeConn{chain: c, live: live}, nil
+}
+
+func (f *fakeConn) BlockNumber(ctx context.Context) (uint64, error) {
+	f.chain.mu.Lock()
+	defer f.chain.mu.Unlock()
+	return f.chain.head, nil
+}
+
+func (f *fakeConn) FilterLogs(ctx context.Context, q ethereum.FilterQuery) ([]ethtypes.Log, error) {
+	f.chain.mu.Lock()
+	defer f.chain.mu.Unlock()
+	var logs []ethtypes.Log
+	for _, l := range f.chain.history {
+		if l.BlockNumber >= q.FromBlock.Uint64() && l.BlockNumber <= q.ToBlock.Uint64() {
+			logs = append(logs, l)
+		}
+	}
+	return logs, nil
+}
+
+func (f *fakeConn) SubscribeFilterLogs(ctx context.Context, q ethereum.FilterQuery, ch chan<- ethtypes.Log) (ethereum.Subscription, error) {
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		for _, l := range f.live {
+			select {
+			case ch <- l:
+			case <-quit:
+				return nil
+			}
+		}
+		return errors.New("connection reset")
+	}), nil
+}
+
+func (f *fakeConn) Close() {}
+
+func testLog(block uint64, index uint) ethtypes.Log {
+	return ethtypes.Log{
+		BlockNumber: block,
+		BlockHash:   common.BigToHash(new(big.Int).SetUint64(block)),
+		Index:       index,
+	}
+}
+
+func TestLogSubscriberFillsGapsWithoutDuplicates(t *testing.T) {
+	history := []ethtypes.Log{testLog(10, 0), testLog(11, 0), testLog(11, 1), testLog(12, 0)}
+	fake := &fakeChain{
+		heads:   []uint64{9, 12},
+		history: history,
+		// the first connection delivers part of block 11 before it drops, the third one
+		// delivers block 12 again after the gap fill already did
+		live: [][]ethtypes.Log{{history[0], history[1]}, {history[3]}},
+	}
+	logger, err := sdklogging.NewZapLogger(sdklogging.Development)
+	if err != nil {
+		t.Fatalf("Error creating logger: %v", err)
+	}
+	s := chain.NewLogSubscriberWithDialer(fake.dial, ethereum.FilterQuery{}, logger)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	out := make(chan ethtypes.Log)
+	go s.Run(ctx, out)
+
+	for _, want := range history {
+		select {
+		case got := <-out:
+			if chain.PositionOf(got) != chain.PositionOf(want) {
+				t.Fatalf("Expected log %+v, but got %+v", chain.PositionOf(want), chain.PositionOf(got))
+			}
+		case <-ctx.Done():
+			t.Fatalf("Timed out waiting for log %+v", chain.PositionOf(want))
+		}
+	}
+	select {
+	case got := <-out:
+		t.Fatalf("Unexpected duplicate log %+v", chain.PositionOf(got))
+	case <-time.After(500 * time.Millisecond):
+	}
+}
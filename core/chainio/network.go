@@ -0,0 +1,34 @@
+package chainio
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	gethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/imua-xyz/imua-avs/core/network"
+)
+
+// ContractAVSManagerPrecompile is the AVS manager precompile the AVS contract in
+// contracts/bindings/avs is compiled against, AVSMANAGER_PRECOMPILE_ADDRESS in IAVSManager.sol.
+var ContractAVSManagerPrecompile = gethcommon.HexToAddress("0x0000000000000000000000000000000000000901")
+
+// ChainIDer reports the chain id of a node.
+type ChainIDer interface {
+	ChainID(ctx context.Context) (*big.Int, error)
+}
+
+// VerifyNetwork checks that the node behind client runs the network of profile, and that
+// the AVS contract bindings call the AVS manager precompile of that network.
+func VerifyNetwork(ctx context.Context, profile network.Profile, client ChainIDer) error {
+	if profile.AVSManagerPrecompile != ContractAVSManagerPrecompile {
+		return fmt.Errorf("network %s has its avs manager precompile at %s, but the avs contract is compiled against %s, "+
+			"update AVSMANAGER_PRECOMPILE_ADDRESS and regenerate the bindings",
+			profile.Name, profile.AVSManagerPrecompile, ContractAVSManagerPrecompile)
+	}
+	chainID, err := client.ChainID(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get chain id: %w", err)
+	}
+	return profile.CheckEVMChainID(chainID)
+}
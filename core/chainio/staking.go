@@ -0,0 +1,269 @@
+package chainio
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	gethcommon "github.com/ethereum/go-ethereum/common"
+	gethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/imua-xyz/imua-avs-sdk/client/txmgr"
+	"github.com/imua-xyz/imua-avs-sdk/logging"
+	"github.com/imua-xyz/imua-avs/core/chainio/eth"
+	"github.com/imua-xyz/imua-avs/core/network"
+)
+
+const (
+	depositABI  = `[{"inputs":[{"internalType":"uint32","name":"clientChainID","type":"uint32"},{"internalType":"bytes","name":"assetsAddress","type":"bytes"},{"internalType":"bytes","name":"stakerAddress","type":"bytes"},{"internalType":"uint256","name":"opAmount","type":"uint256"}],"name":"depositLST","outputs":[{"internalType":"bool","name":"success","type":"bool"},{"internalType":"uint256","name":"latestAssetState","type":"uint256"}],"stateMutability":"nonpayable","type":"function"}]`
+	delegateABI = `[{"inputs":[{"internalType":"uint32","name":"clientChainID","type":"uint32"},{"internalType":"bytes","name":"staker","type":"bytes"},{"internalType":"bytes","name":"operator","type":"bytes"}],"name":"associateOperatorWithStaker","outputs":[{"internalType":"bool","name":"success","type":"bool"}],"stateMutability":"nonpayable","type":"function"},{"inputs":[{"internalType":"uint32","name":"clientChainID","type":"uint32"},{"internalType":"uint64","name":"lzNonce","type":"uint64"},{"internalType":"bytes","name":"assetsAddress","type":"bytes"},{"internalType":"bytes","name":"stakerAddress","type":"bytes"},{"internalType":"bytes","name":"operatorAddr","type":"bytes"},{"internalType":"uint256","name":"opAmount","type":"uint256"}],"name":"delegate","outputs":[{"internalType":"bool","name":"success","type":"bool"}],"stateMutability":"nonpayable","type":"function"},{"inputs":[{"internalType":"uint32","name":"clientChainID","type":"uint32"},{"internalType":"bytes","name":"staker","type":"bytes"}],"name":"dissociateOperatorFromStaker","outputs":[{"internalType":"bool","name":"success","type":"bool"}],"stateMutability":"nonpayable","type":"function"},{"inputs":[{"internalType":"uint32","name":"clientChainID","type":"uint32"},{"internalType":"uint64","name":"lzNonce","type":"uint64"},{"internalType":"bytes","name":"assetsAddress","type":"bytes"},{"internalType":"bytes","name":"stakerAddress","type":"bytes"},{"internalType":"bytes","name":"operatorAddr","type":"bytes"},{"internalType":"uint256","name":"opAmount","type":"uint256"}],"name":"undelegate","outputs":[{"internalType":"bool","name":"success","type":"bool"}],"stateMutability":"nonpayable","type":"function"}]`
+)
+
+// StakingAsset identifies a staked asset by its address on its client chain and the
+// LayerZero id of that chain.
+type StakingAsset struct {
+	Address       gethcommon.Address
+	ClientChainID uint32
+}
+
+// ParseAssetID parses an imuachain asset id of the form "<asset address>_<client chain id>",
+// e.g. "0xdac17f958d2ee523a2206206994597c13d831ec7_0x65", as used by asset_ids in the config.
+func ParseAssetID(assetID string) (StakingAsset, error) {
+	addr, chainID, ok := strings.Cut(assetID, "_")
+	if !ok || !gethcommon.IsHexAddress(addr) {
+		return StakingAsset{}, fmt.Errorf("invalid asset id %q, expected <asset address>_<client chain id>", assetID)
+	}
+	id, err := strconv.ParseUint(chainID, 0, 32)
+	if err != nil {
+		return StakingAsset{}, fmt.Errorf("invalid client chain id in asset id %q: %w", assetID, err)
+	}
+	return StakingAsset{Address: gethcommon.HexToAddress(addr), ClientChainID: uint32(id)}, nil
+}
+
+// maxStakingAmount is the largest amount the uint256 parameters of the staking precompiles hold,
+// the ABI encoder would silently truncate a larger one.
+var maxStakingAmount = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 256), big.NewInt(1))
+
+// ParseStakingAmount parses an amount given as a decimal integer in the smallest unit of the asset.
+func ParseStakingAmount(amount string) (*big.Int, error) {
+	value, ok := new(big.Int).SetString(amount, 10)
+	if !ok {
+		return nil, fmt.Errorf("invalid amount %q, expected a decimal integer", amount)
+	}
+	if err := checkStakingAmount(value); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+// checkStakingAmount returns an error unless amount is positive and fits in a uint256.
+func checkStakingAmount(amount *big.Int) error {
+	if amount == nil || amount.Sign() <= 0 {
+		return fmt.Errorf("amount must be positive, got %v", amount)
+	}
+	if amount.Cmp(maxStakingAmount) > 0 {
+		return fmt.Errorf("amount %s does not fit in a uint256", amount)
+	}
+	return nil
+}
+
+// StakingClient deposits, delegates and associates stake through the imuachain staking
+// precompiles, sending from the funding account of its txmgr.
+type StakingClient struct {
+	deposit    *bind.BoundContract
+	delegation *bind.BoundContract
+	network    network.Profile
+	asset      StakingAsset
+	logger     logging.Logger
+	txMgr      txmgr.TxManager
+}
+
+// NewStakingClient returns a StakingClient staking asset through backend, with the
+// staking precompiles of profile.
+func NewStakingClient(
+	backend bind.ContractBackend,
+	profile network.Profile,
+	asset StakingAsset,
+	logger logging.Logger,
+	txMgr txmgr.TxManager,
+) (*StakingClient, error) {
+	depositAbi, err := abi.JSON(strings.NewReader(depositABI))
+	if err != nil {
+		return nil, err
+	}
+	delegateAbi, err := abi.JSON(strings.NewReader(delegateABI))
+	if err != nil {
+		return nil, err
+	}
+	return &StakingClient{
+		deposit:    bind.NewBoundContract(profile.DepositPrecompile, depositAbi, backend, backend, backend),
+		delegation: bind.NewBoundContract(profile.DelegationPrecompile, delegateAbi, backend, backend, backend),
+		network:    profile,
+		asset:      asset,
+		logger:     logger,
+		txMgr:      txMgr,
+	}, nil
+}
+
+// Deposit deposits amount of the asset for staker.
+func (s *StakingClient) Deposit(
+	ctx context.Context,
+	staker gethcommon.Address,
+	amount *big.Int,
+) (*gethtypes.Receipt, error) {
+	if err := checkStake(staker, amount); err != nil {
+		return nil, fmt.Errorf("invalid deposit: %w", err)
+	}
+	return s.send(ctx, s.deposit, "depositLST",
+		s.asset.ClientChainID, padTo32(s.asset.Address), padTo32(staker), amount)
+}
+
+// Delegate delegates amount of the asset of staker to the operator with the im address operator.
+func (s *StakingClient) Delegate(
+	ctx context.Context,
+	staker gethcommon.Address,
+	operator string,
+	amount *big.Int,
+) (*gethtypes.Receipt, error) {
+	if err := checkStake(staker, amount); err != nil {
+		return nil, fmt.Errorf("invalid delegation: %w", err)
+	}
+	if err := s.checkOperator(operator); err != nil {
+		return nil, fmt.Errorf("invalid delegation: %w", err)
+	}
+	return s.send(ctx, s.delegation, "delegate",
+		s.asset.ClientChainID, uint64(0), padTo32(s.asset.Address), padTo32(staker), []byte(operator), amount)
+}
+
+// Undelegate undelegates amount of the asset of staker from the operator with the im address operator.
+func (s *StakingClient) Undelegate(
+	ctx context.Context,
+	staker gethcommon.Address,
+	operator string,
+	amount *big.Int,
+) (*gethtypes.Receipt, error) {
+	if err := checkStake(staker, amount); err != nil {
+		return nil, fmt.Errorf("invalid undelegation: %w", err)
+	}
+	if err := s.checkOperator(operator); err != nil {
+		return nil, fmt.Errorf("invalid undelegation: %w", err)
+	}
+	return s.send(ctx, s.delegation, "undelegate",
+		s.asset.ClientChainID, uint64(0), padTo32(s.asset.Address), padTo32(staker), []byte(operator), amount)
+}
+
+// Associate associates staker with the operator with the im address operator,
+// so that the stake of staker counts as the self delegation of the operator.
+func (s *StakingClient) Associate(
+	ctx context.Context,
+	staker gethcommon.Address,
+	operator string,
+) (*gethtypes.Receipt, error) {
+	return s.send(ctx, s.delegation, "associateOperatorWithStaker",
+		s.asset.ClientChainID, staker.Bytes(), []byte(operator))
+}
+
+// Dissociate removes the association of staker with its operator.
+func (s *StakingClient) Dissociate(
+	ctx context.Context,
+	staker gethcommon.Address,
+) (*gethtypes.Receipt, error) {
+	return s.send(ctx, s.delegation, "dissociateOperatorFromStaker",
+		s.asset.ClientChainID, staker.Bytes())
+}
+
+// checkStake returns an error if staker is the zero address or amount is not a positive uint256,
+// which the precompiles would only reject once the gas is spent, if at all.
+func checkStake(staker gethcommon.Address, amount *big.Int) error {
+	if staker == (gethcommon.Address{}) {
+		return errors.New("staker must not be the zero address")
+	}
+	return checkStakingAmount(amount)
+}
+
+// checkOperator returns an error unless operator is a non-zero bech32 address of the network.
+func (s *StakingClient) checkOperator(operator string) error {
+	if gethcommon.IsHexAddress(operator) {
+		return fmt.Errorf("operator %q must be a bech32 address", operator)
+	}
+	addr, err := s.network.ParseAddress(operator)
+	if err != nil {
+		return fmt.Errorf("invalid operator: %w", err)
+	}
+	if addr == (gethcommon.Address{}) {
+		return errors.New("operator must not be the zero address")
+	}
+	return nil
+}
+
+func (s *StakingClient) send(
+	ctx context.Context,
+	contract *bind.BoundContract,
+	method string,
+	params ...interface{},
+) (*gethtypes.Receipt, error) {
+	noSendTxOpts, err := s.txMgr.GetNoSendTxOpts()
+	if err != nil {
+		return nil, err
+	}
+	noSendTxOpts.Context = ctx
+	tx, err := contract.Transact(noSendTxOpts, method, params...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build %s tx: %w", method, err)
+	}
+	receipt, err := s.txMgr.Send(ctx, tx)
+	if err != nil {
+		return nil, errors.New("failed to send tx with err: " + err.Error())
+	}
+	if receipt.Status != gethtypes.ReceiptStatusSuccessful {
+		return receipt, fmt.Errorf("%s tx %s reverted", method, receipt.TxHash.String())
+	}
+	s.logger.Infof("%s tx hash: %s", method, receipt.TxHash.String())
+	return receipt, nil
+}
+
+// padTo32 right pads an address to the 32 bytes the staking precompiles expect.
+func padTo32(address gethcommon.Address) []byte {
+	ret := make([]byte, 32)
+	copy(ret, address[:])
+	return ret
+}
+
+// StakingConfig configures the funding account and the asset of a StakingClient.
+type StakingConfig struct {
+	Network network.Profile
+	Signer  FundingSignerConfig
+	// AssetID is the staked asset in the "<asset address>_<client chain id>" form of ParseAssetID.
+	AssetID string
+}
+
+// BuildStakingClient returns a StakingClient sending from the funding account of config
+// through its own TxQueue, which estimates gas and prices transactions with EIP-1559 fees.
+func BuildStakingClient(
+	ctx context.Context,
+	config StakingConfig,
+	ethClient eth.EthClient,
+	logger logging.Logger,
+) (*StakingClient, error) {
+	asset, err := ParseAssetID(config.AssetID)
+	if err != nil {
+		return nil, err
+	}
+	chainID, err := ethClient.ChainID(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get chain id: %w", err)
+	}
+	if err := config.Network.CheckEVMChainID(chainID); err != nil {
+		return nil, err
+	}
+	signerFn, funder, err := NewFundingSigner(ctx, config.Signer, chainID)
+	if err != nil {
+		return nil, err
+	}
+	logger.Info("Staking from funding account", "funder", funder.String(), "asset", asset.Address.String(), "clientChainID", asset.ClientChainID)
+	return NewStakingClient(ethClient, config.Network, asset, logger, NewTxQueue(ethClient, logger, signerFn, funder))
+}
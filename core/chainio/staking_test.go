@@ -0,0 +1,148 @@
+package chainio_test
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	gethcommon "github.com/ethereum/go-ethereum/common"
+	gethtypes "github.com/ethereum/go-ethereum/core/types"
+	sdklogging "github.com/imua-xyz/imua-avs-sdk/logging"
+	chain "github.com/imua-xyz/imua-avs/core/chainio"
+	"github.com/imua-xyz/imua-avs/core/network"
+)
+
+func TestParseAssetID(t *testing.T) {
+	asset, err := chain.ParseAssetID("0xdac17f958d2ee523a2206206994597c13d831ec7_0x65")
+	if err != nil {
+		t.Fatalf("Error parsing asset id: %v", err)
+	}
+	if asset.Address != gethcommon.HexToAddress("0xdAC17F958D2ee523a2206206994597C13D831ec7") || asset.ClientChainID != 101 {
+		t.Fatalf("Expected usdt on client chain 101, but got %s on %d", asset.Address, asset.ClientChainID)
+	}
+
+	for _, id := range []string{"", "0xdac17f958d2ee523a2206206994597c13d831ec7", "usdt_0x65", "0xdac17f958d2ee523a2206206994597c13d831ec7_x"} {
+		if _, err := chain.ParseAssetID(id); err == nil {
+			t.Fatalf("Expected an error for asset id %q", id)
+		}
+	}
+}
+
+// maxUint256 is the largest amount the staking precompiles take.
+const maxUint256 = "115792089237316195423570985008687907853269984665640564039457584007913129639935"
+
+func TestParseStakingAmount(t *testing.T) {
+	cases := []struct {
+		amount  string
+		want    string
+		wantErr string
+	}{
+		{amount: "1", want: "1"},
+		{amount: "1000000000000000000", want: "1000000000000000000"},
+		{amount: maxUint256, want: maxUint256},
+		{amount: "", wantErr: "expected a decimal integer"},
+		{amount: "0", wantErr: "must be positive"},
+		{amount: "-5", wantErr: "must be positive"},
+		{amount: "1.5", wantErr: "expected a decimal integer"},
+		{amount: "1e18", wantErr: "expected a decimal integer"},
+		{amount: "0x10", wantErr: "expected a decimal integer"},
+		// one more than the largest uint256 would be encoded as 0
+		{amount: "115792089237316195423570985008687907853269984665640564039457584007913129639936", wantErr: "does not fit in a uint256"},
+	}
+	for _, c := range cases {
+		got, err := chain.ParseStakingAmount(c.amount)
+		if c.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), c.wantErr) {
+				t.Errorf("Amount %q: expected an error containing %q, but got %v (%v)", c.amount, c.wantErr, err, got)
+			}
+			continue
+		}
+		if err != nil || got.String() != c.want {
+			t.Errorf("Amount %q: expected %s, but got %v (%v)", c.amount, c.want, got, err)
+		}
+	}
+}
+
+// errTxBuilt is returned by stakingTxMgr once the arguments of a staking call were accepted.
+var errTxBuilt = errors.New("tx built")
+
+type stakingTxMgr struct{}
+
+func (stakingTxMgr) GetNoSendTxOpts() (*bind.TransactOpts, error) {
+	return nil, errTxBuilt
+}
+
+func (stakingTxMgr) Send(ctx context.Context, tx *gethtypes.Transaction) (*gethtypes.Receipt, error) {
+	return nil, errTxBuilt
+}
+
+func TestStakingClientChecksArguments(t *testing.T) {
+	logger, err := sdklogging.NewZapLogger(sdklogging.Development)
+	if err != nil {
+		t.Fatalf("Error creating logger: %v", err)
+	}
+	asset, err := chain.ParseAssetID("0xdac17f958d2ee523a2206206994597c13d831ec7_0x65")
+	if err != nil {
+		t.Fatalf("Error parsing asset id: %v", err)
+	}
+	client, err := chain.NewStakingClient(nil, network.Localnet, asset, logger, stakingTxMgr{})
+	if err != nil {
+		t.Fatalf("Error creating staking client: %v", err)
+	}
+
+	staker := gethcommon.HexToAddress("0xa53f68563D22EB0dAFAA871b6C08a6852f91d627")
+	operator := "im18cggcpvwspnd5c6ny8wrqxpffj5zmhkl3agtrj"
+	overflow, _ := new(big.Int).SetString("115792089237316195423570985008687907853269984665640564039457584007913129639936", 10)
+	cases := []struct {
+		name     string
+		staker   gethcommon.Address
+		operator string
+		amount   *big.Int
+		wantErr  string
+	}{
+		{"valid", staker, operator, big.NewInt(100), ""},
+		{"zero staker", gethcommon.Address{}, operator, big.NewInt(100), "staker must not be the zero address"},
+		{"zero amount", staker, operator, big.NewInt(0), "amount must be positive"},
+		{"negative amount", staker, operator, big.NewInt(-100), "amount must be positive"},
+		{"no amount", staker, operator, nil, "amount must be positive"},
+		{"amount overflows uint256", staker, operator, overflow, "does not fit in a uint256"},
+		{"hex operator", staker, "0x3e108c058e8066DA635321Dc3018294cA82ddEdf", big.NewInt(100), "must be a bech32 address"},
+		{"operator of another network", staker, "exo18cggcpvwspnd5c6ny8wrqxpffj5zmhkl3agtrj", big.NewInt(100), "invalid operator"},
+		{"no operator", staker, "", big.NewInt(100), "invalid operator"},
+	}
+	ctx := context.Background()
+	for _, c := range cases {
+		calls := map[string]func() error{
+			"delegate": func() error {
+				_, err := client.Delegate(ctx, c.staker, c.operator, c.amount)
+				return err
+			},
+			"undelegate": func() error {
+				_, err := client.Undelegate(ctx, c.staker, c.operator, c.amount)
+				return err
+			},
+		}
+		// deposits have no operator
+		if !strings.Contains(c.name, "operator") {
+			calls["deposit"] = func() error {
+				_, err := client.Deposit(ctx, c.staker, c.amount)
+				return err
+			}
+		}
+		for call, send := range calls {
+			err := send()
+			if c.wantErr == "" {
+				if !errors.Is(err, errTxBuilt) {
+					t.Errorf("%s %s: expected the arguments to be accepted, but got %v", c.name, call, err)
+				}
+				continue
+			}
+			if err == nil || errors.Is(err, errTxBuilt) || !strings.Contains(err.Error(), c.wantErr) {
+				t.Errorf("%s %s: expected an error containing %q, but got %v", c.name, call, c.wantErr, err)
+			}
+		}
+	}
+}
@@ -0,0 +1,357 @@
+package chainio
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	gethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/imua-xyz/imua-avs-sdk/client/txmgr"
+	"github.com/imua-xyz/imua-avs-sdk/logging"
+	"github.com/imua-xyz/imua-avs-sdk/signer"
+)
+
+const (
+	// maxPendingTxs is how many sent transactions may wait to be mined before the queue stops sending.
+	maxPendingTxs = 16
+	// receiptPollInterval is how often a pending transaction is checked for its receipt.
+	receiptPollInterval = 2 * time.Second
+	// defaultMineTimeout is how long a sent transaction may stay unmined before it is rebroadcast
+	// with higher fees, or given up after the last fee bump.
+	defaultMineTimeout = 2 * time.Minute
+	// maxFeeBumps is how often an unmined transaction is rebroadcast before it is given up.
+	maxFeeBumps = 3
+	// feeBumpPercent raises the fees of a rebroadcast transaction, above the 10% nodes require to
+	// replace a pending transaction.
+	feeBumpPercent = 12
+)
+
+// TxBackend is the part of an eth client the transaction queue needs.
+type TxBackend interface {
+	ChainID(ctx context.Context) (*big.Int, error)
+	PendingNonceAt(ctx context.Context, account common.Address) (uint64, error)
+	SuggestGasTipCap(ctx context.Context) (*big.Int, error)
+	HeaderByNumber(ctx context.Context, number *big.Int) (*gethtypes.Header, error)
+	EstimateGas(ctx context.Context, call ethereum.CallMsg) (uint64, error)
+	SendTransaction(ctx context.Context, tx *gethtypes.Transaction) error
+	TransactionReceipt(ctx context.Context, txHash common.Hash) (*gethtypes.Receipt, error)
+}
+
+type txResult struct {
+	receipt *gethtypes.Receipt
+	err     error
+}
+
+type txRequest struct {
+	ctx    context.Context
+	tx     *gethtypes.Transaction
+	result chan txResult
+}
+
+// TxQueue is a txmgr.TxManager that lets concurrent callers share one sender account.
+// Transactions are sent one at a time, in the order they are enqueued, with nonces assigned
+// locally, so parallel submissions never race for the same nonce. Sending does not wait for
+// the previous transaction to be mined: up to maxPendingTxs transactions are in flight at once.
+// When the node rejects a transaction its nonce is not used, so the local nonce is resynced
+// from PendingNonceAt, and a nonce error is retried once with the fresh nonce. A transaction
+// that is not mined within the mine timeout, because it was dropped or is underpriced, is
+// rebroadcast with bumped fees, and given up after maxFeeBumps; the nonce is then resynced
+// before the next send so the transactions behind it are not stuck on a nonce gap.
+// Send returns the receipt of the caller's own transaction.
+type TxQueue struct {
+	backend  TxBackend
+	logger   logging.Logger
+	signerFn signer.SignerFn
+	sender   common.Address
+
+	requests    chan *txRequest
+	pending     chan struct{}
+	mineTimeout time.Duration
+	// resync is set when a transaction was given up, its nonce may be unused
+	resync atomic.Bool
+
+	// owned by the worker goroutine
+	chainID *big.Int
+	nonce   uint64
+	synced  bool
+}
+
+var _ txmgr.TxManager = (*TxQueue)(nil)
+
+// NewTxQueue returns a TxQueue sending from sender, and starts its worker.
+func NewTxQueue(
+	backend TxBackend,
+	logger logging.Logger,
+	signerFn signer.SignerFn,
+	sender common.Address,
+) *TxQueue {
+	q := &TxQueue{
+		backend:     backend,
+		logger:      logger,
+		signerFn:    signerFn,
+		sender:      sender,
+		requests:    make(chan *txRequest),
+		pending:     make(chan struct{}, maxPendingTxs),
+		mineTimeout: defaultMineTimeout,
+	}
+	go q.run()
+	return q
+}
+
+// Send enqueues a transaction built with GetNoSendTxOpts and waits for its receipt.
+// Only the destination, value and data of tx are kept, gas and nonce are set by the queue.
+func (q *TxQueue) Send(ctx context.Context, tx *gethtypes.Transaction) (*gethtypes.Receipt, error) {
+	req := &txRequest{ctx: ctx, tx: tx, result: make(chan txResult, 1)}
+	select {
+	case q.requests <- req:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	res := <-req.result
+	return res.receipt, res.err
+}
+
+// GetNoSendTxOpts returns TransactOpts that build a transaction without sending it.
+func (q *TxQueue) GetNoSendTxOpts() (*bind.TransactOpts, error) {
+	signer, err := q.signerFn(context.Background(), q.sender)
+	if err != nil {
+		return nil, err
+	}
+	return &bind.TransactOpts{
+		From:   q.sender,
+		Signer: signer,
+		NoSend: true,
+	}, nil
+}
+
+func (q *TxQueue) run() {
+	for req := range q.requests {
+		q.process(req)
+	}
+}
+
+func (q *TxQueue) process(req *txRequest) {
+	if err := req.ctx.Err(); err != nil {
+		req.result <- txResult{err: err}
+		return
+	}
+	// wait for a free slot before sending, so a node that stops mining does not get flooded
+	select {
+	case q.pending <- struct{}{}:
+	case <-req.ctx.Done():
+		req.result <- txResult{err: req.ctx.Err()}
+		return
+	}
+
+	for attempt := 1; ; attempt++ {
+		signed, err := q.prepare(req.ctx, req.tx)
+		if err != nil {
+			<-q.pending
+			req.result <- txResult{err: err}
+			return
+		}
+		err = q.backend.SendTransaction(req.ctx, signed)
+		if err == nil || isAlreadyKnown(err) {
+			q.nonce++
+			q.logger.Debug("Sent transaction", "hash", signed.Hash().String(), "nonce", signed.Nonce())
+			go q.waitMined(req, signed)
+			return
+		}
+		// a rejected transaction does not use its nonce, and the local nonce may be stale
+		q.synced = false
+		if !isNonceError(err) || attempt > 1 {
+			<-q.pending
+			req.result <- txResult{err: fmt.Errorf("send: failed to send txn: %w", err)}
+			return
+		}
+		q.logger.Warn("Transaction rejected for its nonce, resyncing", "nonce", signed.Nonce(), "err", err)
+	}
+}
+
+// prepare assigns the next nonce and EIP-1559 gas parameters to tx and signs it.
+func (q *TxQueue) prepare(ctx context.Context, tx *gethtypes.Transaction) (*gethtypes.Transaction, error) {
+	if q.chainID == nil {
+		chainID, err := q.backend.ChainID(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get chain id: %w", err)
+		}
+		q.chainID = chainID
+	}
+	if q.resync.Swap(false) {
+		q.synced = false
+	}
+	if !q.synced {
+		nonce, err := q.backend.PendingNonceAt(ctx, q.sender)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get pending nonce: %w", err)
+		}
+		q.nonce = nonce
+		q.synced = true
+	}
+
+	gasTipCap, err := q.backend.SuggestGasTipCap(ctx)
+	if err != nil {
+		q.logger.Info("eth_maxPriorityFeePerGas is unsupported by current backend, using fallback gasTipCap")
+		gasTipCap = txmgr.FallbackGasTipCap
+	}
+	header, err := q.backend.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	gasFeeCap := new(big.Int).Add(header.BaseFee, gasTipCap)
+	gasLimit, err := q.backend.EstimateGas(ctx, ethereum.CallMsg{
+		From:      q.sender,
+		To:        tx.To(),
+		GasTipCap: gasTipCap,
+		GasFeeCap: gasFeeCap,
+		Value:     tx.Value(),
+		Data:      tx.Data(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	signerFn, err := q.signerFn(ctx, q.sender)
+	if err != nil {
+		return nil, err
+	}
+	return signerFn(q.sender, gethtypes.NewTx(&gethtypes.DynamicFeeTx{
+		ChainID:   q.chainID,
+		Nonce:     q.nonce,
+		GasTipCap: gasTipCap,
+		GasFeeCap: gasFeeCap,
+		Gas:       gasLimit,
+		To:        tx.To(),
+		Value:     tx.Value(),
+		Data:      tx.Data(),
+	}))
+}
+
+// waitMined polls for the receipt of a sent transaction and hands it to the caller. Each time
+// the mine timeout passes without a receipt the transaction is rebroadcast with bumped fees;
+// every version sent stays a candidate, as any of them may be mined. After maxFeeBumps the
+// transaction is given up as dropped and the nonce is resynced before the next send.
+func (q *TxQueue) waitMined(req *txRequest, tx *gethtypes.Transaction) {
+	defer func() { <-q.pending }()
+	ticker := time.NewTicker(receiptPollInterval)
+	defer ticker.Stop()
+	timeout := time.NewTimer(q.mineTimeout)
+	defer timeout.Stop()
+	sent := []*gethtypes.Transaction{tx}
+	for bumps := 0; ; {
+		select {
+		case <-req.ctx.Done():
+			req.result <- txResult{err: fmt.Errorf("tx %s sent but not mined: %w", tx.Hash().Hex(), req.ctx.Err())}
+			return
+		case <-timeout.C:
+			if receipt := q.findReceipt(req.ctx, sent); receipt != nil {
+				req.result <- txResult{receipt: receipt}
+				return
+			}
+			latest := sent[len(sent)-1]
+			if bumps == maxFeeBumps {
+				q.logger.Error("Transaction not mined, giving it up", "hash", latest.Hash(), "nonce", latest.Nonce(), "feeBumps", bumps)
+				q.resync.Store(true)
+				req.result <- txResult{err: fmt.Errorf("tx %s not mined after %d fee bumps, it was dropped", latest.Hash().Hex(), bumps)}
+				return
+			}
+			bumps++
+			bumped, err := q.rebroadcast(req.ctx, latest)
+			if err != nil {
+				// a nonce error means one of the versions sent, or another transaction, used the nonce
+				q.logger.Warn("Failed to rebroadcast unmined transaction", "hash", latest.Hash(), "nonce", latest.Nonce(), "err", err)
+			} else {
+				q.logger.Warn("Transaction not mined, rebroadcast with bumped fees", "hash", latest.Hash(), "replacement", bumped.Hash(),
+					"nonce", bumped.Nonce(), "gasTipCap", bumped.GasTipCap(), "gasFeeCap", bumped.GasFeeCap())
+				sent = append(sent, bumped)
+			}
+			timeout.Reset(q.mineTimeout)
+		case <-ticker.C:
+			if receipt := q.findReceipt(req.ctx, sent); receipt != nil {
+				req.result <- txResult{receipt: receipt}
+				return
+			}
+		}
+	}
+}
+
+// findReceipt returns the receipt of the first of the versions sent of a transaction that is mined.
+func (q *TxQueue) findReceipt(ctx context.Context, sent []*gethtypes.Transaction) *gethtypes.Receipt {
+	for _, tx := range sent {
+		receipt, err := q.backend.TransactionReceipt(ctx, tx.Hash())
+		if err != nil && !errors.Is(err, ethereum.NotFound) {
+			q.logger.Info("Receipt retrieval failed", "hash", tx.Hash(), "err", err)
+		}
+		if err == nil && receipt != nil {
+			return receipt
+		}
+	}
+	return nil
+}
+
+// rebroadcast signs tx again with the same nonce and its tip and fee cap raised by
+// feeBumpPercent, the fee cap at least the current base fee plus the tip, and sends it to
+// replace tx, or to put it back in the pool if it was dropped.
+func (q *TxQueue) rebroadcast(ctx context.Context, tx *gethtypes.Transaction) (*gethtypes.Transaction, error) {
+	gasTipCap := bumpFee(tx.GasTipCap())
+	gasFeeCap := bumpFee(tx.GasFeeCap())
+	header, err := q.backend.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	if minFeeCap := new(big.Int).Add(header.BaseFee, gasTipCap); gasFeeCap.Cmp(minFeeCap) < 0 {
+		gasFeeCap = minFeeCap
+	}
+	signerFn, err := q.signerFn(ctx, q.sender)
+	if err != nil {
+		return nil, err
+	}
+	signed, err := signerFn(q.sender, gethtypes.NewTx(&gethtypes.DynamicFeeTx{
+		ChainID:   tx.ChainId(),
+		Nonce:     tx.Nonce(),
+		GasTipCap: gasTipCap,
+		GasFeeCap: gasFeeCap,
+		Gas:       tx.Gas(),
+		To:        tx.To(),
+		Value:     tx.Value(),
+		Data:      tx.Data(),
+	}))
+	if err != nil {
+		return nil, err
+	}
+	if err := q.backend.SendTransaction(ctx, signed); err != nil && !isAlreadyKnown(err) {
+		return nil, err
+	}
+	return signed, nil
+}
+
+// bumpFee returns fee raised by feeBumpPercent, rounded up and by at least one wei.
+func bumpFee(fee *big.Int) *big.Int {
+	bumped := new(big.Int).Mul(fee, big.NewInt(100+feeBumpPercent))
+	bumped.Add(bumped, big.NewInt(99))
+	bumped.Div(bumped, big.NewInt(100))
+	if bumped.Cmp(fee) <= 0 {
+		bumped.Add(fee, big.NewInt(1))
+	}
+	return bumped
+}
+
+// isNonceError reports whether the node rejected a transaction because of its nonce,
+// as geth ("nonce too low") and the cosmos evm ("invalid nonce", "invalid sequence") do.
+func isNonceError(err error) bool {
+	msg := strings.ToLower(err.Error())
+	return strings.Contains(msg, "nonce") || strings.Contains(msg, "sequence")
+}
+
+// isAlreadyKnown reports whether the node already has the transaction in its pool.
+func isAlreadyKnown(err error) bool {
+	msg := strings.ToLower(err.Error())
+	return strings.Contains(msg, "already known") || strings.Contains(msg, "tx already in mempool")
+}
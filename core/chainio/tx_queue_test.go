@@ -0,0 +1,225 @@
+package chainio_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	gethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	sdklogging "github.com/imua-xyz/imua-avs-sdk/logging"
+	"github.com/imua-xyz/imua-avs-sdk/signer"
+	chain "github.com/imua-xyz/imua-avs/core/chainio"
+)
+
+// fakeTxBackend accepts a transaction only with the next account nonce and mines it at once.
+type fakeTxBackend struct {
+	mu       sync.Mutex
+	nonce    uint64
+	mined    map[common.Hash]*gethtypes.Receipt
+	nonces   []uint64
+	rejected int
+}
+
+func (b *fakeTxBackend) ChainID(ctx context.Context) (*big.Int, error) { return big.NewInt(232), nil }
+
+func (b *fakeTxBackend) PendingNonceAt(ctx context.Context, account common.Address) (uint64, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.nonce, nil
+}
+
+func (b *fakeTxBackend) SuggestGasTipCap(ctx context.Context) (*big.Int, error) {
+	return big.NewInt(1), nil
+}
+
+func (b *fakeTxBackend) HeaderByNumber(ctx context.Context, number *big.Int) (*gethtypes.Header, error) {
+	return &gethtypes.Header{BaseFee: big.NewInt(1)}, nil
+}
+
+func (b *fakeTxBackend) EstimateGas(ctx context.Context, call ethereum.CallMsg) (uint64, error) {
+	return 21000, nil
+}
+
+func (b *fakeTxBackend) SendTransaction(ctx context.Context, tx *gethtypes.Transaction) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if tx.Nonce() != b.nonce {
+		b.rejected++
+		return fmt.Errorf("invalid nonce; got %d, expected %d: invalid sequence", tx.Nonce(), b.nonce)
+	}
+	b.nonce++
+	b.nonces = append(b.nonces, tx.Nonce())
+	b.mined[tx.Hash()] = &gethtypes.Receipt{TxHash: tx.Hash(), Status: 1}
+	return nil
+}
+
+func (b *fakeTxBackend) TransactionReceipt(ctx context.Context, txHash common.Hash) (*gethtypes.Receipt, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if r, ok := b.mined[txHash]; ok {
+		return r, nil
+	}
+	return nil, ethereum.NotFound
+}
+
+// bumpNonce simulates a transaction sent from the same account by another process.
+func (b *fakeTxBackend) bumpNonce() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.nonce++
+}
+
+func newTestTxQueue(t *testing.T, backend chain.TxBackend) *chain.TxQueue {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatalf("Error generating key: %v", err)
+	}
+	sender := crypto.PubkeyToAddress(key.PublicKey)
+	signerFn := func(ctx context.Context, address common.Address) (bind.SignerFn, error) {
+		return signer.PrivateKeySignerFn(key, big.NewInt(232))
+	}
+	logger, err := sdklogging.NewZapLogger(sdklogging.Development)
+	if err != nil {
+		t.Fatalf("Error creating logger: %v", err)
+	}
+	return chain.NewTxQueue(backend, logger, signerFn, sender)
+}
+
+func unsignedTx(i int) *gethtypes.Transaction {
+	to := common.BigToAddress(big.NewInt(int64(i + 1)))
+	return gethtypes.NewTx(&gethtypes.DynamicFeeTx{To: &to, Data: []byte{byte(i)}})
+}
+
+func TestTxQueueAssignsNoncesToConcurrentSenders(t *testing.T) {
+	backend := &fakeTxBackend{nonce: 5, mined: map[common.Hash]*gethtypes.Receipt{}}
+	q := newTestTxQueue(t, backend)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	const n = 8
+	var wg sync.WaitGroup
+	errs := make(chan error, n)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			receipt, err := q.Send(ctx, unsignedTx(i))
+			if err == nil && (receipt == nil || receipt.Status != 1) {
+				err = errors.New("missing receipt")
+			}
+			errs <- err
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("Error sending tx: %v", err)
+		}
+	}
+	if backend.rejected != 0 {
+		t.Fatalf("Expected no rejected transactions, but got %d", backend.rejected)
+	}
+	for i, nonce := range backend.nonces {
+		if nonce != uint64(5+i) {
+			t.Fatalf("Expected nonces 5..%d in order, but got %v", 5+n-1, backend.nonces)
+		}
+	}
+}
+
+func TestTxQueueResyncsNonceAfterRejection(t *testing.T) {
+	backend := &fakeTxBackend{nonce: 0, mined: map[common.Hash]*gethtypes.Receipt{}}
+	q := newTestTxQueue(t, backend)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	if _, err := q.Send(ctx, unsignedTx(0)); err != nil {
+		t.Fatalf("Error sending tx: %v", err)
+	}
+	backend.bumpNonce()
+	if _, err := q.Send(ctx, unsignedTx(1)); err != nil {
+		t.Fatalf("Expected the queue to resync its nonce, but got %v", err)
+	}
+	if backend.rejected != 1 {
+		t.Fatalf("Expected one rejected transaction, but got %d", backend.rejected)
+	}
+	if got := backend.nonces; len(got) != 2 || got[1] != 2 {
+		t.Fatalf("Expected the second tx to use nonce 2, but got %v", got)
+	}
+}
+
+// stallingTxBackend accepts every transaction into its pool but only mines the ones with a tip
+// of at least minTip, like a node with underpriced or dropped transactions.
+type stallingTxBackend struct {
+	fakeTxBackend
+	minTip *big.Int
+	sent   []*gethtypes.Transaction
+}
+
+func (b *stallingTxBackend) SendTransaction(ctx context.Context, tx *gethtypes.Transaction) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.sent = append(b.sent, tx)
+	if tx.Nonce() != b.nonce {
+		b.rejected++
+		return fmt.Errorf("invalid nonce; got %d, expected %d: invalid sequence", tx.Nonce(), b.nonce)
+	}
+	if tx.GasTipCap().Cmp(b.minTip) < 0 {
+		return nil
+	}
+	b.nonce++
+	b.nonces = append(b.nonces, tx.Nonce())
+	b.mined[tx.Hash()] = &gethtypes.Receipt{TxHash: tx.Hash(), Status: 1}
+	return nil
+}
+
+func TestTxQueueBumpsFeesOfUnminedTx(t *testing.T) {
+	backend := &stallingTxBackend{fakeTxBackend: fakeTxBackend{mined: map[common.Hash]*gethtypes.Receipt{}}, minTip: big.NewInt(2)}
+	q := newTestTxQueue(t, backend)
+	q.SetMineTimeout(50 * time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	receipt, err := q.Send(ctx, unsignedTx(0))
+	if err != nil {
+		t.Fatalf("Expected the bumped tx to be mined, but got %v", err)
+	}
+	if len(backend.sent) != 2 || backend.sent[1].Nonce() != backend.sent[0].Nonce() ||
+		backend.sent[1].GasTipCap().Cmp(big.NewInt(2)) != 0 || receipt.TxHash != backend.sent[1].Hash() {
+		t.Fatalf("Expected one replacement with the same nonce and a bumped tip, but sent %d txs", len(backend.sent))
+	}
+}
+
+func TestTxQueueResyncsNonceAfterDrop(t *testing.T) {
+	backend := &stallingTxBackend{fakeTxBackend: fakeTxBackend{mined: map[common.Hash]*gethtypes.Receipt{}}, minTip: big.NewInt(100)}
+	q := newTestTxQueue(t, backend)
+	q.SetMineTimeout(50 * time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	if _, err := q.Send(ctx, unsignedTx(0)); err == nil {
+		t.Fatalf("Expected a tx that is never mined to be given up")
+	}
+	backend.mu.Lock()
+	sent := len(backend.sent)
+	backend.minTip = big.NewInt(0)
+	backend.mu.Unlock()
+	if sent != 4 {
+		t.Fatalf("Expected the tx and 3 fee bumps to be sent, but got %d", sent)
+	}
+	if _, err := q.Send(ctx, unsignedTx(1)); err != nil {
+		t.Fatalf("Error sending tx: %v", err)
+	}
+	// the dropped tx did not use nonce 0, the next one reuses it instead of leaving a gap
+	if got := backend.nonces; len(got) != 1 || got[0] != 0 || backend.rejected != 0 {
+		t.Fatalf("Expected the next tx to be mined with nonce 0, but got %v (%d rejected)", got, backend.rejected)
+	}
+}
@@ -0,0 +1,260 @@
+// Package health runs the readiness checks of the operator and challenger and serves their
+// outcome as the service statuses and the node health of the node api, so that probes of
+// /imua/node/health and /imua/node/services/{id}/health see whether the node can do its work.
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/imua-xyz/imua-avs-sdk/logging"
+	"github.com/imua-xyz/imua-avs-sdk/nodeapi"
+)
+
+const (
+	// DefaultInterval is how often the checks run.
+	DefaultInterval = 15 * time.Second
+	// DefaultMaxHeadAge is how old the head block may be before the chain is considered stalled.
+	DefaultMaxHeadAge = 60 * time.Second
+	// checkTimeout bounds a single check.
+	checkTimeout = 10 * time.Second
+	// baseURL and specVersion are those of the node api of the sdk.
+	baseURL     = "/imua"
+	specVersion = "v0.0.1"
+)
+
+// Check is a readiness condition, reported as a service of the node api.
+type Check struct {
+	ID          string
+	Name        string
+	Description string
+	// Probe returns an error while the condition does not hold.
+	Probe func(ctx context.Context) error
+}
+
+// Aggregate returns the node health for the service statuses of checks: unhealthy while a
+// check is not up, healthy otherwise.
+func Aggregate(checks []Check, statuses []nodeapi.ServiceStatus) nodeapi.NodeHealth {
+	for i := range checks {
+		if statuses[i] != nodeapi.ServiceStatusUp {
+			return nodeapi.Unhealthy
+		}
+	}
+	return nodeapi.Healthy
+}
+
+// Monitor runs checks and serves their outcome with the endpoints of the node api. The
+// statuses are guarded by a mutex, as the checks update them while requests read them.
+type Monitor struct {
+	name    string
+	version string
+	logger  logging.Logger
+	checks  []Check
+
+	mu       sync.RWMutex
+	statuses []nodeapi.ServiceStatus
+	health   nodeapi.NodeHealth
+}
+
+// NewMonitor returns a monitor of checks for the node name at version. The checks start as
+// initializing services and the node is unhealthy until they pass.
+func NewMonitor(name, version string, logger logging.Logger, checks ...Check) *Monitor {
+	m := &Monitor{
+		name:     name,
+		version:  version,
+		logger:   logger,
+		checks:   checks,
+		statuses: make([]nodeapi.ServiceStatus, len(checks)),
+	}
+	for i := range checks {
+		m.statuses[i] = nodeapi.ServiceStatusInitializing
+	}
+	m.health = Aggregate(checks, m.statuses)
+	return m
+}
+
+// Run runs the checks every interval until ctx is done.
+func (m *Monitor) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		m.RunOnce(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce runs every check once and updates the statuses served.
+func (m *Monitor) RunOnce(ctx context.Context) {
+	statuses := make([]nodeapi.ServiceStatus, len(m.checks))
+	for i, check := range m.checks {
+		checkCtx, cancel := context.WithTimeout(ctx, checkTimeout)
+		err := check.Probe(checkCtx)
+		cancel()
+		statuses[i] = nodeapi.ServiceStatusUp
+		if err != nil {
+			statuses[i] = nodeapi.ServiceStatusDown
+		}
+		if prev, _ := m.Status(check.ID); statuses[i] != prev {
+			if err != nil {
+				m.logger.Warn("Health check failed", "check", check.ID, "err", err)
+			} else {
+				m.logger.Info("Health check passed", "check", check.ID)
+			}
+		}
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.statuses = statuses
+	m.health = Aggregate(m.checks, statuses)
+}
+
+// Health returns the node health.
+func (m *Monitor) Health() nodeapi.NodeHealth {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.health
+}
+
+// Status returns the status of the check with the given id, and whether there is one.
+func (m *Monitor) Status(id string) (nodeapi.ServiceStatus, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	for i, check := range m.checks {
+		if check.ID == id {
+			return m.statuses[i], true
+		}
+	}
+	return "", false
+}
+
+// service is a check as listed by /imua/node/services.
+type service struct {
+	ID          string                `json:"id"`
+	Name        string                `json:"name"`
+	Description string                `json:"description"`
+	Status      nodeapi.ServiceStatus `json:"status"`
+}
+
+// Handler serves the node api: /imua/node, /imua/node/health, /imua/node/services and
+// /imua/node/services/{id}/health.
+func (m *Monitor) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc(baseURL+"/node", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, map[string]string{"node_name": m.name, "spec_version": specVersion, "node_version": m.version})
+	})
+	mux.HandleFunc(baseURL+"/node/health", func(w http.ResponseWriter, r *http.Request) {
+		if m.Health() == nodeapi.Healthy {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+	mux.HandleFunc(baseURL+"/node/services", func(w http.ResponseWriter, r *http.Request) {
+		m.mu.RLock()
+		services := make([]service, len(m.checks))
+		for i, check := range m.checks {
+			services[i] = service{ID: check.ID, Name: check.Name, Description: check.Description, Status: m.statuses[i]}
+		}
+		m.mu.RUnlock()
+		writeJSON(w, map[string][]service{"services": services})
+	})
+	mux.HandleFunc(baseURL+"/node/services/", func(w http.ResponseWriter, r *http.Request) {
+		id, ok := strings.CutSuffix(strings.TrimPrefix(r.URL.Path, baseURL+"/node/services/"), "/health")
+		status, found := m.Status(id)
+		if !ok || !found {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		switch status {
+		case nodeapi.ServiceStatusUp:
+			w.WriteHeader(http.StatusOK)
+		case nodeapi.ServiceStatusInitializing:
+			w.WriteHeader(http.StatusPartialContent)
+		default:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	})
+	return mux
+}
+
+// Serve serves the node api on addr until ctx is done.
+func (m *Monitor) Serve(ctx context.Context, addr string) {
+	server := &http.Server{Addr: addr, Handler: m.Handler(), ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = server.Shutdown(shutdownCtx)
+	}()
+	m.logger.Info("Serving node api", "address", addr)
+	if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		m.logger.Error("Node api server stopped", "err", err)
+	}
+}
+
+func writeJSON(w http.ResponseWriter, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(data)
+}
+
+// Flag is a condition set by the code observing it, such as the connection hook of a log subscriber.
+type Flag struct {
+	up atomic.Bool
+}
+
+// Set records whether the condition holds.
+func (f *Flag) Set(up bool) {
+	f.up.Store(up)
+}
+
+// Probe returns a probe failing with reason while the flag is not set.
+func (f *Flag) Probe(reason string) func(ctx context.Context) error {
+	return func(context.Context) error {
+		if !f.up.Load() {
+			return fmt.Errorf("%s", reason)
+		}
+		return nil
+	}
+}
+
+// HeaderClient reads block headers.
+type HeaderClient interface {
+	HeaderByNumber(ctx context.Context, number *big.Int) (*ethtypes.Header, error)
+}
+
+// HeadRecent returns a probe failing while the head block of client is older than maxAge,
+// which means the node is not reachable or the chain stalled.
+func HeadRecent(client HeaderClient, maxAge time.Duration) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		head, err := client.HeaderByNumber(ctx, nil)
+		if err != nil {
+			return fmt.Errorf("failed to get head block: %w", err)
+		}
+		age := time.Since(time.Unix(int64(head.Time), 0))
+		if age > maxAge {
+			return fmt.Errorf("head block %s is %s old, more than %s", head.Number, age.Round(time.Second), maxAge)
+		}
+		return nil
+	}
+}
+
+// MaxHeadAge returns the configured maximum head age, given in seconds, or DefaultMaxHeadAge if it is 0.
+func MaxHeadAge(seconds uint64) time.Duration {
+	if seconds == 0 {
+		return DefaultMaxHeadAge
+	}
+	return time.Duration(seconds) * time.Second
+}
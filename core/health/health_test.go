@@ -0,0 +1,119 @@
+package health_test
+
+import (
+	"context"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	sdklogging "github.com/imua-xyz/imua-avs-sdk/logging"
+	"github.com/imua-xyz/imua-avs-sdk/nodeapi"
+	"github.com/imua-xyz/imua-avs/core/health"
+)
+
+func TestAggregate(t *testing.T) {
+	checks := []health.Check{{ID: "chain"}, {ID: "keys"}}
+	up, down, initializing := nodeapi.ServiceStatusUp, nodeapi.ServiceStatusDown, nodeapi.ServiceStatusInitializing
+	tests := []struct {
+		statuses []nodeapi.ServiceStatus
+		want     nodeapi.NodeHealth
+	}{
+		{[]nodeapi.ServiceStatus{up, up}, nodeapi.Healthy},
+		{[]nodeapi.ServiceStatus{up, down}, nodeapi.Unhealthy},
+		{[]nodeapi.ServiceStatus{down, up}, nodeapi.Unhealthy},
+		{[]nodeapi.ServiceStatus{initializing, up}, nodeapi.Unhealthy},
+	}
+	for _, tt := range tests {
+		if got := health.Aggregate(checks, tt.statuses); got != tt.want {
+			t.Errorf("Aggregate(%v) = %v, expected %v", tt.statuses, got, tt.want)
+		}
+	}
+}
+
+type fakeHeaderClient struct {
+	time time.Time
+}
+
+func (c fakeHeaderClient) HeaderByNumber(ctx context.Context, number *big.Int) (*ethtypes.Header, error) {
+	return &ethtypes.Header{Number: big.NewInt(7), Time: uint64(c.time.Unix())}, nil
+}
+
+func TestHeadRecent(t *testing.T) {
+	ctx := context.Background()
+	if err := health.HeadRecent(fakeHeaderClient{time.Now()}, time.Minute)(ctx); err != nil {
+		t.Errorf("Expected a fresh head to pass, got %v", err)
+	}
+	if err := health.HeadRecent(fakeHeaderClient{time.Now().Add(-2 * time.Minute)}, time.Minute)(ctx); err == nil {
+		t.Errorf("Expected a stale head to fail")
+	}
+}
+
+func TestFlag(t *testing.T) {
+	var f health.Flag
+	probe := f.Probe("down")
+	if probe(context.Background()) == nil {
+		t.Errorf("Expected an unset flag to fail")
+	}
+	f.Set(true)
+	if err := probe(context.Background()); err != nil {
+		t.Errorf("Expected a set flag to pass, got %v", err)
+	}
+}
+
+func TestMonitorServesStatuses(t *testing.T) {
+	logger, err := sdklogging.NewZapLogger(sdklogging.Development)
+	if err != nil {
+		t.Fatalf("Error creating logger: %v", err)
+	}
+	var chain, keys health.Flag
+	m := health.NewMonitor("node", "v1", logger,
+		health.Check{ID: "chain", Probe: chain.Probe("chain down")},
+		health.Check{ID: "keys", Probe: keys.Probe("keys down")},
+	)
+	server := httptest.NewServer(m.Handler())
+	defer server.Close()
+	get := func(path string) int {
+		resp, err := http.Get(server.URL + path)
+		if err != nil {
+			t.Fatalf("Error requesting %s: %v", path, err)
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+	if code := get("/imua/node/services/chain/health"); code != http.StatusPartialContent {
+		t.Errorf("Expected an initializing check to answer 206, but got %d", code)
+	}
+
+	// checks run while requests are served, go test -race reports unguarded statuses
+	chain.Set(true)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 20; i++ {
+			m.RunOnce(context.Background())
+		}
+	}()
+	for i := 0; i < 20; i++ {
+		get("/imua/node/health")
+		get("/imua/node/services")
+	}
+	<-done
+
+	if code := get("/imua/node/health"); code != http.StatusServiceUnavailable {
+		t.Errorf("Expected a failing check to make the node unhealthy, but got %d", code)
+	}
+	keys.Set(true)
+	m.RunOnce(context.Background())
+	if code := get("/imua/node/health"); code != http.StatusOK {
+		t.Errorf("Expected passing checks to make the node healthy, but got %d", code)
+	}
+	if code := get("/imua/node/services/chain/health"); code != http.StatusOK {
+		t.Errorf("Expected a passing check to answer 200, but got %d", code)
+	}
+	if code := get("/imua/node/services/unknown/health"); code != http.StatusNotFound {
+		t.Errorf("Expected an unknown check to answer 404, but got %d", code)
+	}
+}
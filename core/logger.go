@@ -0,0 +1,109 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"syscall"
+
+	"github.com/imua-xyz/imua-avs-sdk/logging"
+	"go.uber.org/zap"
+)
+
+// ZapLogger is the zap logger of the sdk, which in addition can be synced on shutdown.
+type ZapLogger struct {
+	logger *zap.SugaredLogger
+}
+
+// NewZapLogger returns a logger configured like logging.NewZapLogger of the sdk.
+func NewZapLogger(level logging.LogLevel) (*ZapLogger, error) {
+	var logger *zap.Logger
+	var err error
+	switch level {
+	case logging.Production:
+		logger, err = zap.NewProduction()
+	case logging.Development:
+		logger, err = zap.NewDevelopment()
+	default:
+		return nil, fmt.Errorf("unknown log level %q, expected %s or %s", level, logging.Development, logging.Production)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &ZapLogger{logger: logger.Sugar()}, nil
+}
+
+func (z *ZapLogger) Debug(msg string, tags ...any) { z.logger.Debugw(msg, tags...) }
+func (z *ZapLogger) Info(msg string, tags ...any)  { z.logger.Infow(msg, tags...) }
+func (z *ZapLogger) Warn(msg string, tags ...any)  { z.logger.Warnw(msg, tags...) }
+func (z *ZapLogger) Error(msg string, tags ...any) { z.logger.Errorw(msg, tags...) }
+func (z *ZapLogger) Fatal(msg string, tags ...any) { z.logger.Fatalw(msg, tags...) }
+
+func (z *ZapLogger) Debugf(template string, args ...interface{}) { z.logger.Debugf(template, args...) }
+func (z *ZapLogger) Infof(template string, args ...interface{})  { z.logger.Infof(template, args...) }
+func (z *ZapLogger) Warnf(template string, args ...interface{})  { z.logger.Warnf(template, args...) }
+func (z *ZapLogger) Errorf(template string, args ...interface{}) { z.logger.Errorf(template, args...) }
+func (z *ZapLogger) Fatalf(template string, args ...interface{}) { z.logger.Fatalf(template, args...) }
+
+// Sync flushes the buffered log entries. Syncing a terminal or pipe is not supported by the
+// OS and is not an error.
+func (z *ZapLogger) Sync() error {
+	err := z.logger.Sync()
+	if errors.Is(err, syscall.EINVAL) || errors.Is(err, syscall.ENOTTY) {
+		return nil
+	}
+	return err
+}
+
+// SyncLogger flushes the buffered log entries of logger, if it buffers any.
+func SyncLogger(logger logging.Logger) error {
+	if s, ok := logger.(interface{ Sync() error }); ok {
+		return s.Sync()
+	}
+	return nil
+}
+
+// taggedLogger adds fixed tags to every log line of the logger it wraps.
+type taggedLogger struct {
+	logging.Logger
+	tags   []any
+	prefix string
+}
+
+// LoggerWithTags returns a logger that adds the key value pairs tags to every log line,
+// and prefixes the templates of the formatting methods with them.
+func LoggerWithTags(logger logging.Logger, tags ...any) logging.Logger {
+	var prefix strings.Builder
+	for i := 0; i+1 < len(tags); i += 2 {
+		fmt.Fprintf(&prefix, "%v=%v ", tags[i], tags[i+1])
+	}
+	return &taggedLogger{Logger: logger, tags: tags, prefix: strings.ReplaceAll(prefix.String(), "%", "%%")}
+}
+
+func (l *taggedLogger) with(tags []any) []any {
+	return append(append([]any{}, tags...), l.tags...)
+}
+
+func (l *taggedLogger) Debug(msg string, tags ...any) { l.Logger.Debug(msg, l.with(tags)...) }
+func (l *taggedLogger) Info(msg string, tags ...any)  { l.Logger.Info(msg, l.with(tags)...) }
+func (l *taggedLogger) Warn(msg string, tags ...any)  { l.Logger.Warn(msg, l.with(tags)...) }
+func (l *taggedLogger) Error(msg string, tags ...any) { l.Logger.Error(msg, l.with(tags)...) }
+func (l *taggedLogger) Fatal(msg string, tags ...any) { l.Logger.Fatal(msg, l.with(tags)...) }
+
+func (l *taggedLogger) Debugf(template string, args ...interface{}) {
+	l.Logger.Debugf(l.prefix+template, args...)
+}
+func (l *taggedLogger) Infof(template string, args ...interface{}) {
+	l.Logger.Infof(l.prefix+template, args...)
+}
+func (l *taggedLogger) Warnf(template string, args ...interface{}) {
+	l.Logger.Warnf(l.prefix+template, args...)
+}
+func (l *taggedLogger) Errorf(template string, args ...interface{}) {
+	l.Logger.Errorf(l.prefix+template, args...)
+}
+func (l *taggedLogger) Fatalf(template string, args ...interface{}) {
+	l.Logger.Fatalf(l.prefix+template, args...)
+}
+
+func (l *taggedLogger) Sync() error { return SyncLogger(l.Logger) }
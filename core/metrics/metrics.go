@@ -0,0 +1,194 @@
+// Package metrics exports Prometheus metrics of the avs, operator and challenger.
+// Every role reports the common series of Metrics, the role specific ones are in
+// OperatorMetrics, AvsMetrics and ChallengerMetrics.
+package metrics
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/imua-xyz/imua-avs-sdk/logging"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const (
+	namespace = "hello_avs"
+	// Path is where the metrics are served.
+	Path = "/metrics"
+	// DefaultPollInterval is how often the polled series are refreshed.
+	DefaultPollInterval = 15 * time.Second
+)
+
+// Metrics holds the series every role reports, registered on a registry of its own.
+type Metrics struct {
+	registry *prometheus.Registry
+	role     string
+
+	currentEpoch           *prometheus.GaugeVec
+	subscriptionUp         *prometheus.GaugeVec
+	subscriptionReconnects *prometheus.CounterVec
+	balance                prometheus.Gauge
+	rpcLatency             *prometheus.HistogramVec
+	rpcErrors              *prometheus.CounterVec
+
+	mu sync.Mutex
+	// subscribed holds the AVSs whose log subscription was established before
+	subscribed map[string]bool
+}
+
+// New returns the common metrics of role, "avs", "operator" or "challenger".
+func New(role string) *Metrics {
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(collectors.NewGoCollector(), collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}))
+	labels := prometheus.Labels{"role": role}
+	m := &Metrics{
+		registry:   registry,
+		role:       role,
+		subscribed: map[string]bool{},
+		currentEpoch: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace, Name: "current_epoch", ConstLabels: labels,
+			Help: "Current epoch of an AVS epoch identifier.",
+		}, []string{"epoch_identifier"}),
+		subscriptionUp: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace, Name: "ws_subscription_up", ConstLabels: labels,
+			Help: "1 while the websocket log subscription of an AVS is established, 0 while it is reconnecting.",
+		}, []string{"avs"}),
+		subscriptionReconnects: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace, Name: "ws_subscription_reconnects_total", ConstLabels: labels,
+			Help: "Websocket log subscriptions of an AVS established after the first one.",
+		}, []string{"avs"}),
+		balance: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace, Name: "account_balance_wei", ConstLabels: labels,
+			Help: "Balance of the account sending the transactions of this role, in wei.",
+		}),
+		rpcLatency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace, Name: "rpc_latency_seconds", ConstLabels: labels,
+			Help:    "Latency of the RPC calls, the polled ones and those of the task flows.",
+			Buckets: prometheus.ExponentialBuckets(0.005, 2, 12),
+		}, []string{"method"}),
+		rpcErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace, Name: "rpc_errors_total", ConstLabels: labels,
+			Help: "Failed RPC calls, the polled ones and those of the task flows.",
+		}, []string{"method"}),
+	}
+	registry.MustRegister(m.currentEpoch, m.subscriptionUp, m.subscriptionReconnects, m.balance, m.rpcLatency, m.rpcErrors)
+	return m
+}
+
+// Registry returns the registry the metrics are registered on.
+func (m *Metrics) Registry() *prometheus.Registry {
+	return m.registry
+}
+
+// SetSubscriptionUp records whether the websocket log subscription of avs is established.
+func (m *Metrics) SetSubscriptionUp(avs string, up bool) {
+	if !up {
+		m.subscriptionUp.WithLabelValues(avs).Set(0)
+		return
+	}
+	m.subscriptionUp.WithLabelValues(avs).Set(1)
+	m.mu.Lock()
+	reconnect := m.subscribed[avs]
+	m.subscribed[avs] = true
+	m.mu.Unlock()
+	if reconnect {
+		m.subscriptionReconnects.WithLabelValues(avs).Inc()
+	}
+}
+
+// ObserveRPC records the latency and the outcome of an RPC call started at start.
+func (m *Metrics) ObserveRPC(method string, start time.Time, err error) {
+	m.rpcLatency.WithLabelValues(method).Observe(time.Since(start).Seconds())
+	if err != nil {
+		m.rpcErrors.WithLabelValues(method).Inc()
+	}
+}
+
+// BalanceClient reads account balances.
+type BalanceClient interface {
+	BalanceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (*big.Int, error)
+}
+
+// PollConfig is what Poll refreshes.
+type PollConfig struct {
+	// Client and Account select the balance reported as account_balance_wei.
+	Client  BalanceClient
+	Account common.Address
+	// CurrentEpoch returns the current epoch of each of EpochIdentifiers.
+	EpochIdentifiers []string
+	CurrentEpoch     func(ctx context.Context, epochIdentifier string) (int64, error)
+	// OnPoll refreshes role specific series, it may be nil.
+	OnPoll func(ctx context.Context)
+	// Interval defaults to DefaultPollInterval.
+	Interval time.Duration
+}
+
+// Poll refreshes the polled series until ctx is done.
+func (m *Metrics) Poll(ctx context.Context, c PollConfig) {
+	interval := c.Interval
+	if interval == 0 {
+		interval = DefaultPollInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		m.poll(ctx, c)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (m *Metrics) poll(ctx context.Context, c PollConfig) {
+	if c.Client != nil {
+		start := time.Now()
+		balance, err := c.Client.BalanceAt(ctx, c.Account, nil)
+		m.ObserveRPC("eth_getBalance", start, err)
+		if err == nil {
+			f, _ := new(big.Float).SetInt(balance).Float64()
+			m.balance.Set(f)
+		}
+	}
+	for _, epochIdentifier := range c.EpochIdentifiers {
+		start := time.Now()
+		epoch, err := c.CurrentEpoch(ctx, epochIdentifier)
+		m.ObserveRPC("getCurrentEpoch", start, err)
+		if err == nil {
+			m.currentEpoch.WithLabelValues(epochIdentifier).Set(float64(epoch))
+		}
+	}
+	if c.OnPoll != nil {
+		c.OnPoll(ctx)
+	}
+}
+
+// Handler returns the handler exposing the metrics in the Prometheus text format.
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{Registry: m.registry})
+}
+
+// Serve serves the metrics on addr until ctx is done.
+func (m *Metrics) Serve(ctx context.Context, addr string, logger logging.Logger) {
+	mux := http.NewServeMux()
+	mux.Handle(Path, m.Handler())
+	server := &http.Server{Addr: addr, Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = server.Shutdown(shutdownCtx)
+	}()
+	logger.Info("Serving metrics", "address", addr, "path", Path, "role", m.role)
+	if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		logger.Error("Metrics server stopped", "err", err)
+	}
+}
@@ -0,0 +1,131 @@
+package metrics_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/big"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/imua-xyz/imua-avs/core/chainio/eth"
+	"github.com/imua-xyz/imua-avs/core/metrics"
+)
+
+type fakeBalanceClient struct{}
+
+func (fakeBalanceClient) BalanceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (*big.Int, error) {
+	return big.NewInt(1500), nil
+}
+
+func scrape(t *testing.T, m *metrics.Metrics) string {
+	server := httptest.NewServer(m.Handler())
+	defer server.Close()
+	resp, err := server.Client().Get(server.URL)
+	if err != nil {
+		t.Fatalf("Failed to scrape metrics: %v", err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("Failed to read metrics: %v", err)
+	}
+	return string(body)
+}
+
+func TestOperatorMetrics(t *testing.T) {
+	m := metrics.NewOperatorMetrics()
+	m.TasksSeen.WithLabelValues("hello").Inc()
+	m.TasksSigned.WithLabelValues("hello").Inc()
+	m.Submissions.WithLabelValues("hello", "1", metrics.ResultSubmitted).Inc()
+	m.Submissions.WithLabelValues("hello", "2", metrics.ResultFailed).Inc()
+	// the first subscription is not a reconnect, the one after the drop is
+	m.SetSubscriptionUp("hello", true)
+	m.SetSubscriptionUp("hello", false)
+	m.SetSubscriptionUp("hello", true)
+	m.SetSubscriptionUp("other", true)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	m.Poll(ctx, metrics.PollConfig{
+		Client:           fakeBalanceClient{},
+		EpochIdentifiers: []string{"minute"},
+		CurrentEpoch: func(ctx context.Context, epochIdentifier string) (int64, error) {
+			return 42, nil
+		},
+	})
+
+	body := scrape(t, m.Metrics)
+	for _, want := range []string{
+		`hello_avs_operator_tasks_seen_total{avs="hello"} 1`,
+		`hello_avs_operator_tasks_signed_total{avs="hello"} 1`,
+		`hello_avs_operator_submissions_total{avs="hello",phase="1",result="submitted"} 1`,
+		`hello_avs_operator_submissions_total{avs="hello",phase="2",result="failed"} 1`,
+		`hello_avs_ws_subscription_up{avs="hello",role="operator"} 1`,
+		`hello_avs_ws_subscription_reconnects_total{avs="hello",role="operator"} 1`,
+		`hello_avs_account_balance_wei{role="operator"} 1500`,
+		`hello_avs_current_epoch{epoch_identifier="minute",role="operator"} 42`,
+		`hello_avs_rpc_latency_seconds_count{method="eth_getBalance",role="operator"} 1`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("Expected metrics to contain %q", want)
+		}
+	}
+}
+
+// fakeEthClient answers the task calls, the other methods of eth.EthClient are not used.
+type fakeEthClient struct {
+	eth.EthClient
+}
+
+func (fakeEthClient) CallContract(ctx context.Context, msg ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	return nil, nil
+}
+
+func (fakeEthClient) SendTransaction(ctx context.Context, tx *ethtypes.Transaction) error {
+	return errors.New("nonce too low")
+}
+
+func (fakeEthClient) TransactionReceipt(ctx context.Context, txHash common.Hash) (*ethtypes.Receipt, error) {
+	return nil, ethereum.NotFound
+}
+
+func TestObservedClientRecordsTaskRPCs(t *testing.T) {
+	m := metrics.NewChallengerMetrics()
+	client := eth.NewObservedClient(fakeEthClient{}, m.ObserveRPC)
+	ctx := context.Background()
+	if _, err := client.CallContract(ctx, ethereum.CallMsg{}, nil); err != nil {
+		t.Fatalf("Unexpected CallContract error: %v", err)
+	}
+	if err := client.SendTransaction(ctx, ethtypes.NewTx(&ethtypes.LegacyTx{})); err == nil {
+		t.Fatalf("Expected the SendTransaction error to be passed on")
+	}
+	// a receipt that is not there yet is passed on, but is not a failed call
+	if _, err := client.TransactionReceipt(ctx, common.Hash{}); !errors.Is(err, ethereum.NotFound) {
+		t.Fatalf("Expected ethereum.NotFound, but got %v", err)
+	}
+
+	body := scrape(t, m.Metrics)
+	for _, want := range []string{
+		`hello_avs_rpc_latency_seconds_count{method="eth_call",role="challenger"} 1`,
+		`hello_avs_rpc_latency_seconds_count{method="eth_sendRawTransaction",role="challenger"} 1`,
+		`hello_avs_rpc_latency_seconds_count{method="eth_getTransactionReceipt",role="challenger"} 1`,
+		`hello_avs_rpc_errors_total{method="eth_sendRawTransaction",role="challenger"} 1`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("Expected metrics to contain %q", want)
+		}
+	}
+	for _, unwanted := range []string{
+		`hello_avs_rpc_errors_total{method="eth_call"`,
+		`hello_avs_rpc_errors_total{method="eth_getTransactionReceipt"`,
+	} {
+		if strings.Contains(body, unwanted) {
+			t.Errorf("Expected metrics not to contain %q", unwanted)
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Outcomes of a task submission or a challenge, the values of the result label.
+const (
+	ResultSubmitted = "submitted"
+	ResultFailed    = "failed"
+	ResultRaised    = "raised"
+	ResultSkipped   = "skipped"
+	ResultResolved  = "resolved"
+	ResultSimulated = "simulated"
+)
+
+// OperatorMetrics are the series of the operator.
+type OperatorMetrics struct {
+	*Metrics
+	// TasksSeen and TasksSigned count tasks by avs.
+	TasksSeen   *prometheus.CounterVec
+	TasksSigned *prometheus.CounterVec
+	// Submissions counts phase submissions by avs, phase ("1", "2") and result (ResultSubmitted, ResultFailed).
+	Submissions *prometheus.CounterVec
+	// ShadowVerdicts counts the comparisons of dry-run and shadow mode by avs and verdict
+	// ("match", "mismatch", "unanswered").
+	ShadowVerdicts *prometheus.CounterVec
+	// TaskHashMismatches counts by avs the TaskCreated events refused because they differ from the task on chain.
+	TaskHashMismatches *prometheus.CounterVec
+}
+
+// NewOperatorMetrics returns the operator metrics.
+func NewOperatorMetrics() *OperatorMetrics {
+	m := &OperatorMetrics{
+		Metrics: New("operator"),
+		TasksSeen: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace, Subsystem: "operator", Name: "tasks_seen_total",
+			Help: "Tasks taken up by the operator, from the live subscription, the backfill or the journal.",
+		}, []string{"avs"}),
+		TasksSigned: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace, Subsystem: "operator", Name: "tasks_signed_total",
+			Help: "Task responses signed with the operator BLS key.",
+		}, []string{"avs"}),
+		Submissions: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace, Subsystem: "operator", Name: "submissions_total",
+			Help: "Task phase submissions by phase and result.",
+		}, []string{"avs", "phase", "result"}),
+		ShadowVerdicts: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace, Subsystem: "operator", Name: "shadow_verdicts_total",
+			Help: "Task responses of dry-run and shadow mode compared with the responses on chain, by verdict.",
+		}, []string{"avs", "verdict"}),
+		TaskHashMismatches: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace, Subsystem: "operator", Name: "task_hash_mismatches_total",
+			Help: "TaskCreated events not signed because their fields do not hash to the task hash on chain.",
+		}, []string{"avs"}),
+	}
+	m.registry.MustRegister(m.TasksSeen, m.TasksSigned, m.Submissions, m.ShadowVerdicts, m.TaskHashMismatches)
+	return m
+}
+
+// AvsMetrics are the series of the avs.
+type AvsMetrics struct {
+	*Metrics
+	TasksCreated         prometheus.Counter
+	TaskCreationFailures prometheus.Counter
+	USDValue             prometheus.Gauge
+}
+
+// NewAvsMetrics returns the avs metrics.
+func NewAvsMetrics() *AvsMetrics {
+	m := &AvsMetrics{
+		Metrics: New("avs"),
+		TasksCreated: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace, Subsystem: "avs", Name: "tasks_created_total",
+			Help: "Tasks created on chain.",
+		}),
+		TaskCreationFailures: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace, Subsystem: "avs", Name: "task_creation_failures_total",
+			Help: "Tasks that could not be created after all retries.",
+		}),
+		USDValue: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace, Subsystem: "avs", Name: "usd_value",
+			Help: "USD value of the stake opted in to the AVS.",
+		}),
+	}
+	m.registry.MustRegister(m.TasksCreated, m.TaskCreationFailures, m.USDValue)
+	return m
+}
+
+// ChallengerMetrics are the series of the challenger.
+type ChallengerMetrics struct {
+	*Metrics
+	// Challenges counts challenges by result (ResultRaised, ResultSkipped, ResultResolved,
+	// ResultSimulated, ResultFailed).
+	Challenges *prometheus.CounterVec
+	// ResponseCheckFailures counts operator responses failing a pre-challenge check, by check.
+	ResponseCheckFailures *prometheus.CounterVec
+}
+
+// NewChallengerMetrics returns the challenger metrics.
+func NewChallengerMetrics() *ChallengerMetrics {
+	m := &ChallengerMetrics{
+		Metrics: New("challenger"),
+		Challenges: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace, Subsystem: "challenger", Name: "challenges_total",
+			Help: "Challenges by result.",
+		}, []string{"result"}),
+		ResponseCheckFailures: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace, Subsystem: "challenger", Name: "response_check_failures_total",
+			Help: "Operator task responses failing a check before a challenge, by check.",
+		}, []string{"check"}),
+	}
+	m.registry.MustRegister(m.Challenges, m.ResponseCheckFailures)
+	return m
+}
@@ -0,0 +1,205 @@
+// Package network holds the constants that differ between imuachain networks, grouped in
+// profiles selected by the network config key, so that the same binaries run on every network.
+package network
+
+import (
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+
+	"github.com/cosmos/btcutil/bech32"
+	"github.com/ethereum/go-ethereum/common"
+	"gopkg.in/yaml.v3"
+)
+
+// Profile describes an imuachain network.
+type Profile struct {
+	// Name identifies the profile in logs.
+	Name string `yaml:"name"`
+	// Base is the built-in profile a custom profile takes its unset fields from, localnet by default.
+	Base string `yaml:"base,omitempty"`
+	// ChainID is the cosmos chain id, e.g. "imuachainlocalnet_232", signed in the BLS key registration message.
+	ChainID string `yaml:"chain_id"`
+	// EVMChainID is the EIP-155 chain id the node must report, 0 skips the check.
+	EVMChainID uint64 `yaml:"evm_chain_id"`
+	// Bech32Prefix is the human readable part of account addresses, e.g. "im".
+	Bech32Prefix string `yaml:"bech32_prefix"`
+	// DepositPrecompile, DelegationPrecompile and AVSManagerPrecompile are the addresses
+	// of the imuachain precompiles used for staking and by the AVS contract.
+	DepositPrecompile    common.Address `yaml:"deposit_precompile"`
+	DelegationPrecompile common.Address `yaml:"delegation_precompile"`
+	AVSManagerPrecompile common.Address `yaml:"avs_manager_precompile"`
+}
+
+// Names of the built-in profiles.
+const (
+	LocalnetName = "localnet"
+	TestnetName  = "testnet"
+	MainnetName  = "mainnet"
+)
+
+var (
+	depositPrecompile    = common.HexToAddress("0x0000000000000000000000000000000000000804")
+	delegationPrecompile = common.HexToAddress("0x0000000000000000000000000000000000000805")
+	avsManagerPrecompile = common.HexToAddress("0x0000000000000000000000000000000000000901")
+
+	// Localnet is a local imuachain node as started by the imuachain scripts.
+	Localnet = Profile{
+		Name:                 LocalnetName,
+		ChainID:              "imuachainlocalnet_232",
+		EVMChainID:           232,
+		Bech32Prefix:         "im",
+		DepositPrecompile:    depositPrecompile,
+		DelegationPrecompile: delegationPrecompile,
+		AVSManagerPrecompile: avsManagerPrecompile,
+	}
+	// Testnet is the public imuachain testnet.
+	Testnet = Profile{
+		Name:                 TestnetName,
+		ChainID:              "imuachaintestnet_233",
+		EVMChainID:           233,
+		Bech32Prefix:         "im",
+		DepositPrecompile:    depositPrecompile,
+		DelegationPrecompile: delegationPrecompile,
+		AVSManagerPrecompile: avsManagerPrecompile,
+	}
+	// Mainnet is the imuachain mainnet. Its chain ids are not built in until they are published,
+	// it is selected through a profile file with base mainnet that sets chain_id and evm_chain_id.
+	Mainnet = Profile{
+		Name:                 MainnetName,
+		Bech32Prefix:         "im",
+		DepositPrecompile:    depositPrecompile,
+		DelegationPrecompile: delegationPrecompile,
+		AVSManagerPrecompile: avsManagerPrecompile,
+	}
+)
+
+var builtins = map[string]Profile{
+	LocalnetName: Localnet,
+	TestnetName:  Testnet,
+	MainnetName:  Mainnet,
+}
+
+// Load returns the profile selected by the network config key: the name of a built-in
+// profile, or the path of a YAML file holding a custom one. Empty selects Localnet.
+func Load(network string) (Profile, error) {
+	if network == "" {
+		return Localnet, nil
+	}
+	if p, ok := builtins[network]; ok {
+		return p, p.validate()
+	}
+	data, err := os.ReadFile(network)
+	if err != nil {
+		return Profile{}, fmt.Errorf("network %q is neither a built-in profile (%s, %s, %s) nor a readable profile file: %w",
+			network, LocalnetName, TestnetName, MainnetName, err)
+	}
+	return Parse(data)
+}
+
+// Parse parses a custom YAML profile. Fields it leaves unset are taken from its base profile.
+func Parse(data []byte) (Profile, error) {
+	var custom Profile
+	if err := yaml.Unmarshal(data, &custom); err != nil {
+		return Profile{}, fmt.Errorf("failed to parse network profile: %w", err)
+	}
+	baseName := custom.Base
+	if baseName == "" {
+		baseName = LocalnetName
+	}
+	p, ok := builtins[baseName]
+	if !ok {
+		return Profile{}, fmt.Errorf("network profile %q has unknown base %q", custom.Name, baseName)
+	}
+	p.Base = baseName
+	if custom.Name != "" {
+		p.Name = custom.Name
+	}
+	if custom.ChainID != "" {
+		p.ChainID = custom.ChainID
+	}
+	if custom.EVMChainID != 0 {
+		p.EVMChainID = custom.EVMChainID
+	}
+	if custom.Bech32Prefix != "" {
+		p.Bech32Prefix = custom.Bech32Prefix
+	}
+	if custom.DepositPrecompile != (common.Address{}) {
+		p.DepositPrecompile = custom.DepositPrecompile
+	}
+	if custom.DelegationPrecompile != (common.Address{}) {
+		p.DelegationPrecompile = custom.DelegationPrecompile
+	}
+	if custom.AVSManagerPrecompile != (common.Address{}) {
+		p.AVSManagerPrecompile = custom.AVSManagerPrecompile
+	}
+	return p, p.validate()
+}
+
+// validate returns an error if the profile lacks a chain id, or on mainnet the EVM chain id the
+// node is checked against.
+func (p Profile) validate() error {
+	mainnet := p.Name == MainnetName || p.Base == MainnetName
+	switch {
+	case p.ChainID == "" && mainnet:
+		return fmt.Errorf("network %s has no built-in chain ids, select a profile file with base %s that sets chain_id and evm_chain_id",
+			p.Name, MainnetName)
+	case p.ChainID == "":
+		return fmt.Errorf("network %s has no chain_id", p.Name)
+	case p.EVMChainID == 0 && mainnet:
+		return fmt.Errorf("network %s must set evm_chain_id, the node is not checked against mainnet otherwise", p.Name)
+	}
+	return nil
+}
+
+// CheckEVMChainID returns an error if the node reports a chain id other than the profile's.
+func (p Profile) CheckEVMChainID(chainID *big.Int) error {
+	if p.EVMChainID == 0 || chainID.Cmp(new(big.Int).SetUint64(p.EVMChainID)) == 0 {
+		return nil
+	}
+	return fmt.Errorf("network %s expects evm chain id %d, but the node reports %s", p.Name, p.EVMChainID, chainID)
+}
+
+// Bech32Address returns the bech32 account address of address on the network.
+func (p Profile) Bech32Address(address common.Address) (string, error) {
+	bech32Address, err := bech32.EncodeFromBase256(p.Bech32Prefix, address.Bytes())
+	if err != nil {
+		return "", fmt.Errorf("failed to encode bech32 address: %w", err)
+	}
+	return bech32Address, nil
+}
+
+// ParseAddress parses an account given either as a hex address or as a bech32 address of the network.
+func (p Profile) ParseAddress(address string) (common.Address, error) {
+	if common.IsHexAddress(address) {
+		if !strings.HasPrefix(address, "0x") && !strings.HasPrefix(address, "0X") {
+			return common.Address{}, fmt.Errorf("hex address %q must start with 0x", address)
+		}
+		return common.HexToAddress(address), nil
+	}
+	hrp, b, err := bech32.DecodeToBase256(address)
+	if err != nil {
+		return common.Address{}, fmt.Errorf("%q is neither a hex nor a bech32 address: %w", address, err)
+	}
+	if hrp != p.Bech32Prefix {
+		return common.Address{}, fmt.Errorf("bech32 address %q has prefix %q, expected %q on %s", address, hrp, p.Bech32Prefix, p.Name)
+	}
+	if len(b) != common.AddressLength {
+		return common.Address{}, fmt.Errorf("bech32 address %q is %d bytes long, expected %d", address, len(b), common.AddressLength)
+	}
+	return common.BytesToAddress(b), nil
+}
+
+// ParseAddresses parses a list of accounts given as hex or bech32 addresses of the network.
+func (p Profile) ParseAddresses(addresses []string) ([]common.Address, error) {
+	var parsed []common.Address
+	for _, address := range addresses {
+		addr, err := p.ParseAddress(address)
+		if err != nil {
+			return nil, err
+		}
+		parsed = append(parsed, addr)
+	}
+	return parsed, nil
+}
@@ -0,0 +1,112 @@
+package network_test
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/imua-xyz/imua-avs/core/network"
+)
+
+func TestParseAddress(t *testing.T) {
+	want := common.HexToAddress("0x3e108c058e8066DA635321Dc3018294cA82ddEdf")
+	for _, address := range []string{
+		"0x3e108c058e8066DA635321Dc3018294cA82ddEdf",
+		"im18cggcpvwspnd5c6ny8wrqxpffj5zmhkl3agtrj",
+	} {
+		got, err := network.Localnet.ParseAddress(address)
+		if err != nil {
+			t.Fatalf("Error parsing %s: %v", address, err)
+		}
+		if got != want {
+			t.Fatalf("Expected %s to parse to %s, but got %s", address, want, got)
+		}
+	}
+
+	for _, address := range []string{
+		"",
+		"3e108c058e8066DA635321Dc3018294cA82ddEdf",
+		"0x3e108c058e8066DA635321Dc3018294cA82ddEd",
+		"imvaloper18cggcpvwspnd5c6ny8wrqxpffj5zmhklxvzxw9",
+		"im18cggcpvwspnd5c6ny8wrqxpffj5zmhkl3agtrx",
+	} {
+		if _, err := network.Localnet.ParseAddress(address); err == nil {
+			t.Fatalf("Expected an error for address %q", address)
+		}
+	}
+
+	imAddress, err := network.Localnet.Bech32Address(want)
+	if err != nil {
+		t.Fatalf("Error encoding address: %v", err)
+	}
+	if imAddress != "im18cggcpvwspnd5c6ny8wrqxpffj5zmhkl3agtrj" {
+		t.Fatalf("Expected im18cggcpvwspnd5c6ny8wrqxpffj5zmhkl3agtrj, but got %s", imAddress)
+	}
+}
+
+func TestLoad(t *testing.T) {
+	p, err := network.Load("")
+	if err != nil || p.Name != network.LocalnetName {
+		t.Fatalf("Expected the localnet profile by default, but got %+v, %v", p, err)
+	}
+	p, err = network.Load(network.TestnetName)
+	if err != nil || p.ChainID != "imuachaintestnet_233" {
+		t.Fatalf("Expected the testnet profile, but got %+v, %v", p, err)
+	}
+	if _, err := network.Load("devnet"); err == nil {
+		t.Fatalf("Expected an error for an unknown network")
+	}
+	if _, err := network.Load(network.MainnetName); err == nil {
+		t.Fatalf("Expected an error for mainnet without chain ids")
+	}
+}
+
+func TestParseMainnetProfile(t *testing.T) {
+	p, err := network.Parse([]byte(`
+base: mainnet
+chain_id: imuachainmainnet_9999
+evm_chain_id: 9999
+`))
+	if err != nil {
+		t.Fatalf("Error parsing profile: %v", err)
+	}
+	if p.Name != network.MainnetName || p.Bech32Prefix != "im" || p.AVSManagerPrecompile != network.Mainnet.AVSManagerPrecompile {
+		t.Fatalf("Expected unset fields to come from mainnet, but got %+v", p)
+	}
+	for _, profile := range []string{
+		"base: mainnet\nevm_chain_id: 9999\n",
+		"base: mainnet\nchain_id: imuachainmainnet_9999\n",
+	} {
+		if _, err := network.Parse([]byte(profile)); err == nil {
+			t.Errorf("Expected an error for the mainnet profile %q missing a chain id", profile)
+		}
+	}
+}
+
+func TestParseCustomProfile(t *testing.T) {
+	p, err := network.Parse([]byte(`
+name: devnet
+base: testnet
+chain_id: imuachaindevnet_999
+evm_chain_id: 999
+deposit_precompile: "0x0000000000000000000000000000000000000904"
+`))
+	if err != nil {
+		t.Fatalf("Error parsing profile: %v", err)
+	}
+	if p.Name != "devnet" || p.ChainID != "imuachaindevnet_999" || p.EVMChainID != 999 {
+		t.Fatalf("Expected the custom fields to be set, but got %+v", p)
+	}
+	if p.DepositPrecompile != common.HexToAddress("0x904") {
+		t.Fatalf("Expected the custom deposit precompile, but got %s", p.DepositPrecompile)
+	}
+	if p.DelegationPrecompile != network.Testnet.DelegationPrecompile || p.Bech32Prefix != "im" {
+		t.Fatalf("Expected unset fields to come from testnet, but got %+v", p)
+	}
+	if err := p.CheckEVMChainID(big.NewInt(999)); err != nil {
+		t.Fatalf("Expected chain id 999 to match: %v", err)
+	}
+	if err := p.CheckEVMChainID(big.NewInt(232)); err == nil {
+		t.Fatalf("Expected chain id 232 not to match")
+	}
+}
@@ -0,0 +1,120 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// Process exit codes of the avs, operator and challenger binaries.
+const (
+	// ExitOK is returned when the process stopped on its own or on a signal and drained cleanly.
+	ExitOK = 0
+	// ExitFailure is returned when the process stopped because of an error.
+	ExitFailure = 1
+	// ExitDrainTimeout is returned when in-flight work was abandoned at the drain deadline.
+	ExitDrainTimeout = 2
+	// ExitForced is returned when a second signal interrupted the drain.
+	ExitForced = 130
+)
+
+// DefaultShutdownTimeout is how long in-flight work may drain after a shutdown signal
+// when shutdown_timeout is not configured.
+const DefaultShutdownTimeout = 30 * time.Second
+
+// ShutdownTimeout returns the configured drain timeout, given in seconds, or DefaultShutdownTimeout if it is 0.
+func ShutdownTimeout(seconds uint64) time.Duration {
+	if seconds == 0 {
+		return DefaultShutdownTimeout
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// ErrDrainTimeout is returned by Shutdown when in-flight work did not finish before the deadline.
+var ErrDrainTimeout = errors.New("in-flight work did not drain before the shutdown deadline")
+
+// SignalContext returns a context that is canceled on the first SIGINT or SIGTERM.
+// A second signal exits the process immediately with ExitForced.
+func SignalContext(parent context.Context) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(parent)
+	signals := make(chan os.Signal, 2)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+	go func() {
+		select {
+		case <-signals:
+			cancel()
+		case <-ctx.Done():
+			signal.Stop(signals)
+			return
+		}
+		<-signals
+		os.Exit(ExitForced)
+	}()
+	return ctx, cancel
+}
+
+// Drainer tracks in-flight work so that a shutdown can wait for it up to a deadline.
+// Work is stopped through the root context, but transactions already sent should use
+// Context, which stays alive after the root context is canceled until the drain deadline,
+// so a receipt being waited for is not abandoned halfway.
+type Drainer struct {
+	wg     sync.WaitGroup
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+// NewDrainer returns a Drainer with nothing in flight.
+func NewDrainer() *Drainer {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Drainer{ctx: ctx, cancel: cancel}
+}
+
+// Go runs f in a goroutine the drain waits for.
+func (d *Drainer) Go(f func()) {
+	d.wg.Add(1)
+	go func() {
+		defer d.wg.Done()
+		f()
+	}()
+}
+
+// Context returns the context in-flight transactions are sent with.
+func (d *Drainer) Context() context.Context {
+	return d.ctx
+}
+
+// Wait waits for the work started with Go for at most timeout. On timeout the drain
+// context is canceled and ErrDrainTimeout is returned.
+func (d *Drainer) Wait(timeout time.Duration) error {
+	done := make(chan struct{})
+	go func() {
+		d.wg.Wait()
+		close(done)
+	}()
+	defer d.cancel()
+	select {
+	case <-done:
+		return nil
+	case <-time.After(timeout):
+		return ErrDrainTimeout
+	}
+}
+
+// ExitCode maps the outcome of a service run and its shutdown to a process exit code.
+// A run that ended because ctx was canceled counts as a clean stop.
+func ExitCode(runErr, shutdownErr error) int {
+	switch {
+	case errors.Is(shutdownErr, ErrDrainTimeout):
+		return ExitDrainTimeout
+	case runErr != nil && !errors.Is(runErr, context.Canceled):
+		return ExitFailure
+	case shutdownErr != nil:
+		return ExitFailure
+	default:
+		return ExitOK
+	}
+}
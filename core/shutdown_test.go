@@ -0,0 +1,48 @@
+package core_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/imua-xyz/imua-avs/core"
+)
+
+func TestDrainerWait(t *testing.T) {
+	d := core.NewDrainer()
+	release := make(chan struct{})
+	d.Go(func() { <-release })
+	d.Go(func() {})
+	close(release)
+	if err := d.Wait(time.Second); err != nil {
+		t.Fatalf("Expected work to drain, but got %v", err)
+	}
+	if d.Context().Err() == nil {
+		t.Fatalf("Expected drain context to be canceled after the drain")
+	}
+
+	d = core.NewDrainer()
+	d.Go(func() { <-d.Context().Done() })
+	if err := d.Wait(10 * time.Millisecond); !errors.Is(err, core.ErrDrainTimeout) {
+		t.Fatalf("Expected ErrDrainTimeout, but got %v", err)
+	}
+}
+
+func TestExitCode(t *testing.T) {
+	cases := []struct {
+		runErr, shutdownErr error
+		want                int
+	}{
+		{nil, nil, core.ExitOK},
+		{context.Canceled, nil, core.ExitOK},
+		{errors.New("boom"), nil, core.ExitFailure},
+		{context.Canceled, core.ErrDrainTimeout, core.ExitDrainTimeout},
+		{nil, errors.New("close failed"), core.ExitFailure},
+	}
+	for _, c := range cases {
+		if got := core.ExitCode(c.runErr, c.shutdownErr); got != c.want {
+			t.Errorf("ExitCode(%v, %v) = %d, want %d", c.runErr, c.shutdownErr, got, c.want)
+		}
+	}
+}
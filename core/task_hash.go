@@ -0,0 +1,44 @@
+package core
+
+import (
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+var (
+	taskType, _ = abi.NewType("tuple", "struct", []abi.ArgumentMarshaling{
+		{Name: "name", Type: "string"},
+		{Name: "taskId", Type: "uint64"},
+		{Name: "numberToBeSquared", Type: "uint64"},
+		{Name: "taskResponsePeriod", Type: "uint64"},
+		{Name: "taskChallengePeriod", Type: "uint64"},
+		{Name: "thresholdPercentage", Type: "uint8"},
+		{Name: "taskStatisticalPeriod", Type: "uint64"},
+	})
+
+	taskArgs = abi.Arguments{
+		{Type: taskType, Name: "Task"},
+	}
+)
+
+// Task mirrors the Task struct of AvsServiceContract.
+type Task struct {
+	Name                  string
+	TaskId                uint64
+	NumberToBeSquared     uint64
+	TaskResponsePeriod    uint64
+	TaskChallengePeriod   uint64
+	ThresholdPercentage   uint8
+	TaskStatisticalPeriod uint64
+}
+
+// TaskHash returns keccak256(abi.encode(task)), the hash createNewTask stores in the precompile.
+// createNewTask hashes the task before the precompile assigns its ID, so TaskId must be 0 to
+// reproduce the stored hash.
+func TaskHash(task Task) ([]byte, error) {
+	packed, err := taskArgs.Pack(task)
+	if err != nil {
+		return nil, err
+	}
+	return crypto.Keccak256(packed), nil
+}
@@ -0,0 +1,53 @@
+package core_test
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/imua-xyz/imua-avs/core"
+)
+
+func word(v uint64) []byte {
+	return common.LeftPadBytes(new(big.Int).SetUint64(v).Bytes(), 32)
+}
+
+func TestTaskHash(t *testing.T) {
+	task := core.Task{
+		Name:                  "square:aB3dE",
+		NumberToBeSquared:     7,
+		TaskResponsePeriod:    3,
+		TaskChallengePeriod:   5,
+		ThresholdPercentage:   80,
+		TaskStatisticalPeriod: 2,
+	}
+	// abi.encode of a dynamic struct: the offset of the tuple, its head with the offset of
+	// the string relative to the tuple, then the string length and its right padded bytes
+	var encoded []byte
+	for _, w := range [][]byte{
+		word(0x20),
+		word(7 * 32), word(0), word(7), word(3), word(5), word(80), word(2),
+		word(uint64(len(task.Name))), common.RightPadBytes([]byte(task.Name), 32),
+	} {
+		encoded = append(encoded, w...)
+	}
+
+	hash, err := core.TaskHash(task)
+	if err != nil {
+		t.Fatalf("Error hashing task: %v", err)
+	}
+	if want := crypto.Keccak256(encoded); !bytes.Equal(hash, want) {
+		t.Errorf("TaskHash = %x, want %x", hash, want)
+	}
+
+	task.NumberToBeSquared = 8
+	tampered, err := core.TaskHash(task)
+	if err != nil {
+		t.Fatalf("Error hashing task: %v", err)
+	}
+	if bytes.Equal(hash, tampered) {
+		t.Error("TaskHash does not depend on the task input")
+	}
+}
@@ -0,0 +1,102 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+const (
+	// TaskResponseV1 is abi.encode(uint64 taskID, uint64 numberSquared), the layout
+	// AvsServiceContract decodes when a challenge is raised.
+	TaskResponseV1 uint8 = 1
+	// TaskResponseV2 is abi.encode(uint8 2, uint64 taskID, uint256 numberSquared). Its
+	// leading version word and its length of three words tell it apart from TaskResponseV1.
+	TaskResponseV2 uint8 = 2
+
+	wordSize = 32
+)
+
+var taskResponseV2Args = abi.Arguments{
+	{Name: "version", Type: mustNewType("uint8")},
+	{Name: "taskID", Type: mustNewType("uint64")},
+	{Name: "numberSquared", Type: mustNewType("uint256")},
+}
+
+// BigTaskResponse is a task response whose NumberSquared may exceed uint64.
+type BigTaskResponse struct {
+	TaskID        uint64
+	NumberSquared *big.Int
+}
+
+// Version returns the encoding of r: TaskResponseV1 while NumberSquared fits in uint64, so
+// that those responses stay byte compatible with what operators have always signed, and
+// TaskResponseV2 otherwise. Every value has exactly one encoding, which operators must agree on.
+func (r BigTaskResponse) Version() uint8 {
+	if r.NumberSquared.IsUint64() {
+		return TaskResponseV1
+	}
+	return TaskResponseV2
+}
+
+// EncodeBigTaskResponse encodes r with the encoding of r.Version.
+func EncodeBigTaskResponse(r BigTaskResponse) ([]byte, error) {
+	switch {
+	case r.NumberSquared == nil:
+		return nil, errors.New("task response has no numberSquared")
+	case r.NumberSquared.Sign() < 0 || r.NumberSquared.BitLen() > 256:
+		return nil, fmt.Errorf("numberSquared %s does not fit in uint256", r.NumberSquared)
+	}
+	if r.Version() == TaskResponseV1 {
+		return AbiEncode(TaskResponse{TaskID: r.TaskID, NumberSquared: r.NumberSquared.Uint64()})
+	}
+	return taskResponseV2Args.Pack(TaskResponseV2, r.TaskID, r.NumberSquared)
+}
+
+// DecodeBigTaskResponse decodes a response of either encoding and returns it with its version.
+// A TaskResponseV2 whose value would have been encoded as TaskResponseV1 is rejected.
+func DecodeBigTaskResponse(data []byte) (BigTaskResponse, uint8, error) {
+	switch len(data) {
+	case 2 * wordSize:
+		res, err := AbiDecode(data)
+		if err != nil {
+			return BigTaskResponse{}, 0, err
+		}
+		return BigTaskResponse{TaskID: res.TaskID, NumberSquared: new(big.Int).SetUint64(res.NumberSquared)}, TaskResponseV1, nil
+	case 3 * wordSize:
+		if version := new(big.Int).SetBytes(data[:wordSize]); !version.IsUint64() || version.Uint64() != uint64(TaskResponseV2) {
+			return BigTaskResponse{}, 0, fmt.Errorf("unknown task response version %s", version)
+		}
+		values, err := taskResponseV2Args.UnpackValues(data)
+		if err != nil {
+			return BigTaskResponse{}, 0, err
+		}
+		taskID, ok := values[1].(uint64)
+		if !ok {
+			return BigTaskResponse{}, 0, fmt.Errorf("unexpected taskID type %T", values[1])
+		}
+		numberSquared, ok := values[2].(*big.Int)
+		if !ok {
+			return BigTaskResponse{}, 0, fmt.Errorf("unexpected numberSquared type %T", values[2])
+		}
+		if numberSquared.IsUint64() {
+			return BigTaskResponse{}, 0, fmt.Errorf("numberSquared %s fits in uint64 and must be encoded as version %d", numberSquared, TaskResponseV1)
+		}
+		return BigTaskResponse{TaskID: taskID, NumberSquared: numberSquared}, TaskResponseV2, nil
+	default:
+		return BigTaskResponse{}, 0, fmt.Errorf("task response of %d bytes matches no known version", len(data))
+	}
+}
+
+// GetBigTaskResponseDigest returns the hash of the encoded response, which is what operators sign over,
+// and the encoded response.
+func GetBigTaskResponseDigest(r BigTaskResponse) ([32]byte, []byte, error) {
+	data, err := EncodeBigTaskResponse(r)
+	if err != nil {
+		return [32]byte{}, nil, err
+	}
+	return crypto.Keccak256Hash(data), data, nil
+}
@@ -0,0 +1,83 @@
+package core_test
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+
+	"github.com/imua-xyz/imua-avs/core"
+)
+
+func TestBigTaskResponse(t *testing.T) {
+	// responses that fit in uint64 keep the legacy encoding
+	small := core.BigTaskResponse{TaskID: 10, NumberSquared: big.NewInt(56169)}
+	_, legacy, err := core.GetTaskResponseDigestEncodeByAbi(core.TaskResponse{TaskID: 10, NumberSquared: 56169})
+	if err != nil {
+		t.Fatalf("Error encoding legacy response: %v", err)
+	}
+	encoded, err := core.EncodeBigTaskResponse(small)
+	if err != nil {
+		t.Fatalf("Error encoding response: %v", err)
+	}
+	if small.Version() != core.TaskResponseV1 || !bytes.Equal(encoded, legacy) {
+		t.Fatalf("Expected version 1 response %x, but got version %d %x", legacy, small.Version(), encoded)
+	}
+
+	large := core.BigTaskResponse{TaskID: 11, NumberSquared: new(big.Int).Lsh(big.NewInt(1), 64)}
+	encoded, err = core.EncodeBigTaskResponse(large)
+	if err != nil {
+		t.Fatalf("Error encoding response: %v", err)
+	}
+	if large.Version() != core.TaskResponseV2 || len(encoded) != 96 || encoded[31] != core.TaskResponseV2 {
+		t.Fatalf("Expected a version 2 response of 96 bytes, but got version %d %x", large.Version(), encoded)
+	}
+	decoded, version, err := core.DecodeBigTaskResponse(encoded)
+	if err != nil || version != core.TaskResponseV2 || decoded.TaskID != 11 || decoded.NumberSquared.Cmp(large.NumberSquared) != 0 {
+		t.Fatalf("Expected %+v as version 2, but got %+v as version %d (err %v)", large, decoded, version, err)
+	}
+
+	invalid := map[string][]byte{
+		"unknown length":  encoded[:64+16],
+		"unknown version": append([]byte{}, encoded...),
+		"non canonical":   append([]byte{}, encoded...),
+	}
+	invalid["unknown version"][31] = 3
+	copy(invalid["non canonical"][64:], make([]byte, 32))
+	invalid["non canonical"][95] = 7
+	for name, data := range invalid {
+		if _, _, err := core.DecodeBigTaskResponse(data); err == nil {
+			t.Errorf("Expected %s response to be rejected", name)
+		}
+	}
+	if _, err := core.EncodeBigTaskResponse(core.BigTaskResponse{NumberSquared: big.NewInt(-1)}); err == nil {
+		t.Error("Expected negative response to be rejected")
+	}
+}
+
+func TestSquareTaskOverflow(t *testing.T) {
+	square, err := core.LookupTaskType(core.SquareTaskType)
+	if err != nil {
+		t.Fatalf("Error looking up square task: %v", err)
+	}
+	// 2^32 squared is 2^64, which wraps to 0 in uint64
+	input := uint64(1) << 32
+	output, err := square.Solve(7, input)
+	if err != nil {
+		t.Fatalf("Error solving task: %v", err)
+	}
+	want := new(big.Int).Lsh(big.NewInt(1), 64)
+	if got, ok := output.(*big.Int); !ok || got.Cmp(want) != 0 {
+		t.Fatalf("Expected %s, but got %v", want, output)
+	}
+	response, err := square.EncodeResponse(7, output)
+	if err != nil {
+		t.Fatalf("Error encoding response: %v", err)
+	}
+	if ok, err := square.Verify(7, input, response); err != nil || !ok {
+		t.Fatalf("Expected valid response, but got %v (err %v)", ok, err)
+	}
+	wrapped, _ := square.EncodeResponse(7, uint64(0))
+	if ok, _ := square.Verify(7, input, wrapped); ok {
+		t.Fatal("Expected the wrapped uint64 answer to be rejected")
+	}
+}
@@ -0,0 +1,116 @@
+package core
+
+import (
+	"fmt"
+	"math/big"
+	"math/rand"
+)
+
+const (
+	// SquareTaskType is the name of the built-in task type that squares a number.
+	SquareTaskType = "square"
+	// maxNumberToBeSquared bounds the inputs generated for new square tasks.
+	maxNumberToBeSquared = 500
+)
+
+func init() {
+	MustRegisterTaskType(squareTask{})
+}
+
+// squareTask asks operators to square numberToBeSquared.
+// Its response is the ABI encoded TaskResponse, which is what AvsServiceContract decodes
+// when a challenge is raised, or a TaskResponseV2 for squares that do not fit in uint64.
+type squareTask struct{}
+
+func (squareTask) Name() string {
+	return SquareTaskType
+}
+
+func (squareTask) NewInput() (interface{}, error) {
+	return uint64(rand.Intn(maxNumberToBeSquared)), nil
+}
+
+func (squareTask) EncodeInput(input interface{}) (uint64, error) {
+	number, ok := input.(uint64)
+	if !ok {
+		return 0, fmt.Errorf("square task: unexpected input type %T", input)
+	}
+	return number, nil
+}
+
+func (squareTask) DecodeInput(raw uint64) (interface{}, error) {
+	return raw, nil
+}
+
+// Solve squares the input. The square is a uint64 while it fits and a *big.Int otherwise,
+// inputs of 2^32 and above would wrap around in uint64.
+func (squareTask) Solve(_ uint64, input interface{}) (interface{}, error) {
+	number, ok := input.(uint64)
+	if !ok {
+		return nil, fmt.Errorf("square task: unexpected input type %T", input)
+	}
+	n := new(big.Int).SetUint64(number)
+	return squareOutput(n.Mul(n, n)), nil
+}
+
+// EncodeResponse encodes a uint64 or *big.Int square, as TaskResponseV1 while it fits in
+// uint64 and as TaskResponseV2 otherwise.
+func (squareTask) EncodeResponse(taskID uint64, output interface{}) ([]byte, error) {
+	numberSquared, err := squareBig(output)
+	if err != nil {
+		return nil, err
+	}
+	_, data, err := GetBigTaskResponseDigest(BigTaskResponse{
+		TaskID:        taskID,
+		NumberSquared: numberSquared,
+	})
+	return data, err
+}
+
+func (squareTask) DecodeResponse(data []byte) (uint64, interface{}, error) {
+	res, _, err := DecodeBigTaskResponse(data)
+	if err != nil {
+		return 0, nil, err
+	}
+	return res.TaskID, squareOutput(res.NumberSquared), nil
+}
+
+func (t squareTask) Verify(taskID uint64, input interface{}, response []byte) (bool, error) {
+	expected, err := t.Solve(taskID, input)
+	if err != nil {
+		return false, err
+	}
+	resTaskID, output, err := t.DecodeResponse(response)
+	if err != nil {
+		return false, err
+	}
+	expectedBig, err := squareBig(expected)
+	if err != nil {
+		return false, err
+	}
+	outputBig, err := squareBig(output)
+	if err != nil {
+		return false, err
+	}
+	return resTaskID == taskID && outputBig.Cmp(expectedBig) == 0, nil
+}
+
+// squareOutput returns n as a uint64 if it fits, as a *big.Int otherwise.
+func squareOutput(n *big.Int) interface{} {
+	if n.IsUint64() {
+		return n.Uint64()
+	}
+	return n
+}
+
+// squareBig converts a square task output to a *big.Int.
+func squareBig(output interface{}) (*big.Int, error) {
+	switch v := output.(type) {
+	case uint64:
+		return new(big.Int).SetUint64(v), nil
+	case *big.Int:
+		return v, nil
+	default:
+		return nil, fmt.Errorf("square task: unexpected output type %T", output)
+	}
+}
@@ -0,0 +1,128 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+const (
+	// DefaultTaskType is the task type assumed for tasks whose name does not
+	// carry a task type prefix, i.e. every task created before the registry existed.
+	DefaultTaskType = SquareTaskType
+	// TaskNameSeparator separates the task type from the rest of the task name.
+	TaskNameSeparator = ":"
+)
+
+// TaskType is a kind of work the AVS hands out to operators.
+// The avs binary uses it to create tasks, the operator to solve them and the
+// challenger to check the answers, so a new kind of work only needs a new
+// TaskType registered in all three binaries.
+//
+// The on-chain task only carries a uint64 input (the numberToBeSquared field of
+// the TaskCreated event), while the response is arbitrary bytes. Inputs and
+// outputs are passed around as interface{} and each implementation asserts its
+// own concrete types.
+type TaskType interface {
+	// Name identifies the task type. It prefixes the on-chain task name.
+	Name() string
+	// NewInput generates the input of a new task.
+	NewInput() (interface{}, error)
+	// EncodeInput converts a task input to the value stored on chain.
+	EncodeInput(input interface{}) (uint64, error)
+	// DecodeInput converts the value stored on chain back to a task input.
+	DecodeInput(raw uint64) (interface{}, error)
+	// Solve computes the answer of a task.
+	Solve(taskID uint64, input interface{}) (interface{}, error)
+	// EncodeResponse serializes an answer into the bytes operators sign and submit.
+	EncodeResponse(taskID uint64, output interface{}) ([]byte, error)
+	// DecodeResponse parses bytes produced by EncodeResponse.
+	DecodeResponse(data []byte) (uint64, interface{}, error)
+	// Verify reports whether the submitted response is a correct answer for the task.
+	Verify(taskID uint64, input interface{}, response []byte) (bool, error)
+}
+
+var (
+	taskTypesMu sync.RWMutex
+	taskTypes   = map[string]TaskType{}
+)
+
+// RegisterTaskType makes a task type available to LookupTaskType.
+// It returns an error if the name is empty, contains TaskNameSeparator or is already taken.
+func RegisterTaskType(t TaskType) error {
+	if t == nil {
+		return errors.New("task type is nil")
+	}
+	name := t.Name()
+	if name == "" {
+		return errors.New("task type name is empty")
+	}
+	if strings.Contains(name, TaskNameSeparator) {
+		return fmt.Errorf("task type name %q must not contain %q", name, TaskNameSeparator)
+	}
+	taskTypesMu.Lock()
+	defer taskTypesMu.Unlock()
+	if _, ok := taskTypes[name]; ok {
+		return fmt.Errorf("task type %q is already registered", name)
+	}
+	taskTypes[name] = t
+	return nil
+}
+
+// MustRegisterTaskType is like RegisterTaskType but panics on error.
+// It is meant to be called from init functions.
+func MustRegisterTaskType(t TaskType) {
+	if err := RegisterTaskType(t); err != nil {
+		panic(err)
+	}
+}
+
+// LookupTaskType returns the task type registered under name.
+// An empty name selects DefaultTaskType.
+func LookupTaskType(name string) (TaskType, error) {
+	if name == "" {
+		name = DefaultTaskType
+	}
+	taskTypesMu.RLock()
+	defer taskTypesMu.RUnlock()
+	t, ok := taskTypes[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown task type %q", name)
+	}
+	return t, nil
+}
+
+// TaskTypeNames returns the names of all registered task types in sorted order.
+func TaskTypeNames() []string {
+	taskTypesMu.RLock()
+	defer taskTypesMu.RUnlock()
+	names := make([]string, 0, len(taskTypes))
+	for name := range taskTypes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// FormatTaskName builds the on-chain task name for a task of the given type,
+// e.g. "square:aB3dE".
+func FormatTaskName(taskType, name string) string {
+	return taskType + TaskNameSeparator + name
+}
+
+// TaskTypeFromName extracts the task type from an on-chain task name.
+// Names without a task type prefix belong to DefaultTaskType.
+func TaskTypeFromName(taskName string) string {
+	taskType, _, found := strings.Cut(taskName, TaskNameSeparator)
+	if !found || taskType == "" {
+		return DefaultTaskType
+	}
+	return taskType
+}
+
+// TaskTypeForName looks up the task type of an on-chain task by its name.
+func TaskTypeForName(taskName string) (TaskType, error) {
+	return LookupTaskType(TaskTypeFromName(taskName))
+}
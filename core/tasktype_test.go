@@ -0,0 +1,91 @@
+package core_test
+
+import (
+	"testing"
+
+	"github.com/imua-xyz/imua-avs/core"
+)
+
+func TestTaskTypeFromName(t *testing.T) {
+	cases := map[string]string{
+		"":              core.DefaultTaskType,
+		"aB3dE":         core.DefaultTaskType,
+		"square:aB3dE":  core.SquareTaskType,
+		"custom:x:y":    "custom",
+		":missing-type": core.DefaultTaskType,
+	}
+	for name, expected := range cases {
+		if got := core.TaskTypeFromName(name); got != expected {
+			t.Fatalf("TaskTypeFromName(%q): expected %q, but got %q", name, expected, got)
+		}
+	}
+	if got := core.TaskTypeFromName(core.FormatTaskName("custom", "abc")); got != "custom" {
+		t.Fatalf("Expected custom, but got %q", got)
+	}
+}
+
+func TestRegisterTaskType(t *testing.T) {
+	if err := core.RegisterTaskType(nil); err == nil {
+		t.Fatalf("Expected error registering a nil task type")
+	}
+	square, err := core.LookupTaskType(core.SquareTaskType)
+	if err != nil {
+		t.Fatalf("Error looking up square task type: %v", err)
+	}
+	if err := core.RegisterTaskType(square); err == nil {
+		t.Fatalf("Expected error registering %q twice", core.SquareTaskType)
+	}
+	if _, err := core.LookupTaskType("does-not-exist"); err == nil {
+		t.Fatalf("Expected error looking up an unknown task type")
+	}
+	defaultType, err := core.LookupTaskType("")
+	if err != nil || defaultType.Name() != core.DefaultTaskType {
+		t.Fatalf("Expected empty name to select %q, but got %v (err %v)", core.DefaultTaskType, defaultType, err)
+	}
+}
+
+func TestSquareTaskType(t *testing.T) {
+	square, err := core.LookupTaskType(core.SquareTaskType)
+	if err != nil {
+		t.Fatalf("Error looking up square task type: %v", err)
+	}
+	input, err := square.DecodeInput(237)
+	if err != nil {
+		t.Fatalf("Error decoding input: %v", err)
+	}
+	output, err := square.Solve(10, input)
+	if err != nil {
+		t.Fatalf("Error solving task: %v", err)
+	}
+	if output != uint64(56169) {
+		t.Fatalf("Expected 56169, but got %v", output)
+	}
+	response, err := square.EncodeResponse(10, output)
+	if err != nil {
+		t.Fatalf("Error encoding response: %v", err)
+	}
+	// the response must stay byte compatible with the digest operators have always signed
+	_, expected, err := core.GetTaskResponseDigestEncodeByAbi(core.TaskResponse{TaskID: 10, NumberSquared: 56169})
+	if err != nil {
+		t.Fatalf("Error encoding task response: %v", err)
+	}
+	if string(response) != string(expected) {
+		t.Fatalf("Expected response %x, but got %x", expected, response)
+	}
+	taskID, decoded, err := square.DecodeResponse(response)
+	if err != nil || taskID != 10 || decoded != output {
+		t.Fatalf("Expected (10, %v), but got (%d, %v) err %v", output, taskID, decoded, err)
+	}
+
+	ok, err := square.Verify(10, input, response)
+	if err != nil || !ok {
+		t.Fatalf("Expected valid response, but got %v (err %v)", ok, err)
+	}
+	wrong, _ := square.EncodeResponse(10, uint64(56168))
+	if ok, _ := square.Verify(10, input, wrong); ok {
+		t.Fatalf("Expected wrong answer to be rejected")
+	}
+	if ok, _ := square.Verify(11, input, response); ok {
+		t.Fatalf("Expected response for another task to be rejected")
+	}
+}
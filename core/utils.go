@@ -2,15 +2,11 @@ package core
 
 import (
 	"bytes"
-	"encoding/hex"
 	"encoding/json"
 	"fmt"
-	"github.com/cosmos/btcutil/bech32"
 	"github.com/ethereum/go-ethereum/accounts/abi"
-	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/crypto"
 	"gopkg.in/yaml.v3"
-	"math/big"
 	"os"
 	"path/filepath"
 	"regexp"
@@ -35,6 +31,13 @@ type TaskResponse struct {
 
 const BLSMessageToSign = "BLS12-381 Signed Message\nChainIDWithoutRevision: %s\nAccAddressBech32: %s"
 
+const (
+	// TaskPhaseOne is the first submission phase, operators only submit their BLS signature.
+	TaskPhaseOne uint8 = 1
+	// TaskPhaseTwo is the second submission phase, operators reveal the signed task response.
+	TaskPhaseTwo uint8 = 2
+)
+
 func AbiEncode(resp TaskResponse) ([]byte, error) {
 
 	abiArgs := abi.Arguments{
@@ -42,10 +45,7 @@ func AbiEncode(resp TaskResponse) ([]byte, error) {
 		{Name: "numberSquared", Type: mustNewType("uint64")},
 	}
 
-	taskID := new(big.Int).SetUint64(resp.TaskID)
-	numSquared := new(big.Int).SetUint64(resp.NumberSquared)
-
-	return abiArgs.Pack(taskID, numSquared)
+	return abiArgs.Pack(resp.TaskID, resp.NumberSquared)
 }
 
 func AbiDecode(data []byte) (TaskResponse, error) {
@@ -59,14 +59,18 @@ func AbiDecode(data []byte) (TaskResponse, error) {
 		return TaskResponse{}, err
 	}
 
-	var (
-		taskID, _    = values[0].(*big.Int)
-		numSquare, _ = values[1].(*big.Int)
-	)
+	taskID, ok := values[0].(uint64)
+	if !ok {
+		return TaskResponse{}, fmt.Errorf("unexpected taskID type %T", values[0])
+	}
+	numSquare, ok := values[1].(uint64)
+	if !ok {
+		return TaskResponse{}, fmt.Errorf("unexpected numberSquared type %T", values[1])
+	}
 
 	return TaskResponse{
-		TaskID:        taskID.Uint64(),
-		NumberSquared: numSquare.Uint64(),
+		TaskID:        taskID,
+		NumberSquared: numSquare,
 	}, nil
 }
 
@@ -161,33 +165,6 @@ func GetFileInCurrentDirectory(filename string) (string, error) {
 
 	return fullPath, nil
 }
-func ConvertToEthAddresses(strArray []string) []common.Address {
-	var ethAddresses []common.Address
-
-	if len(strArray) > 0 {
-		for _, str := range strArray {
-			address := common.HexToAddress(str)
-			ethAddresses = append(ethAddresses, address)
-		}
-	}
-
-	return ethAddresses
-}
-func SwitchEthAddressToImAddress(ethAddress string) (string, error) {
-	b, err := hex.DecodeString(ethAddress[2:])
-	if err != nil {
-		return "", fmt.Errorf("failed to decode eth address: %w", err)
-	}
-
-	// Generate im address
-	bech32Prefix := "im"
-	imAddress, err := bech32.EncodeFromBase256(bech32Prefix, b)
-	if err != nil {
-		return "", fmt.Errorf("failed to encode bech32 address: %w", err)
-	}
-
-	return imAddress, nil
-}
 
 // ChainIDWithoutRevision returns the chainID without the revision number.
 // For example, "imuachaintestnet_233-1" returns "imuachaintestnet_233".
@@ -0,0 +1,165 @@
+package operator
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	sdklogging "github.com/imua-xyz/imua-avs-sdk/logging"
+	"github.com/imua-xyz/imua-avs/core"
+	chain "github.com/imua-xyz/imua-avs/core/chainio"
+	"github.com/imua-xyz/imua-avs/core/health"
+	"github.com/imua-xyz/imua-avs/operator/shadow"
+	"github.com/imua-xyz/imua-avs/types"
+)
+
+// avsService is the state the operator keeps for one of the AVSs it serves. The ECDSA and BLS
+// identity, the transaction queue, the journal and the metrics are shared through the embedded
+// Operator, everything bound to the AVS contract is kept here.
+type avsService struct {
+	*Operator
+	// name labels the AVS in logs, metrics and health checks
+	name   string
+	logger sdklogging.Logger
+
+	avsAddr         common.Address
+	avsReader       chain.AvsReader
+	avsWriter       chain.AvsWriter
+	epochIdentifier string
+	// taskType is the only task type handled when set
+	taskType string
+	// follower handles the logs of the AVS contract, backfilling the TaskCreated events missed
+	// since its checkpoint on restart
+	follower *chain.LogFollower
+	// subscriptionUp is set while the log subscription is established
+	subscriptionUp health.Flag
+	// events and backfillEvents route the logs of the subscription and of the backfill
+	events         *chain.EventRouter
+	backfillEvents *chain.EventRouter
+}
+
+// avsConfigs returns the AVSs configured for the operator, avs_address alone if no list is given,
+// with the default checkpoint paths of mode filled in.
+func avsConfigs(c types.NodeConfig, mode shadow.Mode) ([]types.AVSConfig, error) {
+	if len(c.OperatorAVSs) == 0 {
+		checkpointPath := c.OperatorCheckpointPath
+		if checkpointPath == "" {
+			checkpointPath = modePath(defaultCheckpointPath, mode)
+		}
+		return []types.AVSConfig{{
+			Address:        c.AVSAddress,
+			CheckpointPath: checkpointPath,
+		}}, nil
+	}
+	configs := make([]types.AVSConfig, len(c.OperatorAVSs))
+	seen := map[string]bool{}
+	for i, a := range c.OperatorAVSs {
+		if !common.IsHexAddress(a.Address) {
+			return nil, fmt.Errorf("operator_avs_list entry %d has invalid address %q", i, a.Address)
+		}
+		if a.Name == "" {
+			a.Name = common.HexToAddress(a.Address).Hex()
+		}
+		if a.CheckpointPath == "" {
+			a.CheckpointPath = modePath(avsCheckpointPath(a.Address), mode)
+		}
+		for _, key := range []string{"name " + a.Name, "address " + common.HexToAddress(a.Address).Hex(), "checkpoint_path " + a.CheckpointPath} {
+			if seen[key] {
+				return nil, fmt.Errorf("operator_avs_list has duplicate %s", key)
+			}
+			seen[key] = true
+		}
+		if a.TaskType != "" {
+			if _, err := core.LookupTaskType(a.TaskType); err != nil {
+				return nil, fmt.Errorf("operator_avs_list entry %s: %w", a.Name, err)
+			}
+		}
+		configs[i] = a
+	}
+	return configs, nil
+}
+
+// avsCheckpointPath is the default checkpoint path of an AVS of operator_avs_list.
+func avsCheckpointPath(address string) string {
+	return fmt.Sprintf("data/operator_checkpoint_%s.json", strings.ToLower(common.HexToAddress(address).Hex()))
+}
+
+// newAvsService binds the operator to the AVS contract of c.
+func newAvsService(o *Operator, c types.AVSConfig) (*avsService, error) {
+	avsAddr := common.HexToAddress(c.Address)
+	name := c.Name
+	if name == "" {
+		name = avsAddr.Hex()
+	}
+	logger := core.LoggerWithTags(o.logger, "avs", name)
+
+	avsReader, err := chain.BuildChainReader(avsAddr, o.ethClient, logger)
+	if err != nil {
+		logger.Error("Cannot create avsReader", "err", err)
+		return nil, err
+	}
+	avsWriter, err := chain.BuildChainWriter(avsAddr, o.ethClient, logger, o.txMgr)
+	if err != nil {
+		logger.Error("Cannot create avsWriter", "err", err)
+		return nil, err
+	}
+	epochIdentifier, err := avsReader.GetAVSEpochIdentifier(&bind.CallOpts{}, avsAddr.String())
+	if err != nil {
+		logger.Error("Cannot GetAVSEpochIdentifier", "err", err)
+		return nil, err
+	}
+	checkpoint, err := chain.OpenLogCheckpoint(c.CheckpointPath)
+	if err != nil {
+		logger.Error("Cannot open log checkpoint", "path", c.CheckpointPath, "err", err)
+		return nil, err
+	}
+	s := &avsService{
+		Operator:        o,
+		name:            name,
+		logger:          logger,
+		avsAddr:         avsAddr,
+		avsReader:       avsReader,
+		avsWriter:       avsWriter,
+		epochIdentifier: epochIdentifier,
+		taskType:        c.TaskType,
+	}
+	s.follower = chain.NewLogFollower(o.ethClient, o.config.EthWsUrl, ethereum.FilterQuery{
+		Addresses: []common.Address{avsAddr},
+	}, checkpoint, logger)
+	s.follower.StartAt(o.config.BackfillStartBlock)
+	s.follower.OnConnectionChange(func(up bool) {
+		s.subscriptionUp.Set(up)
+		s.metrics.SetSubscriptionUp(name, up)
+	})
+	s.events = s.newEventRouter(false)
+	s.backfillEvents = s.newEventRouter(true)
+	return s, nil
+}
+
+// AVSs returns the addresses of the AVSs the operator serves.
+func (o *Operator) AVSs() []common.Address {
+	addrs := make([]common.Address, len(o.services))
+	for i, s := range o.services {
+		addrs[i] = s.avsAddr
+	}
+	return addrs
+}
+
+// service returns the served AVS selected by name or address. An empty selector
+// selects the only AVS, and is an error if the operator serves several.
+func (o *Operator) service(selector string) (*avsService, error) {
+	if selector == "" {
+		if len(o.services) == 1 {
+			return o.services[0], nil
+		}
+		return nil, fmt.Errorf("the operator serves %d AVSs, select one by name or address", len(o.services))
+	}
+	for _, s := range o.services {
+		if s.name == selector || (common.IsHexAddress(selector) && s.avsAddr == common.HexToAddress(selector)) {
+			return s, nil
+		}
+	}
+	return nil, fmt.Errorf("the operator does not serve an AVS %q", selector)
+}
@@ -0,0 +1,102 @@
+package operator
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/imua-xyz/imua-avs/operator/shadow"
+	"github.com/imua-xyz/imua-avs/types"
+)
+
+func TestAvsConfigs(t *testing.T) {
+	const (
+		avsA = "0x10Ed22D975453A5D4031440D51624552E4f204D5"
+		avsB = "0x3e108c058e8066DA635321Dc3018294cA82ddEdf"
+	)
+	cases := []struct {
+		name string
+		c    types.NodeConfig
+		mode shadow.Mode
+		want []types.AVSConfig
+	}{
+		{
+			name: "avs_address alone",
+			c:    types.NodeConfig{AVSAddress: avsA},
+			mode: shadow.Live,
+			want: []types.AVSConfig{{Address: avsA, CheckpointPath: defaultCheckpointPath}},
+		},
+		{
+			name: "avs_address with its checkpoint",
+			c:    types.NodeConfig{AVSAddress: avsA, OperatorCheckpointPath: "state/checkpoint.json"},
+			mode: shadow.Live,
+			want: []types.AVSConfig{{Address: avsA, CheckpointPath: "state/checkpoint.json"}},
+		},
+		{
+			name: "avs_address in shadow mode",
+			c:    types.NodeConfig{AVSAddress: avsA},
+			mode: shadow.Shadow,
+			want: []types.AVSConfig{{Address: avsA, CheckpointPath: "data/operator_checkpoint_shadow.json"}},
+		},
+		{
+			name: "list with defaults",
+			c: types.NodeConfig{AVSAddress: avsB, OperatorAVSs: []types.AVSConfig{
+				{Address: strings.ToLower(avsA)},
+				{Name: "second", Address: avsB, TaskType: "square", CheckpointPath: "state/second.json"},
+			}},
+			mode: shadow.Live,
+			want: []types.AVSConfig{
+				{Name: avsA, Address: strings.ToLower(avsA), CheckpointPath: "data/operator_checkpoint_" + strings.ToLower(avsA) + ".json"},
+				{Name: "second", Address: avsB, TaskType: "square", CheckpointPath: "state/second.json"},
+			},
+		},
+		{
+			name: "list in dry-run mode",
+			c:    types.NodeConfig{OperatorAVSs: []types.AVSConfig{{Name: "first", Address: avsA}}},
+			mode: shadow.DryRun,
+			want: []types.AVSConfig{
+				{Name: "first", Address: avsA, CheckpointPath: "data/operator_checkpoint_" + strings.ToLower(avsA) + "_dry-run.json"},
+			},
+		},
+	}
+	for _, c := range cases {
+		got, err := avsConfigs(c.c, c.mode)
+		if err != nil {
+			t.Errorf("%s: unexpected error %v", c.name, err)
+			continue
+		}
+		if len(got) != len(c.want) {
+			t.Errorf("%s: expected %d AVSs, but got %+v", c.name, len(c.want), got)
+			continue
+		}
+		for i := range got {
+			if got[i] != c.want[i] {
+				t.Errorf("%s: expected entry %d to be %+v, but got %+v", c.name, i, c.want[i], got[i])
+			}
+		}
+	}
+}
+
+func TestAvsConfigsRejected(t *testing.T) {
+	const avsA = "0x10Ed22D975453A5D4031440D51624552E4f204D5"
+	cases := []struct {
+		name    string
+		avss    []types.AVSConfig
+		wantErr string
+	}{
+		{"duplicate address", []types.AVSConfig{{Name: "a", Address: avsA}, {Name: "b", Address: avsA}}, "duplicate address"},
+		{"duplicate address in another case", []types.AVSConfig{{Address: avsA}, {Name: "b", Address: strings.ToLower(avsA)}}, "duplicate address"},
+		{"duplicate name", []types.AVSConfig{{Name: "a", Address: avsA}, {Name: "a", Address: "0x3e108c058e8066DA635321Dc3018294cA82ddEdf"}}, "duplicate name"},
+		{"duplicate checkpoint", []types.AVSConfig{
+			{Name: "a", Address: avsA, CheckpointPath: "state/c.json"},
+			{Name: "b", Address: "0x3e108c058e8066DA635321Dc3018294cA82ddEdf", CheckpointPath: "state/c.json"},
+		}, "duplicate checkpoint_path"},
+		{"invalid address", []types.AVSConfig{{Name: "a", Address: "0x1234"}}, "invalid address"},
+		{"unknown task type", []types.AVSConfig{{Name: "a", Address: avsA, TaskType: "cube"}}, "entry a"},
+	}
+	for _, c := range cases {
+		_, err := avsConfigs(types.NodeConfig{OperatorAVSs: c.avss}, shadow.Live)
+		if err == nil || !strings.Contains(err.Error(), c.wantErr) {
+			t.Errorf("%s: expected an error containing %q, but got %v", c.name, c.wantErr, err)
+		}
+	}
+}
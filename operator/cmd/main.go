@@ -3,11 +3,14 @@ package main
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"log"
 	"os"
 
 	"github.com/urfave/cli"
 
+	"github.com/imua-xyz/imua-avs/core"
 	"github.com/imua-xyz/imua-avs/core/config"
 	"github.com/imua-xyz/imua-avs/operator"
 	"github.com/imua-xyz/imua-avs/types"
@@ -15,9 +18,14 @@ import (
 	sdkutils "github.com/imua-xyz/imua-avs-sdk/utils"
 )
 
+var modeFlag = cli.StringFlag{
+	Name:  "mode",
+	Usage: "live, or dry-run or shadow to sign tasks without submitting and compare the responses with the chain, overrides operator_mode",
+}
+
 func main() {
 	app := cli.NewApp()
-	app.Flags = []cli.Flag{config.FileFlag}
+	app.Flags = []cli.Flag{config.FileFlag, modeFlag}
 	app.Name = "hello-world-demo-operator"
 	app.Usage = "hello-world-demo Operator"
 	app.Description = "Service that operator listens to AVS contract events, signs tasks, and submits results."
@@ -37,6 +45,9 @@ func operatorMain(ctx *cli.Context) error {
 	if err != nil {
 		return err
 	}
+	if mode := ctx.GlobalString(modeFlag.Name); mode != "" {
+		nodeConfig.OperatorMode = mode
+	}
 	configJson, err := json.MarshalIndent(nodeConfig, "", "  ")
 	if err != nil {
 		log.Fatalf(err.Error())
@@ -50,12 +61,16 @@ func operatorMain(ctx *cli.Context) error {
 	}
 	log.Println("initialized operator")
 
+	runCtx, stop := core.SignalContext(context.Background())
+	defer stop()
+
 	log.Println("starting operator")
-	err = operator.Start(context.Background())
-	if err != nil {
-		return err
+	runErr := operator.Start(runCtx)
+	shutdownErr := operator.Shutdown(core.ShutdownTimeout(nodeConfig.ShutdownTimeout))
+	if code := core.ExitCode(runErr, shutdownErr); code != core.ExitOK {
+		return cli.NewExitError(fmt.Sprintf("operator stopped: %v", errors.Join(runErr, shutdownErr)), code)
 	}
-	log.Println("started operator")
+	log.Println("operator stopped")
 
 	return nil
 }
@@ -0,0 +1,94 @@
+package operator
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/imua-xyz/imua-avs/core/health"
+	"github.com/imua-xyz/imua-avs/operator/shadow"
+)
+
+// healthChecks are the readiness conditions the node api reports for the operator. The checks
+// of an AVS get its name appended to their id when the operator serves several AVSs.
+func (o *Operator) healthChecks() []health.Check {
+	checks := []health.Check{
+		{
+			ID:          "chain-head",
+			Name:        "Chain head",
+			Description: "the node answers and its head block is recent",
+			Probe:       health.HeadRecent(o.ethClient, health.MaxHeadAge(o.config.HealthMaxHeadAge)),
+		},
+		{
+			ID:          "keys",
+			Name:        "Keys",
+			Description: "the ECDSA key is loaded and the BLS signer can sign",
+			Probe:       o.blsSigner.Ready,
+		},
+	}
+	for _, s := range o.services {
+		suffix := ""
+		if len(o.services) > 1 {
+			suffix = "-" + s.name
+		}
+		checks = append(checks, health.Check{
+			ID:          "ws-subscription" + suffix,
+			Name:        "Log subscription",
+			Description: "websocket subscription to the logs of AVS " + s.name + " is established",
+			Probe:       s.subscriptionUp.Probe("log subscription is not established"),
+		})
+		if o.mode == shadow.DryRun {
+			// a dry run needs neither registration nor a registered key
+			continue
+		}
+		checks = append(checks,
+			health.Check{
+				ID:          "operator-registration" + suffix,
+				Name:        "Operator registration",
+				Description: "the operator is registered on chain and opted in to AVS " + s.name,
+				Probe:       s.checkRegistration,
+			},
+			health.Check{
+				ID:          "bls-pubkey" + suffix,
+				Name:        "BLS public key",
+				Description: "the BLS public key registered for AVS " + s.name + " is the one the operator signs with",
+				Probe:       s.checkBLSPublicKey,
+			},
+		)
+	}
+	return checks
+}
+
+func (o *avsService) checkRegistration(ctx context.Context) error {
+	registered, err := o.avsReader.IsOperator(&bind.CallOpts{Context: ctx}, o.operatorAddr.String())
+	if err != nil {
+		return fmt.Errorf("failed to check operator registration: %w", err)
+	}
+	if !registered {
+		return errors.New("operator is not registered on chain")
+	}
+	optedIn, err := o.isOptedIn(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to check AVS opt-in: %w", err)
+	}
+	if !optedIn {
+		return errors.New("operator is not opted in to the AVS")
+	}
+	return nil
+}
+
+func (o *avsService) checkBLSPublicKey(ctx context.Context) error {
+	pubKey, err := o.avsReader.GetRegisteredPubkey(&bind.CallOpts{Context: ctx}, o.operatorAddr.String(), o.avsAddr.String())
+	if err != nil {
+		return fmt.Errorf("failed to get registered BLS public key: %w", err)
+	}
+	if len(pubKey) == 0 {
+		return errors.New("no BLS public key is registered")
+	}
+	if !bytes.Equal(pubKey, o.blsSigner.PublicKey()) {
+		return errors.New("registered BLS public key differs from the one of the BLS signer")
+	}
+	return nil
+}
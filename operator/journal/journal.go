@@ -0,0 +1,343 @@
+// Package journal keeps an on-disk record of the lifecycle of every task an operator handles,
+// so that a restarted operator can resume unfinished tasks from the phase it reached.
+//
+// The journal is an append-only file with one JSON record per line. Every write is fsynced
+// before it is acknowledged. When the journal is opened it is replayed, a torn last line left
+// by a crash is dropped, and the file is compacted to the latest record of each task.
+//
+// Only one process owns a journal: Open takes an exclusive lock on a lock file next to it and
+// fails with ErrLocked while another process holds it. Other processes, such as the cli, use
+// OpenReadOnly, which replays the journal without compacting or writing it.
+package journal
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// ErrLocked is returned by Open if another process owns the journal.
+var ErrLocked = errors.New("journal is locked by another process")
+
+// TaskState is the lifecycle state of a task. States only move forward.
+type TaskState uint8
+
+const (
+	// TaskSeen means the TaskCreated event was received.
+	TaskSeen TaskState = iota + 1
+	// TaskSigned means the task response was computed and signed.
+	TaskSigned
+	// TaskPhaseOneSent means the phase one submission (signature only) is on chain.
+	TaskPhaseOneSent
+	// TaskPhaseTwoSent means the phase two submission (response and signature) is on chain.
+	TaskPhaseTwoSent
+	// TaskFinished means nothing is left to do for the task.
+	TaskFinished
+)
+
+var taskStateNames = map[TaskState]string{
+	TaskSeen:         "seen",
+	TaskSigned:       "signed",
+	TaskPhaseOneSent: "phase1_sent",
+	TaskPhaseTwoSent: "phase2_sent",
+	TaskFinished:     "finished",
+}
+
+func (s TaskState) String() string {
+	if name, ok := taskStateNames[s]; ok {
+		return name
+	}
+	return fmt.Sprintf("unknown(%d)", uint8(s))
+}
+
+func (s TaskState) MarshalText() ([]byte, error) {
+	name, ok := taskStateNames[s]
+	if !ok {
+		return nil, fmt.Errorf("unknown task state %d", uint8(s))
+	}
+	return []byte(name), nil
+}
+
+func (s *TaskState) UnmarshalText(text []byte) error {
+	for state, name := range taskStateNames {
+		if name == string(text) {
+			*s = state
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown task state %q", text)
+}
+
+// TaskRecord is the journaled state of one task.
+type TaskRecord struct {
+	TaskAddress common.Address `json:"task_address"`
+	TaskID      uint64         `json:"task_id"`
+	State       TaskState      `json:"state"`
+	// Name and Input are copied from the TaskCreated event so that a task seen but not yet
+	// signed before a crash can be solved again.
+	Name         string        `json:"name,omitempty"`
+	Input        uint64        `json:"input"`
+	TaskResponse hexutil.Bytes `json:"task_response,omitempty"`
+	BlsSignature hexutil.Bytes `json:"bls_signature,omitempty"`
+	UpdatedAt    time.Time     `json:"updated_at"`
+}
+
+type taskKey struct {
+	taskAddress common.Address
+	taskID      uint64
+}
+
+func keyOf(r TaskRecord) taskKey {
+	return taskKey{taskAddress: r.TaskAddress, taskID: r.TaskID}
+}
+
+// TaskJournal is safe for concurrent use.
+type TaskJournal struct {
+	mu       sync.Mutex
+	path     string
+	file     *os.File
+	lock     *os.File
+	readOnly bool
+	tasks    map[taskKey]TaskRecord
+}
+
+// Open opens the journal at path for the process that owns it, creating it if needed. It locks
+// the journal, replays its records and compacts the file.
+func Open(path string) (*TaskJournal, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create journal directory: %w", err)
+	}
+	lock, err := lockFile(path + ".lock")
+	if err != nil {
+		return nil, err
+	}
+	j := &TaskJournal{
+		path:  path,
+		lock:  lock,
+		tasks: map[taskKey]TaskRecord{},
+	}
+	if err := j.replay(); err != nil {
+		lock.Close()
+		return nil, err
+	}
+	if err := j.compact(); err != nil {
+		lock.Close()
+		return nil, err
+	}
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o600)
+	if err != nil {
+		lock.Close()
+		return nil, fmt.Errorf("failed to open journal: %w", err)
+	}
+	j.file = file
+	return j, nil
+}
+
+// OpenReadOnly replays the journal at path without locking, compacting or writing it, so it
+// can be read while the owning process keeps appending. A missing journal reads as empty.
+// Record fails on a read-only journal.
+func OpenReadOnly(path string) (*TaskJournal, error) {
+	j := &TaskJournal{
+		path:     path,
+		readOnly: true,
+		tasks:    map[taskKey]TaskRecord{},
+	}
+	if err := j.replay(); err != nil {
+		return nil, err
+	}
+	return j, nil
+}
+
+// lockFile takes an exclusive lock on the file at path, which is never renamed, unlike the
+// journal itself. The lock is released when the file is closed or the process exits.
+func lockFile(path string) (*os.File, error) {
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open journal lock: %w", err)
+	}
+	if err := syscall.Flock(int(file.Fd()), syscall.LOCK_EX|syscall.LOCK_NB); err != nil {
+		file.Close()
+		if errors.Is(err, syscall.EWOULDBLOCK) {
+			return nil, fmt.Errorf("%w: %s", ErrLocked, path)
+		}
+		return nil, fmt.Errorf("failed to lock journal: %w", err)
+	}
+	return file, nil
+}
+
+func (j *TaskJournal) replay() error {
+	data, err := os.ReadFile(filepath.Clean(j.path))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read journal: %w", err)
+	}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	line := 0
+	for scanner.Scan() {
+		line++
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+		var r TaskRecord
+		if err := json.Unmarshal(scanner.Bytes(), &r); err != nil {
+			// a torn write left by a crash can only be the unterminated last line,
+			// that record was never acknowledged so it is safe to drop
+			if !scanner.Scan() && !bytes.HasSuffix(data, []byte("\n")) {
+				break
+			}
+			return fmt.Errorf("corrupted journal record at %s:%d: %w", j.path, line, err)
+		}
+		j.apply(r)
+	}
+	return scanner.Err()
+}
+
+// compact rewrites the journal with the latest record of each task.
+func (j *TaskJournal) compact() error {
+	tmp := j.path + ".tmp"
+	file, err := os.OpenFile(tmp, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to compact journal: %w", err)
+	}
+	w := bufio.NewWriter(file)
+	for _, r := range j.sortedRecords(func(TaskRecord) bool { return true }) {
+		line, err := json.Marshal(r)
+		if err != nil {
+			file.Close()
+			return err
+		}
+		w.Write(line)
+		w.WriteByte('\n')
+	}
+	if err := w.Flush(); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Sync(); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp, j.path)
+}
+
+// apply keeps the most advanced state of a task and fills in fields a later record omits.
+func (j *TaskJournal) apply(r TaskRecord) {
+	prev, ok := j.tasks[keyOf(r)]
+	if ok {
+		if r.State < prev.State {
+			r.State = prev.State
+		}
+		if r.Name == "" {
+			r.Name = prev.Name
+		}
+		if r.Input == 0 {
+			r.Input = prev.Input
+		}
+		if len(r.TaskResponse) == 0 {
+			r.TaskResponse = prev.TaskResponse
+		}
+		if len(r.BlsSignature) == 0 {
+			r.BlsSignature = prev.BlsSignature
+		}
+	}
+	j.tasks[keyOf(r)] = r
+}
+
+// Record durably appends a record and returns once it is on disk.
+// Fields left empty keep the value of the previous record of the same task.
+func (j *TaskJournal) Record(r TaskRecord) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.readOnly {
+		return fmt.Errorf("journal %s is opened read-only", j.path)
+	}
+	if j.file == nil {
+		return fmt.Errorf("journal %s is closed", j.path)
+	}
+	if r.UpdatedAt.IsZero() {
+		r.UpdatedAt = time.Now().UTC()
+	}
+	line, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	if _, err := j.file.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("failed to append journal record: %w", err)
+	}
+	if err := j.file.Sync(); err != nil {
+		return fmt.Errorf("failed to sync journal: %w", err)
+	}
+	j.apply(r)
+	return nil
+}
+
+// Advance moves a task to state, keeping everything else recorded so far.
+func (j *TaskJournal) Advance(taskAddress common.Address, taskID uint64, state TaskState) error {
+	return j.Record(TaskRecord{TaskAddress: taskAddress, TaskID: taskID, State: state})
+}
+
+// Get returns the latest record of a task.
+func (j *TaskJournal) Get(taskAddress common.Address, taskID uint64) (TaskRecord, bool) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	r, ok := j.tasks[taskKey{taskAddress: taskAddress, taskID: taskID}]
+	return r, ok
+}
+
+// Unfinished returns the tasks that have not reached TaskFinished, ordered by task address and ID.
+func (j *TaskJournal) Unfinished() []TaskRecord {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.sortedRecords(func(r TaskRecord) bool { return r.State < TaskFinished })
+}
+
+func (j *TaskJournal) sortedRecords(keep func(TaskRecord) bool) []TaskRecord {
+	records := make([]TaskRecord, 0, len(j.tasks))
+	for _, r := range j.tasks {
+		if keep(r) {
+			records = append(records, r)
+		}
+	}
+	sort.Slice(records, func(a, b int) bool {
+		if c := bytes.Compare(records[a].TaskAddress[:], records[b].TaskAddress[:]); c != 0 {
+			return c < 0
+		}
+		return records[a].TaskID < records[b].TaskID
+	})
+	return records
+}
+
+// Close flushes and closes the journal file and releases its lock.
+func (j *TaskJournal) Close() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.file == nil {
+		return nil
+	}
+	err := j.file.Sync()
+	if cerr := j.file.Close(); err == nil {
+		err = cerr
+	}
+	j.file = nil
+	if cerr := j.lock.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
@@ -0,0 +1,209 @@
+package journal_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/imua-xyz/imua-avs/operator/journal"
+)
+
+var taskAddr = common.HexToAddress("0x10Ed22D975453A5D4031440D51624552E4f204D5")
+
+func TestJournalResume(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "journal.jsonl")
+	j, err := journal.Open(path)
+	if err != nil {
+		t.Fatalf("Error opening journal: %v", err)
+	}
+	records := []journal.TaskRecord{
+		{TaskAddress: taskAddr, TaskID: 1, State: journal.TaskSeen, Name: "square:abc", Input: 7},
+		{TaskAddress: taskAddr, TaskID: 1, State: journal.TaskSigned, TaskResponse: []byte{1, 2}, BlsSignature: []byte{3}},
+		{TaskAddress: taskAddr, TaskID: 2, State: journal.TaskSeen, Name: "square:def", Input: 9},
+		{TaskAddress: taskAddr, TaskID: 3, State: journal.TaskSigned},
+	}
+	for _, r := range records {
+		if err := j.Record(r); err != nil {
+			t.Fatalf("Error recording task: %v", err)
+		}
+	}
+	if err := j.Advance(taskAddr, 1, journal.TaskPhaseOneSent); err != nil {
+		t.Fatalf("Error advancing task: %v", err)
+	}
+	if err := j.Advance(taskAddr, 3, journal.TaskFinished); err != nil {
+		t.Fatalf("Error advancing task: %v", err)
+	}
+	// states never move backwards
+	if err := j.Advance(taskAddr, 1, journal.TaskSeen); err != nil {
+		t.Fatalf("Error advancing task: %v", err)
+	}
+	if err := j.Close(); err != nil {
+		t.Fatalf("Error closing journal: %v", err)
+	}
+
+	j, err = journal.Open(path)
+	if err != nil {
+		t.Fatalf("Error reopening journal: %v", err)
+	}
+	defer j.Close()
+	unfinished := j.Unfinished()
+	if len(unfinished) != 2 {
+		t.Fatalf("Expected 2 unfinished tasks, but got %d", len(unfinished))
+	}
+	first := unfinished[0]
+	if first.TaskID != 1 || first.State != journal.TaskPhaseOneSent || first.Name != "square:abc" ||
+		first.Input != 7 || string(first.TaskResponse) != string([]byte{1, 2}) || string(first.BlsSignature) != string([]byte{3}) {
+		t.Fatalf("Unexpected resumed record: %+v", first)
+	}
+	if unfinished[1].TaskID != 2 || unfinished[1].State != journal.TaskSeen {
+		t.Fatalf("Unexpected resumed record: %+v", unfinished[1])
+	}
+	if r, ok := j.Get(taskAddr, 3); !ok || r.State != journal.TaskFinished {
+		t.Fatalf("Expected task 3 to be finished, but got %+v", r)
+	}
+
+	// reopening compacts the file to one line per task
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Error reading journal: %v", err)
+	}
+	if lines := strings.Count(string(data), "\n"); lines != 3 {
+		t.Fatalf("Expected 3 compacted records, but got %d", lines)
+	}
+}
+
+func TestJournalTornWrite(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "journal.jsonl")
+	j, err := journal.Open(path)
+	if err != nil {
+		t.Fatalf("Error opening journal: %v", err)
+	}
+	if err := j.Record(journal.TaskRecord{TaskAddress: taskAddr, TaskID: 5, State: journal.TaskSigned}); err != nil {
+		t.Fatalf("Error recording task: %v", err)
+	}
+	j.Close()
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		t.Fatalf("Error opening journal file: %v", err)
+	}
+	f.WriteString(`{"task_address":"0x10ed22d975453a5d4031440d51624552e4f204d5","task_id":5,"state":"phase1`)
+	f.Close()
+
+	j, err = journal.Open(path)
+	if err != nil {
+		t.Fatalf("Expected torn last record to be dropped, but got: %v", err)
+	}
+	defer j.Close()
+	if r, _ := j.Get(taskAddr, 5); r.State != journal.TaskSigned {
+		t.Fatalf("Expected task 5 to stay signed, but got %s", r.State)
+	}
+}
+
+func TestJournalCorruption(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "journal.jsonl")
+	content := "not json\n" + `{"task_address":"0x10ed22d975453a5d4031440d51624552e4f204d5","task_id":5,"state":"signed"}` + "\n"
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("Error writing journal: %v", err)
+	}
+	if _, err := journal.Open(path); err == nil {
+		t.Fatalf("Expected corrupted journal to be rejected")
+	}
+}
+
+func TestJournalLock(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "journal.jsonl")
+	j, err := journal.Open(path)
+	if err != nil {
+		t.Fatalf("Error opening journal: %v", err)
+	}
+	for _, state := range []journal.TaskState{journal.TaskSeen, journal.TaskSigned} {
+		if err := j.Advance(taskAddr, 1, state); err != nil {
+			t.Fatalf("Error advancing task: %v", err)
+		}
+	}
+	if _, err := journal.Open(path); !errors.Is(err, journal.ErrLocked) {
+		t.Fatalf("Expected ErrLocked while the journal is owned, but got %v", err)
+	}
+
+	// a read-only journal sees the records but leaves the file alone
+	before, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Error reading journal: %v", err)
+	}
+	ro, err := journal.OpenReadOnly(path)
+	if err != nil {
+		t.Fatalf("Error opening journal read-only: %v", err)
+	}
+	if r, ok := ro.Get(taskAddr, 1); !ok || r.State != journal.TaskSigned {
+		t.Fatalf("Expected task 1 to be signed, but got %+v", r)
+	}
+	if err := ro.Advance(taskAddr, 1, journal.TaskFinished); err == nil {
+		t.Fatalf("Expected a read-only journal to reject records")
+	}
+	if after, _ := os.ReadFile(path); string(after) != string(before) {
+		t.Fatalf("Expected the read-only open not to rewrite the journal")
+	}
+
+	// the owner keeps appending to the same file
+	if err := j.Advance(taskAddr, 1, journal.TaskFinished); err != nil {
+		t.Fatalf("Error advancing task: %v", err)
+	}
+	if err := j.Close(); err != nil {
+		t.Fatalf("Error closing journal: %v", err)
+	}
+	j, err = journal.Open(path)
+	if err != nil {
+		t.Fatalf("Expected the lock to be released on close, but got %v", err)
+	}
+	defer j.Close()
+	if r, _ := j.Get(taskAddr, 1); r.State != journal.TaskFinished {
+		t.Fatalf("Expected task 1 to be finished, but got %s", r.State)
+	}
+}
+
+func TestJournalKeysByTaskAddressAndID(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "journal.jsonl")
+	j, err := journal.Open(path)
+	if err != nil {
+		t.Fatalf("Error opening journal: %v", err)
+	}
+	// two AVSs served by one operator number their tasks independently
+	otherAddr := common.HexToAddress("0x3e108c058e8066DA635321Dc3018294cA82ddEdf")
+	for _, r := range []journal.TaskRecord{
+		{TaskAddress: taskAddr, TaskID: 1, State: journal.TaskSigned, Name: "square:abc", Input: 7},
+		{TaskAddress: otherAddr, TaskID: 1, State: journal.TaskSeen, Name: "square:def", Input: 9},
+	} {
+		if err := j.Record(r); err != nil {
+			t.Fatalf("Error recording task: %v", err)
+		}
+	}
+	if err := j.Advance(taskAddr, 1, journal.TaskFinished); err != nil {
+		t.Fatalf("Error advancing task: %v", err)
+	}
+	if err := j.Close(); err != nil {
+		t.Fatalf("Error closing journal: %v", err)
+	}
+
+	j, err = journal.Open(path)
+	if err != nil {
+		t.Fatalf("Error reopening journal: %v", err)
+	}
+	defer j.Close()
+	if r, ok := j.Get(taskAddr, 1); !ok || r.State != journal.TaskFinished || r.Name != "square:abc" {
+		t.Errorf("Expected task 1 of %s to be finished, but got %+v", taskAddr.Hex(), r)
+	}
+	if r, ok := j.Get(otherAddr, 1); !ok || r.State != journal.TaskSeen || r.Name != "square:def" || r.Input != 9 {
+		t.Errorf("Expected task 1 of %s to be untouched, but got %+v", otherAddr.Hex(), r)
+	}
+	if _, ok := j.Get(otherAddr, 2); ok {
+		t.Errorf("Expected no task 2 of %s", otherAddr.Hex())
+	}
+	unfinished := j.Unfinished()
+	if len(unfinished) != 1 || unfinished[0].TaskAddress != otherAddr || unfinished[0].TaskID != 1 {
+		t.Errorf("Expected only task 1 of %s to be unfinished, but got %+v", otherAddr.Hex(), unfinished)
+	}
+}
@@ -0,0 +1,41 @@
+package operator
+
+import (
+	"testing"
+
+	"github.com/imua-xyz/imua-avs/operator/shadow"
+	"github.com/imua-xyz/imua-avs/types"
+)
+
+func TestModePathsKeepLiveStateApart(t *testing.T) {
+	if got := modePath(defaultJournalPath, shadow.Live); got != defaultJournalPath {
+		t.Errorf("expected the live journal at %s, but got %s", defaultJournalPath, got)
+	}
+	if got := modePath(defaultJournalPath, shadow.Shadow); got != "data/operator_journal_shadow.jsonl" {
+		t.Errorf("expected the shadow journal at data/operator_journal_shadow.jsonl, but got %s", got)
+	}
+	if got := modePath(defaultCheckpointPath, shadow.DryRun); got != "data/operator_checkpoint_dry-run.json" {
+		t.Errorf("expected the dry-run checkpoint at data/operator_checkpoint_dry-run.json, but got %s", got)
+	}
+
+	avs := "0x0000000000000000000000000000000000000001"
+	for _, mode := range []shadow.Mode{shadow.DryRun, shadow.Shadow} {
+		configs, err := avsConfigs(types.NodeConfig{AVSAddress: avs}, mode)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := checkModePaths(mode, modePath(defaultJournalPath, mode), configs); err != nil {
+			t.Errorf("%s mode: expected its default paths to be accepted, but got %v", mode, err)
+		}
+		if err := checkModePaths(mode, "./"+defaultJournalPath, configs); err == nil {
+			t.Errorf("%s mode: expected the live journal to be refused", mode)
+		}
+		configs[0].CheckpointPath = defaultCheckpointPath
+		if err := checkModePaths(mode, modePath(defaultJournalPath, mode), configs); err == nil {
+			t.Errorf("%s mode: expected the live checkpoint to be refused", mode)
+		}
+	}
+	if err := checkModePaths(shadow.Live, defaultJournalPath, []types.AVSConfig{{Address: avs, CheckpointPath: defaultCheckpointPath}}); err != nil {
+		t.Errorf("expected the live operator to use the live paths, but got %v", err)
+	}
+}
@@ -3,27 +3,28 @@ package operator
 import (
 	"context"
 	"fmt"
-	"github.com/ethereum/go-ethereum"
-	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
-	ethtypes "github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
-	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/imua-xyz/imua-avs-sdk/client/txmgr"
-	"github.com/imua-xyz/imua-avs-sdk/crypto/bls"
 	sdklogging "github.com/imua-xyz/imua-avs-sdk/logging"
-	"github.com/imua-xyz/imua-avs-sdk/nodeapi"
 	"github.com/imua-xyz/imua-avs-sdk/signer"
 	avs "github.com/imua-xyz/imua-avs/contracts/bindings/avs"
 	"github.com/imua-xyz/imua-avs/core"
+	"github.com/imua-xyz/imua-avs/core/blssigner"
 	chain "github.com/imua-xyz/imua-avs/core/chainio"
 	"github.com/imua-xyz/imua-avs/core/chainio/eth"
+	"github.com/imua-xyz/imua-avs/core/health"
+	"github.com/imua-xyz/imua-avs/core/metrics"
+	"github.com/imua-xyz/imua-avs/core/network"
+	"github.com/imua-xyz/imua-avs/operator/journal"
+	"github.com/imua-xyz/imua-avs/operator/shadow"
 	"github.com/imua-xyz/imua-avs/types"
-	blscommon "github.com/prysmaticlabs/prysm/v5/crypto/bls/common"
 	"math/big"
 	"os"
-	"strconv"
+	"path/filepath"
+	"strings"
+	"sync"
 	"time"
 )
 
@@ -32,42 +33,102 @@ const (
 	SemVer     = "0.0.1"
 	maxRetries = 80
 	retryDelay = 1 * time.Second
+	// defaultJournalPath is used when operator_journal_path is not configured
+	defaultJournalPath = "data/operator_journal.jsonl"
+	// defaultCheckpointPath is used when operator_checkpoint_path is not configured
+	defaultCheckpointPath = "data/operator_checkpoint.json"
+	// defaultShadowReportPath is used when shadow_report_path is not configured
+	defaultShadowReportPath = "data/operator_shadow_report.jsonl"
 )
 
+// modePath returns the default path of a file of the operator in mode: path itself when live,
+// else path with the mode appended to its name, so dry-run and shadow keep their own state.
+func modePath(path string, mode shadow.Mode) string {
+	if mode.Submits() {
+		return path
+	}
+	ext := filepath.Ext(path)
+	return strings.TrimSuffix(path, ext) + "_" + string(mode) + ext
+}
+
+// checkModePaths returns an error if the operator in dry-run or shadow mode would use the
+// journal or a checkpoint of the live operator: finishing tasks it only compared would make a
+// live operator on the same files skip them.
+func checkModePaths(mode shadow.Mode, journalPath string, configs []types.AVSConfig) error {
+	if mode.Submits() {
+		return nil
+	}
+	live := map[string]bool{filepath.Clean(defaultJournalPath): true, filepath.Clean(defaultCheckpointPath): true}
+	for _, c := range configs {
+		live[filepath.Clean(avsCheckpointPath(c.Address))] = true
+	}
+	paths := map[string]string{"operator_journal_path": journalPath}
+	for _, c := range configs {
+		paths["checkpoint_path of "+c.Address] = c.CheckpointPath
+	}
+	for key, path := range paths {
+		if live[filepath.Clean(path)] {
+			return fmt.Errorf("%s mode would use %s of the live operator (%s), configure a file of its own", mode, path, key)
+		}
+	}
+	return nil
+}
+
 type Operator struct {
-	config      types.NodeConfig
-	logger      sdklogging.Logger
-	ethClient   eth.EthClient
-	nodeApi     *nodeapi.NodeApi
-	avsWriter   chain.AvsWriter
-	avsReader   chain.ChainReader
-	ethWsClient *ethclient.Client
-
-	blsKeypair   blscommon.SecretKey
+	config    types.NodeConfig
+	network   network.Profile
+	logger    sdklogging.Logger
+	ethClient eth.EthClient
+	// txMgr sends the transactions of the operator account, for every AVS
+	txMgr txmgr.TxManager
+	// chainReader reads the operator state of the AVS manager precompile that does not depend on an AVS
+	chainReader chain.ChainReader
+	// services are the AVSs the operator serves
+	services []*avsService
+
+	blsSigner    blssigner.BLSSigner
 	operatorAddr common.Address
 	// receive new tasks in this chan (typically from listening to onchain event)
 	newTaskCreatedChan chan *avs.ContracthelloWorldTaskCreated
-	// needed when opting in to avs (allow this service manager contract to slash operator)
-	avsAddr         common.Address
-	epochIdentifier string
-	contractABI     abi.ABI
+	// journal records the lifecycle of every task of every AVS so unfinished ones survive a restart
+	journal *journal.TaskJournal
+	// drainer tracks the task submissions a shutdown waits for
+	drainer *core.Drainer
+	// stakingClient funds deposits and delegations, built on first use
+	stakingClient *chain.StakingClient
+	metrics       *metrics.OperatorMetrics
+	// mode is live unless task responses are only compared with the chain
+	mode shadow.Mode
+	// shadowReport receives the comparisons of dry-run and shadow mode
+	shadowReport *shadow.Writer
 }
 
+// NewOperatorFromConfig builds the operator process, which owns the task journal and, in dry-run
+// and shadow mode, the shadow report.
 func NewOperatorFromConfig(c types.NodeConfig) (*Operator, error) {
+	return newOperatorFromConfig(c, false)
+}
+
+// NewCliOperatorFromConfig builds an operator for the cli commands. The task journal is opened
+// read-only and the shadow report is not opened, so the commands can run next to the operator
+// process that owns them.
+func NewCliOperatorFromConfig(c types.NodeConfig) (*Operator, error) {
+	return newOperatorFromConfig(c, true)
+}
+
+func newOperatorFromConfig(c types.NodeConfig, cli bool) (*Operator, error) {
 	var logLevel sdklogging.LogLevel
 	if c.Production {
 		logLevel = sdklogging.Production
 	} else {
 		logLevel = sdklogging.Development
 	}
-	logger, err := sdklogging.NewZapLogger(logLevel)
+	logger, err := core.NewZapLogger(logLevel)
 	if err != nil {
 		return nil, err
 	}
 
-	// Setup Node Api
-	nodeApi := nodeapi.NewNodeApi(AvsName, SemVer, c.NodeApiIpPortAddress, logger)
-
+	operatorMetrics := metrics.NewOperatorMetrics()
 	var ethRpcClient eth.EthClient
 	ethRpcClient, err = eth.NewClient(c.EthRpcUrl)
 	if err != nil {
@@ -75,19 +136,22 @@ func NewOperatorFromConfig(c types.NodeConfig) (*Operator, error) {
 
 		return nil, err
 	}
-	ethWsClient, err := ethclient.Dial(c.EthWsUrl)
-	if err != nil {
-		logger.Error("Cannot create ws eth client", "err", err)
-		return nil, err
-	}
+	// task info reads, submissions and their receipts are timed in rpc_latency_seconds
+	ethRpcClient = eth.NewObservedClient(ethRpcClient, operatorMetrics.ObserveRPC)
 
 	blsKeyPassword, ok := os.LookupEnv("OPERATOR_BLS_KEY_PASSWORD")
 	if !ok {
 		logger.Info("OPERATOR_BLS_KEY_PASSWORD env var not set. using empty string")
 	}
-	blsKeyPair, err := bls.ReadPrivateKeyFromFile(c.BlsPrivateKeyStorePath, blsKeyPassword)
+	blsSigner, err := blssigner.SignerFromConfig(blssigner.Config{
+		Type:            c.BlsSigner,
+		KeystorePath:    c.BlsPrivateKeyStorePath,
+		Password:        blsKeyPassword,
+		RemoteURL:       c.BlsRemoteSignerUrl,
+		RemotePublicKey: c.BlsRemoteSignerPublicKey,
+	})
 	if err != nil {
-		logger.Error("Cannot parse bls private key", "err", err)
+		logger.Error("Cannot create bls signer", "err", err)
 		return nil, err
 	}
 
@@ -96,6 +160,15 @@ func NewOperatorFromConfig(c types.NodeConfig) (*Operator, error) {
 		logger.Error("Cannot get chainId", "err", err)
 		return nil, err
 	}
+	profile, err := network.Load(c.Network)
+	if err != nil {
+		logger.Error("Cannot load network profile", "network", c.Network, "err", err)
+		return nil, err
+	}
+	if err := chain.VerifyNetwork(context.Background(), profile, ethRpcClient); err != nil {
+		logger.Error("Node does not match the network profile", "network", profile.Name, "err", err)
+		return nil, err
+	}
 
 	ecdsaKeyPassword, ok := os.LookupEnv("OPERATOR_ECDSA_KEY_PASSWORD")
 	if !ok {
@@ -121,58 +194,88 @@ func NewOperatorFromConfig(c types.NodeConfig) (*Operator, error) {
 	if c.OperatorAddress != operatorSender.String() {
 		logger.Error("operatorSender is not equal OperatorAddress")
 	}
-	txMgr := txmgr.NewSimpleTxManager(ethRpcClient, logger, signer, common.HexToAddress(c.OperatorAddress))
+	txMgr := chain.NewTxQueue(ethRpcClient, logger, signer, common.HexToAddress(c.OperatorAddress))
 
-	avsReader, _ := chain.BuildChainReader(
-		common.HexToAddress(c.AVSAddress),
+	mode, err := shadow.ParseMode(c.OperatorMode)
+	if err != nil {
+		logger.Error("Invalid operator_mode", "err", err)
+		return nil, err
+	}
+	configs, err := avsConfigs(c, mode)
+	if err != nil {
+		logger.Error("Invalid operator_avs_list", "err", err)
+		return nil, err
+	}
+	chainReader, err := chain.BuildChainReader(
+		common.HexToAddress(configs[0].Address),
 		ethRpcClient,
 		logger)
-
-	avsWriter, _ := chain.BuildChainWriter(
-		common.HexToAddress(c.AVSAddress),
-		ethRpcClient,
-		logger,
-		txMgr)
-
 	if err != nil {
-		logger.Error("Cannot create AvsSubscriber", "err", err)
+		logger.Error("Cannot create chainReader", "err", err)
 		return nil, err
 	}
-	epochIdentifier, err := avsReader.GetAVSEpochIdentifier(&bind.CallOpts{}, c.AVSAddress)
-	if err != nil {
-		logger.Error("Cannot GetAVSEpochIdentifier", "err", err)
+	journalPath := c.OperatorJournalPath
+	if journalPath == "" {
+		journalPath = modePath(defaultJournalPath, mode)
+	}
+	if err := checkModePaths(mode, journalPath, configs); err != nil {
+		logger.Error("Refusing to share the state of the live operator", "mode", mode, "err", err)
 		return nil, err
 	}
-	contractABI, err := avs.ContracthelloWorldMetaData.GetAbi()
+	openJournal := journal.Open
+	if cli {
+		openJournal = journal.OpenReadOnly
+	}
+	taskJournal, err := openJournal(journalPath)
 	if err != nil {
-		logger.Error("Cannot GetAbi", "err", err)
+		logger.Error("Cannot open task journal", "path", journalPath, "err", err)
 		return nil, err
 	}
+	var shadowReport *shadow.Writer
+	if !mode.Submits() && !cli {
+		reportPath := c.ShadowReportPath
+		if reportPath == "" {
+			reportPath = defaultShadowReportPath
+		}
+		shadowReport, err = shadow.OpenWriter(reportPath)
+		if err != nil {
+			logger.Error("Cannot open shadow report", "path", reportPath, "err", err)
+			return nil, err
+		}
+	}
 
 	operator := &Operator{
 		config:             c,
+		network:            profile,
 		logger:             logger,
-		nodeApi:            nodeApi,
 		ethClient:          ethRpcClient,
-		avsWriter:          avsWriter,
-		avsReader:          *avsReader,
-		ethWsClient:        ethWsClient,
-		blsKeypair:         blsKeyPair,
+		txMgr:              txMgr,
+		chainReader:        *chainReader,
+		blsSigner:          blsSigner,
 		operatorAddr:       common.HexToAddress(c.OperatorAddress),
 		newTaskCreatedChan: make(chan *avs.ContracthelloWorldTaskCreated),
-		avsAddr:            common.HexToAddress(c.AVSAddress),
-		epochIdentifier:    epochIdentifier,
-		contractABI:        *contractABI,
+		journal:            taskJournal,
+		drainer:            core.NewDrainer(),
+		metrics:            operatorMetrics,
+		mode:               mode,
+		shadowReport:       shadowReport,
+	}
+	for _, avsConfig := range configs {
+		service, err := newAvsService(operator, avsConfig)
+		if err != nil {
+			return nil, err
+		}
+		operator.services = append(operator.services, service)
 	}
 
-	if c.RegisterOperatorOnStartup {
+	if c.RegisterOperatorOnStartup && mode.Submits() {
 		operator.registerOperatorOnStartup()
 	}
 	// Wait for transaction which operator optin avs to be mined
 	time.Sleep(5 * retryDelay)
 	logger.Info("Operator info",
 		"operatorAddr", c.OperatorAddress,
-		"operatorKey", operator.blsKeypair.PublicKey().Marshal(),
+		"operatorKey", operator.blsSigner.PublicKey(),
 	)
 
 	return operator, nil
@@ -185,22 +288,72 @@ func (o *Operator) Start(ctx context.Context) error {
 	// 4.operator register BLSPublicKey
 	// 5.operator submit task
 
-	operatorAddress, err := core.SwitchEthAddressToImAddress(o.operatorAddr.String())
+	operatorAddress, err := o.network.Bech32Address(o.operatorAddr)
 	if err != nil {
-		o.logger.Error("Cannot switch eth address to im address", "err", err)
+		o.logger.Error("Cannot switch eth address to bech32 address", "err", err)
 		panic(err)
 	}
 
-	flag, err := o.avsReader.IsOperator(&bind.CallOpts{}, o.operatorAddr.String())
-	if err != nil {
-		o.logger.Error("Cannot exec IsOperator", "err", err)
-		return err
-	}
-	if !flag {
-		o.logger.Error("Operator is not registered.", "err", err)
-		panic(fmt.Sprintf("Operator is not registered: %s", operatorAddress))
+	if o.mode == shadow.DryRun {
+		o.logger.Info("Dry-run mode, skipping registration and stake, no transaction is sent")
+	} else {
+		flag, err := o.chainReader.IsOperator(&bind.CallOpts{}, o.operatorAddr.String())
+		if err != nil {
+			o.logger.Error("Cannot exec IsOperator", "err", err)
+			return err
+		}
+		if !flag {
+			o.logger.Error("Operator is not registered.", "err", err)
+			panic(fmt.Sprintf("Operator is not registered: %s", operatorAddress))
+		}
+
+		for _, s := range o.services {
+			if o.mode == shadow.Shadow {
+				// the live operator with the same keys registers, shadow mode only checks it did
+				if err := s.checkRegistration(ctx); err != nil {
+					return fmt.Errorf("shadow mode needs an operator registered with avs %s: %w", s.name, err)
+				}
+				continue
+			}
+			if err := s.prepare(ctx, operatorAddress); err != nil {
+				return err
+			}
+		}
 	}
+	o.logger.Info("Starting operator.", "mode", o.mode)
+
+	if o.config.EnableNodeApi {
+		monitor := health.NewMonitor(AvsName, SemVer, o.logger, o.healthChecks()...)
+		go monitor.Serve(ctx, o.config.NodeApiIpPortAddress)
+		go monitor.Run(ctx, health.DefaultInterval)
+	}
+	if o.config.EnableMetrics {
+		go o.metrics.Serve(ctx, o.config.OperatorMetricsIpPortAddress, o.logger)
+		go o.metrics.Poll(ctx, metrics.PollConfig{
+			Client:           o.ethClient,
+			Account:          o.operatorAddr,
+			EpochIdentifiers: o.epochIdentifiers(),
+			CurrentEpoch: func(ctx context.Context, epochIdentifier string) (int64, error) {
+				return o.chainReader.GetCurrentEpoch(&bind.CallOpts{Context: ctx}, epochIdentifier)
+			},
+		})
+	}
+
+	var wg sync.WaitGroup
+	for _, s := range o.services {
+		wg.Add(1)
+		go func(s *avsService) {
+			defer wg.Done()
+			s.run(ctx)
+		}(s)
+	}
+	wg.Wait()
+	return ctx.Err()
+}
 
+// prepare registers the BLS public key of the operator with the AVS and makes sure the
+// operator has stake opted in to it, depositing and delegating if it has none.
+func (o *avsService) prepare(ctx context.Context, operatorAddress string) error {
 	pubKey, err := o.avsReader.GetRegisteredPubkey(&bind.CallOpts{}, o.operatorAddr.String(), o.avsAddr.String())
 	if err != nil {
 		o.logger.Error("Cannot exec GetRegisteredPubKey", "err", err)
@@ -210,15 +363,19 @@ func (o *Operator) Start(ctx context.Context) error {
 	if len(pubKey) == 0 {
 		// operator register BLSPublicKey  via evm tx
 		msg := fmt.Sprintf(core.BLSMessageToSign,
-			core.ChainIDWithoutRevision("imuachainlocalnet_232"), operatorAddress)
+			core.ChainIDWithoutRevision(o.network.ChainID), operatorAddress)
 		hashedMsg := crypto.Keccak256Hash([]byte(msg))
-		sig := o.blsKeypair.Sign(hashedMsg.Bytes())
+		sig, err := o.blsSigner.Sign(ctx, hashedMsg.Bytes())
+		if err != nil {
+			o.logger.Error("operator failed to sign the BLS public key registration", "err", err)
+			return err
+		}
 
 		_, err = o.avsWriter.RegisterBLSPublicKey(
 			context.Background(),
 			o.avsAddr.String(),
-			o.blsKeypair.PublicKey().Marshal(),
-			sig.Marshal())
+			o.blsSigner.PublicKey(),
+			sig)
 
 		if err != nil {
 			o.logger.Error("operator failed to registerBLSPublicKey", "err", err)
@@ -248,16 +405,16 @@ func (o *Operator) Start(ctx context.Context) error {
 		// 2.Perform Deposit and so on
 		if amount.IsZero() {
 			//deposit and delegate
-			err := o.Deposit()
+			err := o.Deposit(ctx)
 			if err != nil {
 				panic(fmt.Sprintf("Can not Deposit: %s", err))
 			}
-			err = o.Delegate()
+			err = o.Delegate(ctx)
 			if err != nil {
 				panic(fmt.Sprintf("Can not Delegate: %s", err))
 
 			}
-			err = o.SelfDelegate()
+			err = o.SelfDelegate(ctx)
 			if err != nil {
 				panic(fmt.Sprintf("Can not SelfDelegate: %s", err))
 
@@ -293,215 +450,107 @@ func (o *Operator) Start(ctx context.Context) error {
 		}
 		if amount.IsZero() {
 			//deposit and delegate
-			err := o.Deposit()
+			err := o.Deposit(ctx)
 			if err != nil {
 				panic(fmt.Sprintf("Can not Deposit: %s", err))
 			}
-			err = o.Delegate()
+			err = o.Delegate(ctx)
 			if err != nil {
 				panic(fmt.Sprintf("Can not Delegate: %s", err))
 			}
-			err = o.SelfDelegate()
+			err = o.SelfDelegate(ctx)
 			if err != nil {
 				panic(fmt.Sprintf("Can not SelfDelegate: %s", err))
 			}
 		}
 	}
-	o.logger.Infof("Starting operator.")
+	return nil
+}
 
-	if o.config.EnableNodeApi {
-		o.nodeApi.Start()
-	}
+// run handles the tasks of the AVS until ctx is done: the unfinished ones of the journal,
+// the ones missed while the operator was stopped and then the new ones.
+func (o *avsService) run(ctx context.Context) {
+	o.resumeUnfinishedTasks(ctx)
 
-	query := ethereum.FilterQuery{
-		Addresses: []common.Address{o.avsAddr},
-	}
-	logs := make(chan ethtypes.Log)
+	o.follower.Run(ctx, o.events, o.backfillEvents)
+}
 
-	sub, err := o.ethWsClient.SubscribeFilterLogs(context.Background(), query, logs)
+// Shutdown waits up to timeout for in-flight task submissions, then closes the task journal and
+// flushes the logs.
+// Submissions that did not finish are resumed from the journal on the next start.
+func (o *Operator) Shutdown(timeout time.Duration) error {
+	o.logger.Info("Shutting down operator, draining in-flight submissions", "timeout", timeout)
+	err := o.drainer.Wait(timeout)
 	if err != nil {
-		o.logger.Error("Subscribe failed", "err", err)
-
+		o.logger.Warn("Abandoning in-flight submissions", "err", err)
 	}
-	defer sub.Unsubscribe()
-
-	o.logger.Infof("Starting event monitoring...")
-
-	for {
-		select {
-		case err := <-sub.Err():
-			o.logger.Error("Subscription error:", err)
-		case vLog := <-logs:
-			event, err := o.parseEvent(vLog)
-			if err != nil {
-				o.logger.Info("Not as expected TaskCreated log ，parse err:", "err", err)
-			}
-			if event != nil {
-				e := event.(*avs.ContracthelloWorldTaskCreated)
-				taskResponse := o.ProcessNewTaskCreatedLog(e)
-				sig, resBytes, err := o.SignTaskResponse(taskResponse)
-				if err != nil {
-					o.logger.Error("Failed to sign task response", "err", err)
-					continue
-				}
-				taskInfo, _ := o.avsReader.GetTaskInfo(&bind.CallOpts{}, o.avsAddr.String(), taskResponse.TaskID)
-				go func() {
-					_, err := o.SendSignedTaskResponseToChain(context.Background(), taskResponse.TaskID, resBytes, sig, taskInfo)
-					if err != nil {
-
-					}
-				}()
+	if cerr := o.journal.Close(); cerr != nil {
+		o.logger.Error("Failed to close task journal", "err", cerr)
+		if err == nil {
+			err = cerr
+		}
+	}
+	if o.shadowReport != nil {
+		if cerr := o.shadowReport.Close(); cerr != nil {
+			o.logger.Error("Failed to close shadow report", "err", cerr)
+			if err == nil {
+				err = cerr
 			}
 		}
 	}
+	o.logger.Info("Operator shut down")
+	if serr := core.SyncLogger(o.logger); serr != nil && err == nil {
+		err = serr
+	}
+	return err
 }
-func (o *Operator) parseEvent(vLog ethtypes.Log) (interface{}, error) {
-
-	vLog.Topics[0] = o.contractABI.Events["TaskCreated"].ID
 
-	var event avs.ContracthelloWorldTaskCreated
-
-	err := o.contractABI.UnpackIntoInterface(&event, "TaskCreated", vLog.Data)
-	if err != nil {
-		return nil, err
+// epochIdentifiers returns the epoch identifiers of the served AVSs, each once.
+func (o *Operator) epochIdentifiers() []string {
+	var identifiers []string
+	seen := map[string]bool{}
+	for _, s := range o.services {
+		if !seen[s.epochIdentifier] {
+			seen[s.epochIdentifier] = true
+			identifiers = append(identifiers, s.epochIdentifier)
+		}
 	}
-	return &event, nil
+	return identifiers
 }
 
-// ProcessNewTaskCreatedLog TaskResponse is the struct that is signed and sent to the chain as a task response.
-func (o *Operator) ProcessNewTaskCreatedLog(e *avs.ContracthelloWorldTaskCreated) *core.TaskResponse {
+// ProcessNewTaskCreatedLog solves the task announced by the TaskCreated event with the
+// task type named in the task name, which must be the task type of the AVS if it has one,
+// and returns the task ID and the encoded task response.
+func (o *avsService) ProcessNewTaskCreatedLog(e *avs.ContracthelloWorldTaskCreated) (uint64, []byte, error) {
 	o.logger.Info("New Task Created", "TaskID", e.TaskId.Uint64(),
 		"Issuer", e.Issuer.String(), "Name", e.Name, "NumberToBeSquared", e.NumberToBeSquared)
-	// Perform multiplication to complete the task requirements
-	taskResponse := &core.TaskResponse{
-		TaskID:        e.TaskId.Uint64(),
-		NumberSquared: e.NumberToBeSquared * e.NumberToBeSquared,
+	taskID := e.TaskId.Uint64()
+	taskType, err := core.TaskTypeForName(e.Name)
+	if err != nil {
+		o.logger.Error("Unsupported task type, skipping task", "name", e.Name, "err", err)
+		return 0, nil, err
 	}
-	return taskResponse
-}
-
-func (o *Operator) SignTaskResponse(taskResponse *core.TaskResponse) ([]byte, []byte, error) {
-
-	taskResponseHash, data, err := core.GetTaskResponseDigestEncodeByAbi(*taskResponse)
+	if o.taskType != "" && taskType.Name() != o.taskType {
+		o.logger.Error("Task type not handled for this AVS, skipping task", "name", e.Name, "taskType", o.taskType)
+		return 0, nil, fmt.Errorf("task type %s is not handled for avs %s, expected %s", taskType.Name(), o.name, o.taskType)
+	}
+	input, err := taskType.DecodeInput(e.NumberToBeSquared)
 	if err != nil {
-		o.logger.Error("Error SignTaskResponse with getting task response header hash. skipping task (this is not expected and should be investigated)", "err", err)
-		return nil, nil, err
+		return 0, nil, fmt.Errorf("failed to decode %s task input: %w", taskType.Name(), err)
 	}
-	msgBytes := taskResponseHash[:]
-
-	sig := o.blsKeypair.Sign(msgBytes)
-
-	return sig.Marshal(), data, nil
+	output, err := taskType.Solve(taskID, input)
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to solve %s task: %w", taskType.Name(), err)
+	}
+	taskResponse, err := taskType.EncodeResponse(taskID, output)
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to encode %s task response: %w", taskType.Name(), err)
+	}
+	return taskID, taskResponse, nil
 }
 
-func (o *Operator) SendSignedTaskResponseToChain(
-	ctx context.Context,
-	taskId uint64,
-	taskResponse []byte,
-	blsSignature []byte,
-	taskInfo avs.TaskInfo) (string, error) {
-
-	startingEpoch := taskInfo.StartingEpoch
-	taskResponsePeriod := taskInfo.TaskResponsePeriod
-	taskStatisticalPeriod := taskInfo.TaskStatisticalPeriod
-
-	for {
-		select {
-		case <-ctx.Done():
-			return "", ctx.Err() // Gracefully exit if context is canceled
-		default:
-			// Fetch the current epoch information
-			epochIdentifier, err := o.avsReader.GetAVSEpochIdentifier(&bind.CallOpts{}, o.avsAddr.String())
-			if err != nil {
-				o.logger.Error("Cannot GetAVSEpochIdentifier", "err", err)
-				return "", fmt.Errorf("failed to get AVS info: %w", err) // Stop on persistent error
-			}
-
-			num, err := o.avsReader.GetCurrentEpoch(&bind.CallOpts{}, epochIdentifier)
-			if err != nil {
-				o.logger.Error("Cannot exec GetCurrentEpoch", "err", err)
-				return "", fmt.Errorf("failed to get current epoch: %w", err) // Stop on persistent error
-			}
-
-			currentEpoch := uint64(num)
-			// o.logger.Info("current epoch  is :", "currentEpoch", currentEpoch)
-			if currentEpoch > startingEpoch+taskResponsePeriod+taskStatisticalPeriod {
-				o.logger.Info("Exiting loop: Task period has passed",
-					"Task", taskInfo.TaskContractAddress.String()+"--"+strconv.FormatUint(taskId, 10))
-				return "The current task period has passed:", nil
-			}
-
-			switch {
-			case currentEpoch <= startingEpoch:
-				// o.logger.Info("current epoch  is less than starting epoch", "currentEpoch", currentEpoch, "startingEpoch", startingEpoch)
-
-			case currentEpoch <= startingEpoch+taskResponsePeriod:
-				o.logger.Info("Execute Phase One Submission Task", "currentEpoch", currentEpoch,
-					"startingEpoch", startingEpoch, "taskResponsePeriod", taskResponsePeriod)
-				o.logger.Info("Submitting task response for task response period",
-					"taskAddr", o.avsAddr.String(), "taskId", taskId, "operator-addr", o.operatorAddr)
-				_, err := o.avsWriter.OperatorSubmitTask(
-					ctx,
-					taskId,
-					nil,
-					blsSignature,
-					o.avsAddr.String(),
-					1)
-				if err != nil {
-					o.logger.Error("Avs failed to OperatorSubmitTask", "err", err)
-					return "", fmt.Errorf("failed to submit task during taskResponsePeriod: %w", err)
-				}
-				// Wait for transaction which operator submit task to be mined
-				//	Avoid err: invalid nonce,invalid sequence,got x, expected y
-				// time.Sleep(2 * time.Second)
-
-			case currentEpoch <= startingEpoch+taskResponsePeriod+taskStatisticalPeriod && currentEpoch > startingEpoch+taskResponsePeriod:
-				o.logger.Info("Execute Phase Two Submission Task", "currentEpoch", currentEpoch,
-					"startingEpoch", startingEpoch, "taskResponsePeriod", taskResponsePeriod, "taskStatisticalPeriod", taskStatisticalPeriod)
-				o.logger.Info("Submitting task response for statistical period",
-					"taskAddr", o.avsAddr.String(), "taskId", taskId, "operator-addr", o.operatorAddr)
-				_, err := o.avsWriter.OperatorSubmitTask(
-					ctx,
-					taskId,
-					taskResponse,
-					blsSignature,
-					o.avsAddr.String(),
-					2)
-				if err != nil {
-					o.logger.Error("Avs failed to OperatorSubmitTask", "err", err)
-					return "", fmt.Errorf("failed to submit task during statistical period: %w", err)
-				}
-
-			default:
-				o.logger.Info("Current epoch is not within expected range", "currentEpoch", currentEpoch)
-				return "", fmt.Errorf("current epoch %d is not within expected range %d", currentEpoch, startingEpoch)
-			}
-			// Disable the uselesss ticker.
-			// // Dynamic sleep based on the epoch identifier
-			// var sleepDuration time.Duration
-			// switch epochIdentifier {
-			// case DayEpochID:
-			// 	sleepDuration = 24 * time.Hour
-			// case HourEpochID:
-			// 	sleepDuration = time.Hour
-			// case MinuteEpochID:
-			// 	sleepDuration = time.Minute
-			// case WeekEpochID:
-			// 	sleepDuration = 7 * 24 * time.Hour
-			// default:
-			// 	o.logger.Info("Unknown epoch identifier", "epochIdentifier", epochIdentifier)
-			// 	sleepDuration = time.Minute // Default to a safe short duration
-			// }
-
-			// // Use a ticker instead of time.Sleep for more control and graceful handling
-			// ticker := time.NewTicker(sleepDuration)
-			// defer ticker.Stop() // Ensure ticker stops even if the function exits early
-			// select {
-			// case <-ticker.C:
-			// 	continue // Proceed to the next iteration
-			// case <-ctx.Done():
-			// 	return "", ctx.Err() // Handle cancellation
-			// }
-		}
-	}
+// SignTaskResponse signs the keccak256 digest of the encoded task response with the operator BLS signer.
+func (o *Operator) SignTaskResponse(ctx context.Context, taskResponse []byte) ([]byte, error) {
+	taskResponseHash := crypto.Keccak256Hash(taskResponse)
+	return o.blsSigner.Sign(ctx, taskResponseHash.Bytes())
 }
@@ -1,10 +1,27 @@
 package operator
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"math/big"
+	"slices"
+	"sort"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	avs "github.com/imua-xyz/imua-avs/contracts/bindings/avs"
+	"github.com/imua-xyz/imua-avs/core"
+	chain "github.com/imua-xyz/imua-avs/core/chainio"
+	"github.com/imua-xyz/imua-avs/operator/journal"
 )
 
 func (o *Operator) registerOperatorOnStartup() {
@@ -22,7 +39,7 @@ func (o *Operator) registerOperatorOnStartup() {
 	}
 }
 func (o *Operator) RegisterOperatorWithChain() error {
-	flag, err := o.avsReader.IsOperator(&bind.CallOpts{}, o.operatorAddr.String())
+	flag, err := o.chainReader.IsOperator(&bind.CallOpts{}, o.operatorAddr.String())
 	if err != nil {
 		o.logger.Error("Cannot exec IsOperator", "err", err)
 		return err
@@ -35,8 +52,18 @@ func (o *Operator) RegisterOperatorWithChain() error {
 	return nil
 }
 
-// RegisterOperatorWithAvs Registration specific functions
+// RegisterOperatorWithAvs opts the operator in to every AVS it serves.
 func (o *Operator) RegisterOperatorWithAvs() error {
+	for _, s := range o.services {
+		if err := s.registerOperatorWithAvs(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// registerOperatorWithAvs opts the operator in to the AVS unless it already is.
+func (o *avsService) registerOperatorWithAvs() error {
 	operators, err := o.avsReader.GetOptInOperators(&bind.CallOpts{}, o.avsAddr.String())
 	if err != nil {
 		o.logger.Error("Cannot exec IsOperator", "err", err)
@@ -64,27 +91,351 @@ func (o *Operator) RegisterOperatorWithAvs() error {
 	return nil
 }
 
+// owedTasksMaxBatches bounds how far back owedTasks scans for TaskCreated events, in batches of
+// chain.BackfillBatchSize blocks.
+const owedTasksMaxBatches = 50
+
+// OwedTask is a task whose submission window is still open and whose response the operator has
+// not yet revealed on chain.
+type OwedTask struct {
+	TaskID uint64
+	Name   string
+	// Phase is the last phase the operator submitted on chain, 0 if none.
+	Phase uint8
+	// StatisticalEnd is the last epoch the response can be revealed in.
+	StatisticalEnd uint64
+}
+
+// ErrTasksOwed is returned by DeregisterOperatorWithAvs when the operator still owes task
+// responses and the opt-out is not forced.
+var ErrTasksOwed = errors.New("operator still owes task responses")
+
+// owedTasks returns the tasks the operator still owes a response for, as recorded on chain, the
+// responses it would leave unanswered if it opted out now. It scans the TaskCreated events of the AVS backwards from the head block and asks the
+// chain for the window of each task and the phase the operator submitted. Tasks start in epoch
+// order, so the scan stops at the first batch of blocks whose oldest task is past the longest
+// window seen, at backfill_start_block or after owedTasksMaxBatches batches.
+func (o *avsService) owedTasks(ctx context.Context) ([]OwedTask, error) {
+	opts := &bind.CallOpts{Context: ctx}
+	epoch, err := o.avsReader.GetCurrentEpoch(opts, o.epochIdentifier)
+	if err != nil {
+		return nil, fmt.Errorf("cannot get current epoch: %w", err)
+	}
+	head, err := o.ethClient.BlockNumber(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get head block: %w", err)
+	}
+
+	var created []*avs.ContracthelloWorldTaskCreated
+	router := chain.NewEventRouter()
+	chain.OnEvent(router, func(ctx context.Context, e *avs.ContracthelloWorldTaskCreated) error {
+		created = append(created, e)
+		return nil
+	})
+
+	var owed []OwedTask
+	var maxWindow uint64
+	for batch, to := 0, head; batch < owedTasksMaxBatches && to >= o.config.BackfillStartBlock; batch++ {
+		from := o.config.BackfillStartBlock
+		if to >= from+chain.BackfillBatchSize {
+			from = to - chain.BackfillBatchSize + 1
+		}
+		logs, err := o.ethClient.FilterLogs(ctx, ethereum.FilterQuery{
+			FromBlock: new(big.Int).SetUint64(from),
+			ToBlock:   new(big.Int).SetUint64(to),
+			Addresses: []common.Address{o.avsAddr},
+			Topics:    [][]common.Hash{{chain.EventTopic[avs.ContracthelloWorldTaskCreated]()}},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get TaskCreated logs of blocks %d to %d: %w", from, to, err)
+		}
+		created = created[:0]
+		for _, vLog := range logs {
+			if err := router.Dispatch(ctx, vLog); err != nil {
+				o.logger.Debug("Ignoring log of the AVS contract", "block", vLog.BlockNumber, "err", err)
+			}
+		}
+		oldestStart := uint64(epoch)
+		for i := len(created) - 1; i >= 0; i-- {
+			info, err := o.avsReader.GetTaskInfo(opts, o.avsAddr.String(), created[i].TaskId.Uint64())
+			if err != nil {
+				return nil, fmt.Errorf("cannot get task info: %w", err)
+			}
+			maxWindow = max(maxWindow, info.TaskResponsePeriod+info.TaskStatisticalPeriod)
+			oldestStart = min(oldestStart, info.StartingEpoch)
+			task, ok, err := o.owedTask(ctx, uint64(epoch), info)
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				owed = append(owed, task)
+			}
+		}
+		if len(created) > 0 && oldestStart+maxWindow < uint64(epoch) || from <= o.config.BackfillStartBlock {
+			break
+		}
+		to = from - 1
+	}
+	sort.Slice(owed, func(a, b int) bool { return owed[a].TaskID < owed[b].TaskID })
+	return owed, nil
+}
+
+// owedTask reports whether the operator still owes the response to the task described by info
+// at epoch: it was opted in when the task was created, has not revealed its response on chain,
+// and the submission state machine would still send a phase.
+func (o *avsService) owedTask(ctx context.Context, epoch uint64, info avs.TaskInfo) (OwedTask, bool, error) {
+	if len(info.OptInOperators) > 0 && !slices.Contains(info.OptInOperators, o.operatorAddr) {
+		return OwedTask{}, false, nil
+	}
+	res, err := o.avsReader.GetOperatorTaskResponse(&bind.CallOpts{Context: ctx}, o.avsAddr.String(), o.operatorAddr.String(), info.TaskID)
+	if err != nil {
+		return OwedTask{}, false, fmt.Errorf("failed to check submitted phase: %w", err)
+	}
+	state := journal.TaskSigned
+	switch {
+	case res.Phase >= core.TaskPhaseTwo:
+		return OwedTask{}, false, nil
+	case res.Phase == core.TaskPhaseOne:
+		state = journal.TaskPhaseOneSent
+	}
+	if nextSubmissionAction(state, epoch, info) == actionFinish {
+		return OwedTask{}, false, nil
+	}
+	return OwedTask{
+		TaskID:         info.TaskID,
+		Name:           info.Name,
+		Phase:          res.Phase,
+		StatisticalEnd: info.StartingEpoch + info.TaskResponsePeriod + info.TaskStatisticalPeriod,
+	}, true, nil
+}
+
+// DeregisterOperatorWithAvs opts the operator out of the AVS selected by name or address and
+// confirms through GetOptInOperators that it is no longer opted in. The tasks the operator still
+// owes a response for are logged as warnings, and the opt-out is refused with ErrTasksOwed while
+// there are any unless force is set.
+func (o *Operator) DeregisterOperatorWithAvs(ctx context.Context, avs string, force bool) error {
+	s, err := o.service(avs)
+	if err != nil {
+		return err
+	}
+	return s.deregisterOperatorWithAvs(ctx, force)
+}
+
+func (o *avsService) deregisterOperatorWithAvs(ctx context.Context, force bool) error {
+	optedIn, err := o.isOptedIn(ctx)
+	if err != nil {
+		return err
+	}
+	if !optedIn {
+		o.logger.Info("Operator is not opt-in this avs, nothing to deregister.", "avsAddr", o.avsAddr.String())
+		return nil
+	}
+
+	// tasks still in their window go unanswered once the operator has opted out
+	owed, err := o.owedTasks(ctx)
+	if err != nil {
+		return err
+	}
+	for _, t := range owed {
+		o.logger.Warn("Task still owes a response", "taskId", t.TaskID, "name", t.Name, "untilEpoch", t.StatisticalEnd, "phaseOnChain", t.Phase)
+	}
+	if len(owed) > 0 {
+		if !force {
+			return fmt.Errorf("%w: %d tasks are still in their window, wait for them or force the opt-out", ErrTasksOwed, len(owed))
+		}
+		o.logger.Warn("Deregistering with unfinished tasks, their responses will not be submitted", "tasks", len(owed))
+	}
+
+	receipt, err := o.avsWriter.DeregisterOperatorFromAVS(ctx)
+	if err != nil {
+		o.logger.Error("Avs failed to DeregisterOperatorFromAVS", "err", err)
+		return err
+	}
+	if receipt.Status != ethtypes.ReceiptStatusSuccessful {
+		return fmt.Errorf("deregister tx %s reverted", receipt.TxHash.String())
+	}
+
+	optedIn, err = o.isOptedIn(ctx)
+	if err != nil {
+		return err
+	}
+	if optedIn {
+		return fmt.Errorf("operator %s is still opted in to avs %s after deregister tx %s",
+			o.operatorAddr.String(), o.avsAddr.String(), receipt.TxHash.String())
+	}
+	o.logger.Info("Operator has opted out of this avs:", "avsAddr", o.avsAddr.String(), "txHash", receipt.TxHash.String())
+	return nil
+}
+
+// isOptedIn reports whether the operator is among the opted in operators of the AVS.
+func (o *avsService) isOptedIn(ctx context.Context) (bool, error) {
+	operators, err := o.avsReader.GetOptInOperators(&bind.CallOpts{Context: ctx}, o.avsAddr.String())
+	if err != nil {
+		o.logger.Error("Cannot exec GetOptInOperators", "err", err)
+		return false, err
+	}
+	for _, addr := range operators {
+		if addr == o.operatorAddr {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// OperatorStatus is the state of the operator as seen from the chain.
 type OperatorStatus struct {
-	EcdsaAddress string
-	// pubkey compendium related
-	PubkeysRegistered bool
-	Pubkey            string
+	EcdsaAddress    string `json:"ecdsa_address"`
+	ImAddress       string `json:"im_address"`
+	AvsAddress      string `json:"avs_address"`
+	EcdsaBalance    string `json:"ecdsa_balance"`
+	EpochIdentifier string `json:"epoch_identifier"`
+	CurrentEpoch    int64  `json:"current_epoch"`
+	// chain related
+	RegisteredWithChain bool `json:"registered_with_chain"`
+	// pubkey related
+	Pubkey            string `json:"pubkey"`
+	RegisteredPubkey  string `json:"registered_pubkey"`
+	PubkeysRegistered bool   `json:"pubkeys_registered"`
+	PubkeyMatches     bool   `json:"pubkey_matches"`
 	// avs related
-	RegisteredWithAvs bool
+	RegisteredWithAvs bool   `json:"registered_with_avs"`
+	OptedUSDValue     string `json:"opted_usd_value"`
 }
 
-func (o *Operator) PrintOperatorStatus() error {
-	o.logger.Info("Printing operator status")
-	operatorStatus := OperatorStatus{
-		EcdsaAddress:      o.operatorAddr.String(),
-		PubkeysRegistered: true,
-		Pubkey:            string(o.blsKeypair.PublicKey().Marshal()),
-		RegisteredWithAvs: false,
+// Status queries the state of the operator with the AVS selected by name or address from the chain.
+func (o *Operator) Status(ctx context.Context, avs string) (OperatorStatus, error) {
+	s, err := o.service(avs)
+	if err != nil {
+		return OperatorStatus{}, err
 	}
-	operatorStatusJson, err := json.MarshalIndent(operatorStatus, "", " ")
+	return s.status(ctx)
+}
+
+func (o *avsService) status(ctx context.Context) (OperatorStatus, error) {
+	opts := &bind.CallOpts{Context: ctx}
+	status := OperatorStatus{
+		EcdsaAddress:    o.operatorAddr.String(),
+		AvsAddress:      o.avsAddr.String(),
+		EpochIdentifier: o.epochIdentifier,
+		Pubkey:          hexutil.Encode(o.blsSigner.PublicKey()),
+		OptedUSDValue:   "0",
+	}
+	imAddress, err := o.network.Bech32Address(o.operatorAddr)
 	if err != nil {
-		return err
+		return status, err
+	}
+	status.ImAddress = imAddress
+
+	balance, err := o.ethClient.BalanceAt(ctx, o.operatorAddr, nil)
+	if err != nil {
+		return status, fmt.Errorf("cannot get balance: %w", err)
+	}
+	status.EcdsaBalance = balance.String()
+	status.CurrentEpoch, err = o.avsReader.GetCurrentEpoch(opts, o.epochIdentifier)
+	if err != nil {
+		return status, fmt.Errorf("cannot get current epoch: %w", err)
+	}
+
+	status.RegisteredWithChain, err = o.avsReader.IsOperator(opts, o.operatorAddr.String())
+	if err != nil {
+		return status, fmt.Errorf("cannot exec IsOperator: %w", err)
+	}
+
+	registeredPubkey, err := o.avsReader.GetRegisteredPubkey(opts, o.operatorAddr.String(), o.avsAddr.String())
+	if err != nil {
+		return status, fmt.Errorf("cannot exec GetRegisteredPubkey: %w", err)
+	}
+	status.PubkeysRegistered = len(registeredPubkey) > 0
+	if status.PubkeysRegistered {
+		status.RegisteredPubkey = hexutil.Encode(registeredPubkey)
+		status.PubkeyMatches = bytes.Equal(registeredPubkey, o.blsSigner.PublicKey())
+	}
+
+	status.RegisteredWithAvs, err = o.isOptedIn(ctx)
+	if err != nil {
+		return status, err
+	}
+	if status.RegisteredWithAvs {
+		value, err := o.avsReader.GetOperatorOptedUSDValue(opts, o.avsAddr.String(), o.operatorAddr.String())
+		if err != nil {
+			return status, fmt.Errorf("cannot exec GetOperatorOptedUSDValue: %w", err)
+		}
+		status.OptedUSDValue = value.String()
+	}
+	return status, nil
+}
+
+// PrintOperatorStatus writes the status of the operator with the AVS selected by name or address,
+// or with every AVS it serves if avs is empty, to w. Each status is written as a table or, when
+// output is "json", the status of the selected AVS as a JSON object and the statuses of every
+// AVS as one JSON array.
+func (o *Operator) PrintOperatorStatus(ctx context.Context, w io.Writer, output, avs string) error {
+	if avs != "" {
+		s, err := o.service(avs)
+		if err != nil {
+			return err
+		}
+		status, err := s.status(ctx)
+		if err != nil {
+			return err
+		}
+		return status.Write(w, output)
+	}
+	statuses := make([]OperatorStatus, 0, len(o.services))
+	for _, s := range o.services {
+		status, err := s.status(ctx)
+		if err != nil {
+			return err
+		}
+		statuses = append(statuses, status)
+	}
+	if output == "json" {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(statuses)
+	}
+	for i, status := range statuses {
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+		if err := status.Write(w, output); err != nil {
+			return err
+		}
 	}
-	o.logger.Info(string(operatorStatusJson))
 	return nil
 }
+
+// Write writes the status to w, as a table or, when output is "json", as JSON.
+func (s OperatorStatus) Write(w io.Writer, output string) error {
+	switch output {
+	case "json":
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(s)
+	case "", "table":
+		pubkeyMatches := "-"
+		if s.PubkeysRegistered {
+			pubkeyMatches = strconv.FormatBool(s.PubkeyMatches)
+		}
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		for _, row := range [][2]string{
+			{"ECDSA address", s.EcdsaAddress},
+			{"im address", s.ImAddress},
+			{"ECDSA balance (wei)", s.EcdsaBalance},
+			{"Registered with chain", strconv.FormatBool(s.RegisteredWithChain)},
+			{"AVS address", s.AvsAddress},
+			{"Registered with AVS", strconv.FormatBool(s.RegisteredWithAvs)},
+			{"Opted USD value", s.OptedUSDValue},
+			{"Local BLS pubkey", s.Pubkey},
+			{"BLS pubkey registered", strconv.FormatBool(s.PubkeysRegistered)},
+			{"Registered BLS pubkey", s.RegisteredPubkey},
+			{"Registered pubkey matches local", pubkeyMatches},
+			{"Current epoch", fmt.Sprintf("%d (%s)", s.CurrentEpoch, s.EpochIdentifier)},
+		} {
+			fmt.Fprintf(tw, "%s\t%s\n", row[0], row[1])
+		}
+		return tw.Flush()
+	default:
+		return fmt.Errorf("unknown output format %q, expected table or json", output)
+	}
+}
@@ -0,0 +1,348 @@
+package operator
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"slices"
+	"strings"
+	"testing"
+
+	sdkmath "cosmossdk.io/math"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	sdklogging "github.com/imua-xyz/imua-avs-sdk/logging"
+	avs "github.com/imua-xyz/imua-avs/contracts/bindings/avs"
+	"github.com/imua-xyz/imua-avs/core"
+	chain "github.com/imua-xyz/imua-avs/core/chainio"
+	"github.com/imua-xyz/imua-avs/core/chainio/eth"
+	"github.com/imua-xyz/imua-avs/core/network"
+	"github.com/imua-xyz/imua-avs/types"
+)
+
+var (
+	testAvsAddr      = common.HexToAddress("0x10Ed22D975453A5D4031440D51624552E4f204D5")
+	testOperatorAddr = common.HexToAddress("0x3e108c058e8066DA635321Dc3018294cA82ddEdf")
+)
+
+// fakeAvsChain is the AVS contract and the chain as the operator reads them, the methods of the
+// embedded interfaces it does not implement are not used.
+type fakeAvsChain struct {
+	chain.AvsReader
+	chain.AvsWriter
+	eth.EthClient
+
+	epoch int64
+	// logs are the TaskCreated logs, tasks and phases what the chain knows about them
+	logs   []ethtypes.Log
+	tasks  map[uint64]avs.TaskInfo
+	phases map[uint64]uint8
+	// optedIn is cleared by the deregister transaction
+	optedIn      bool
+	deregistered bool
+	// registeredPubkey is the BLS key of the operator on chain
+	registeredPubkey []byte
+	// failing is the status lookup that fails
+	failing string
+}
+
+func (c *fakeAvsChain) lookup(name string) error {
+	if c.failing == name {
+		return errors.New(name + " unavailable")
+	}
+	return nil
+}
+
+func (c *fakeAvsChain) GetCurrentEpoch(opts *bind.CallOpts, epochIdentifier string) (int64, error) {
+	return c.epoch, c.lookup("GetCurrentEpoch")
+}
+
+func (c *fakeAvsChain) IsOperator(opts *bind.CallOpts, operator string) (bool, error) {
+	return true, c.lookup("IsOperator")
+}
+
+func (c *fakeAvsChain) GetRegisteredPubkey(opts *bind.CallOpts, operator, avsAddress string) ([]byte, error) {
+	return c.registeredPubkey, c.lookup("GetRegisteredPubkey")
+}
+
+func (c *fakeAvsChain) GetOperatorOptedUSDValue(opts *bind.CallOpts, avsAddress, operatorAddr string) (sdkmath.LegacyDec, error) {
+	return sdkmath.LegacyMustNewDecFromStr("12.5"), c.lookup("GetOperatorOptedUSDValue")
+}
+
+func (c *fakeAvsChain) BalanceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (*big.Int, error) {
+	return big.NewInt(1500), c.lookup("BalanceAt")
+}
+
+func (c *fakeAvsChain) GetTaskInfo(opts *bind.CallOpts, avsAddress string, taskID uint64) (avs.TaskInfo, error) {
+	info, ok := c.tasks[taskID]
+	if !ok {
+		return avs.TaskInfo{}, errors.New("task not found")
+	}
+	return info, nil
+}
+
+func (c *fakeAvsChain) GetOperatorTaskResponse(opts *bind.CallOpts, taskAddress, operatorAddress string, taskID uint64) (avs.TaskResultInfo, error) {
+	return avs.TaskResultInfo{TaskID: taskID, Phase: c.phases[taskID]}, nil
+}
+
+func (c *fakeAvsChain) GetOptInOperators(opts *bind.CallOpts, avsAddress string) ([]common.Address, error) {
+	if err := c.lookup("GetOptInOperators"); err != nil {
+		return nil, err
+	}
+	if !c.optedIn {
+		return nil, nil
+	}
+	return []common.Address{testOperatorAddr}, nil
+}
+
+func (c *fakeAvsChain) DeregisterOperatorFromAVS(ctx context.Context) (*ethtypes.Receipt, error) {
+	c.optedIn, c.deregistered = false, true
+	return &ethtypes.Receipt{Status: ethtypes.ReceiptStatusSuccessful}, nil
+}
+
+func (c *fakeAvsChain) BlockNumber(ctx context.Context) (uint64, error) {
+	return uint64(len(c.logs)), nil
+}
+
+func (c *fakeAvsChain) FilterLogs(ctx context.Context, q ethereum.FilterQuery) ([]ethtypes.Log, error) {
+	var logs []ethtypes.Log
+	for _, l := range c.logs {
+		if l.BlockNumber >= q.FromBlock.Uint64() && l.BlockNumber <= q.ToBlock.Uint64() {
+			logs = append(logs, l)
+		}
+	}
+	return logs, nil
+}
+
+// addTask creates info on the chain in a block of its own, with the operator at phase.
+func (c *fakeAvsChain) addTask(t *testing.T, info avs.TaskInfo, phase uint8) {
+	contractABI, err := avs.ContracthelloWorldMetaData.GetAbi()
+	if err != nil {
+		t.Fatalf("Error loading ABI: %v", err)
+	}
+	data, err := contractABI.Events["TaskCreated"].Inputs.Pack(new(big.Int).SetUint64(info.TaskID), testAvsAddr, info.Name,
+		uint64(2), info.TaskResponsePeriod, info.TaskChallengePeriod, info.ThresholdPercentage, info.TaskStatisticalPeriod)
+	if err != nil {
+		t.Fatalf("Error packing TaskCreated: %v", err)
+	}
+	c.logs = append(c.logs, ethtypes.Log{
+		Address:     testAvsAddr,
+		Topics:      []common.Hash{chain.EventTopic[avs.ContracthelloWorldTaskCreated]()},
+		Data:        data,
+		BlockNumber: uint64(len(c.logs) + 1),
+	})
+	c.tasks[info.TaskID] = info
+	c.phases[info.TaskID] = phase
+}
+
+func newTestAvsService(t *testing.T, c *fakeAvsChain) *avsService {
+	logger, err := sdklogging.NewZapLogger(sdklogging.Development)
+	if err != nil {
+		t.Fatalf("Error creating logger: %v", err)
+	}
+	return &avsService{
+		Operator: &Operator{
+			config:       types.NodeConfig{BackfillStartBlock: 1},
+			logger:       logger,
+			network:      network.Localnet,
+			ethClient:    c,
+			blsSigner:    fakeBLSSigner{},
+			operatorAddr: testOperatorAddr,
+		},
+		name:            "hello",
+		logger:          logger,
+		avsAddr:         testAvsAddr,
+		avsReader:       c,
+		avsWriter:       c,
+		epochIdentifier: "minute",
+	}
+}
+
+// fakeBLSSigner holds testPubkey.
+type fakeBLSSigner struct{}
+
+var testPubkey = []byte{0xa1, 0xb2}
+
+func (fakeBLSSigner) PublicKey() []byte { return testPubkey }
+
+func (fakeBLSSigner) Sign(ctx context.Context, digest []byte) ([]byte, error) {
+	return nil, errors.New("not used")
+}
+
+func (fakeBLSSigner) Ready(ctx context.Context) error { return nil }
+
+// testTask is a task with phase one during epochs start+1 to start+3 and phase two during
+// epochs start+4 and start+5.
+func testTask(id, start uint64) avs.TaskInfo {
+	return avs.TaskInfo{
+		TaskContractAddress:   testAvsAddr,
+		Name:                  core.FormatTaskName("square", "task"),
+		TaskID:                id,
+		StartingEpoch:         start,
+		TaskResponsePeriod:    3,
+		TaskStatisticalPeriod: 2,
+		TaskChallengePeriod:   2,
+		ThresholdPercentage:   100,
+	}
+}
+
+func TestOwedTasks(t *testing.T) {
+	notOptedIn := testTask(6, 10)
+	notOptedIn.OptInOperators = []common.Address{common.HexToAddress("0x0000000000000000000000000000000000000001")}
+	optedIn := testTask(7, 10)
+	optedIn.OptInOperators = []common.Address{testOperatorAddr}
+	// the current epoch is 12, in phase one of the tasks starting in epoch 10
+	cases := []struct {
+		name  string
+		info  avs.TaskInfo
+		phase uint8
+		owed  bool
+	}{
+		{"nothing submitted", testTask(1, 10), 0, true},
+		{"phase one submitted", testTask(2, 10), core.TaskPhaseOne, true},
+		{"already submitted", testTask(3, 10), core.TaskPhaseTwo, false},
+		{"expired window", testTask(4, 5), 0, false},
+		{"phase one missed", testTask(5, 8), 0, false},
+		{"created before the opt-in", notOptedIn, 0, false},
+		{"opted in when created", optedIn, 0, true},
+		{"not started yet", testTask(8, 12), 0, true},
+	}
+	c := &fakeAvsChain{epoch: 12, tasks: map[uint64]avs.TaskInfo{}, phases: map[uint64]uint8{}}
+	for _, tc := range cases {
+		c.addTask(t, tc.info, tc.phase)
+	}
+
+	owed, err := newTestAvsService(t, c).owedTasks(context.Background())
+	if err != nil {
+		t.Fatalf("owedTasks failed: %v", err)
+	}
+	for _, tc := range cases {
+		i := slices.IndexFunc(owed, func(o OwedTask) bool { return o.TaskID == tc.info.TaskID })
+		if got := i >= 0; got != tc.owed {
+			t.Errorf("%s: expected owed %v, but got %v", tc.name, tc.owed, got)
+			continue
+		}
+		if i < 0 {
+			continue
+		}
+		want := OwedTask{
+			TaskID:         tc.info.TaskID,
+			Name:           tc.info.Name,
+			Phase:          tc.phase,
+			StatisticalEnd: tc.info.StartingEpoch + 5,
+		}
+		if owed[i] != want {
+			t.Errorf("%s: expected %+v, but got %+v", tc.name, want, owed[i])
+		}
+	}
+	if !slices.IsSortedFunc(owed, func(a, b OwedTask) int { return int(a.TaskID) - int(b.TaskID) }) {
+		t.Errorf("Expected the owed tasks sorted by task id, but got %+v", owed)
+	}
+}
+
+func TestDeregisterRefusedWhileTasksOwed(t *testing.T) {
+	cases := []struct {
+		name         string
+		phase        uint8
+		force        bool
+		wantErr      error
+		deregistered bool
+	}{
+		{"task owed", 0, false, ErrTasksOwed, false},
+		{"task owed, forced", 0, true, nil, true},
+		{"task already submitted", core.TaskPhaseTwo, false, nil, true},
+	}
+	for _, tc := range cases {
+		c := &fakeAvsChain{epoch: 12, tasks: map[uint64]avs.TaskInfo{}, phases: map[uint64]uint8{}, optedIn: true}
+		c.addTask(t, testTask(1, 10), tc.phase)
+
+		err := newTestAvsService(t, c).deregisterOperatorWithAvs(context.Background(), tc.force)
+		if !errors.Is(err, tc.wantErr) || (tc.wantErr == nil && err != nil) {
+			t.Errorf("%s: expected error %v, but got %v", tc.name, tc.wantErr, err)
+		}
+		if c.deregistered != tc.deregistered {
+			t.Errorf("%s: expected deregister tx sent %v, but got %v", tc.name, tc.deregistered, c.deregistered)
+		}
+		if c.optedIn == tc.deregistered {
+			t.Errorf("%s: expected opted in %v after the command", tc.name, !tc.deregistered)
+		}
+	}
+}
+
+func TestPrintOperatorStatusJSON(t *testing.T) {
+	c := &fakeAvsChain{epoch: 12, optedIn: true, registeredPubkey: testPubkey}
+	s := newTestAvsService(t, c)
+	s.Operator.services = []*avsService{s}
+
+	var out bytes.Buffer
+	if err := s.Operator.PrintOperatorStatus(context.Background(), &out, "json", "hello"); err != nil {
+		t.Fatalf("PrintOperatorStatus failed: %v", err)
+	}
+	var status map[string]interface{}
+	if err := json.Unmarshal(out.Bytes(), &status); err != nil {
+		t.Fatalf("Expected a JSON object for the selected AVS, but got %q: %v", out.String(), err)
+	}
+	imAddress, err := network.Localnet.Bech32Address(testOperatorAddr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]interface{}{
+		"ecdsa_address":         testOperatorAddr.Hex(),
+		"im_address":            imAddress,
+		"avs_address":           testAvsAddr.Hex(),
+		"ecdsa_balance":         "1500",
+		"epoch_identifier":      "minute",
+		"current_epoch":         float64(12),
+		"registered_with_chain": true,
+		"pubkey":                "0xa1b2",
+		"registered_pubkey":     "0xa1b2",
+		"pubkeys_registered":    true,
+		"pubkey_matches":        true,
+		"registered_with_avs":   true,
+		"opted_usd_value":       "12.500000000000000000",
+	}
+	for key, value := range want {
+		if status[key] != value {
+			t.Errorf("Expected %s to be %v, but got %v", key, value, status[key])
+		}
+	}
+	for key := range status {
+		if _, ok := want[key]; !ok {
+			t.Errorf("Unexpected key %s in the status", key)
+		}
+	}
+
+	// without --avs the statuses of every AVS are one array
+	out.Reset()
+	if err := s.Operator.PrintOperatorStatus(context.Background(), &out, "json", ""); err != nil {
+		t.Fatalf("PrintOperatorStatus failed: %v", err)
+	}
+	var statuses []map[string]interface{}
+	if err := json.Unmarshal(out.Bytes(), &statuses); err != nil || len(statuses) != 1 {
+		t.Fatalf("Expected a JSON array with one status, but got %q: %v", out.String(), err)
+	}
+	if statuses[0]["avs_address"] != testAvsAddr.Hex() {
+		t.Errorf("Expected the status of %s, but got %v", testAvsAddr.Hex(), statuses[0]["avs_address"])
+	}
+}
+
+func TestPrintOperatorStatusLookupFails(t *testing.T) {
+	for _, lookup := range []string{"BalanceAt", "GetCurrentEpoch", "IsOperator", "GetRegisteredPubkey", "GetOptInOperators", "GetOperatorOptedUSDValue"} {
+		c := &fakeAvsChain{epoch: 12, optedIn: true, registeredPubkey: testPubkey, failing: lookup}
+		s := newTestAvsService(t, c)
+		s.Operator.services = []*avsService{s}
+
+		var out bytes.Buffer
+		err := s.Operator.PrintOperatorStatus(context.Background(), &out, "json", "")
+		if err == nil || !strings.Contains(err.Error(), lookup+" unavailable") {
+			t.Errorf("%s: expected the lookup error, but got %v", lookup, err)
+		}
+		if out.Len() > 0 {
+			t.Errorf("%s: expected no partial status to be printed, but got %q", lookup, out.String())
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package operator
+
+import (
+	"context"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	avs "github.com/imua-xyz/imua-avs/contracts/bindings/avs"
+	"github.com/imua-xyz/imua-avs/operator/journal"
+	"github.com/imua-xyz/imua-avs/operator/shadow"
+)
+
+// compareTaskResponse waits for the statistical period of a task to end, when every operator
+// has revealed its response, and reports how the response of the operator compares with them.
+// A failed comparison is retried on the next poll until ctx is canceled.
+func (o *avsService) compareTaskResponse(ctx context.Context, taskID uint64, resBytes []byte, taskInfo avs.TaskInfo) {
+	statisticalEnd := taskInfo.StartingEpoch + taskInfo.TaskResponsePeriod + taskInfo.TaskStatisticalPeriod
+
+	ticker := time.NewTicker(epochPollInterval)
+	defer ticker.Stop()
+
+	for {
+		num, err := o.avsReader.GetCurrentEpoch(&bind.CallOpts{Context: ctx}, o.epochIdentifier)
+		if err != nil {
+			o.logger.Error("Cannot exec GetCurrentEpoch", "err", err)
+		} else if uint64(num) > statisticalEnd {
+			if err := o.reportTaskResponse(ctx, taskID, resBytes); err != nil {
+				o.logger.Error("Failed to compare task response, retrying", "taskId", taskID, "err", err)
+			} else {
+				o.advanceTask(taskID, journal.TaskFinished)
+				return
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			o.logger.Info("Stopped task comparison, it resumes from the journal on restart", "taskId", taskID)
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// reportTaskResponse compares the response of the operator with the ones on chain and records the verdict.
+func (o *avsService) reportTaskResponse(ctx context.Context, taskID uint64, resBytes []byte) error {
+	infos, err := o.avsReader.GetOperatorTaskResponseList(&bind.CallOpts{Context: ctx}, o.avsAddr.String(), taskID)
+	if err != nil {
+		return err
+	}
+	report := shadow.Compare(o.operatorAddr, resBytes, infos)
+	report.Mode = o.mode
+	report.AVS = o.name
+	report.TaskAddress = o.avsAddr
+	report.TaskID = taskID
+	if err := o.shadowReport.Write(report); err != nil {
+		return err
+	}
+	o.metrics.ShadowVerdicts.WithLabelValues(o.name, string(report.Verdict)).Inc()
+
+	log := o.logger.Info
+	if report.Verdict == shadow.Mismatch || report.Live == shadow.Mismatch {
+		log = o.logger.Warn
+	}
+	log("Compared task response with the chain", "taskId", taskID, "verdict", report.Verdict,
+		"matching", len(report.Matching), "mismatching", len(report.Mismatching), "live", report.Live)
+	return nil
+}
@@ -0,0 +1,189 @@
+// Package shadow supports running an operator build against live traffic without submitting:
+// the operator signs every task as usual, and once the statistical period of the task is over
+// its response is compared with the ones the other operators submitted on chain.
+//
+// Comparisons are appended to a report file with one JSON record per line.
+package shadow
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	avs "github.com/imua-xyz/imua-avs/contracts/bindings/avs"
+	"github.com/imua-xyz/imua-avs/core"
+)
+
+// Mode is how the operator treats the tasks it signs.
+type Mode string
+
+const (
+	// Live submits task responses, the default.
+	Live Mode = "live"
+	// DryRun sends no transaction at all, it needs neither registration nor stake.
+	DryRun Mode = "dry-run"
+	// Shadow sends no transaction and runs next to a live operator with the same keys, which
+	// must be registered and opted in; the answer of the live operator is reported separately.
+	Shadow Mode = "shadow"
+)
+
+// ParseMode returns the mode named s, Live if s is empty.
+func ParseMode(s string) (Mode, error) {
+	switch m := Mode(s); m {
+	case "":
+		return Live, nil
+	case Live, DryRun, Shadow:
+		return m, nil
+	default:
+		return "", fmt.Errorf("unknown operator mode %q, expected %s, %s or %s", s, Live, DryRun, Shadow)
+	}
+}
+
+// Submits reports whether task responses are sent to the chain in mode m.
+func (m Mode) Submits() bool {
+	return m == Live
+}
+
+// Verdict is the outcome of comparing a task response with the ones on chain.
+type Verdict string
+
+const (
+	// Match means every other operator that revealed a response gave the same one.
+	Match Verdict = "match"
+	// Mismatch means at least one other operator revealed a different response.
+	Mismatch Verdict = "mismatch"
+	// Unanswered means no other operator revealed a response.
+	Unanswered Verdict = "unanswered"
+)
+
+// Report is the comparison of the response of the operator for one task with the chain.
+type Report struct {
+	Mode        Mode           `json:"mode"`
+	AVS         string         `json:"avs"`
+	TaskAddress common.Address `json:"task_address"`
+	TaskID      uint64         `json:"task_id"`
+	Response    hexutil.Bytes  `json:"response"`
+	Verdict     Verdict        `json:"verdict"`
+	// Matching and Mismatching are the other operators that revealed the same or a different
+	// response in phase two, Unrevealed the ones that only submitted phase one.
+	Matching    []common.Address `json:"matching"`
+	Mismatching []common.Address `json:"mismatching"`
+	Unrevealed  []common.Address `json:"unrevealed"`
+	// MatchingPower and MismatchingPower sum the power of Matching and Mismatching.
+	MatchingPower    string `json:"matching_power"`
+	MismatchingPower string `json:"mismatching_power"`
+	// Live is the verdict for the response the operator address itself has on chain, submitted
+	// by a live operator with the same keys, empty if it has none.
+	Live      Verdict   `json:"live,omitempty"`
+	CheckedAt time.Time `json:"checked_at"`
+}
+
+// Compare compares response, the response of operator self, with the responses on chain.
+func Compare(self common.Address, response []byte, infos []avs.OperatorResInfo) Report {
+	r := Report{
+		Response:    response,
+		Matching:    []common.Address{},
+		Mismatching: []common.Address{},
+		Unrevealed:  []common.Address{},
+	}
+	matchingPower, mismatchingPower := new(big.Int), new(big.Int)
+	for _, info := range infos {
+		revealed := info.Phase >= core.TaskPhaseTwo
+		if info.OperatorAddress == self {
+			switch {
+			case !revealed:
+				r.Live = Unanswered
+			case bytes.Equal(info.TaskResponse, response):
+				r.Live = Match
+			default:
+				r.Live = Mismatch
+			}
+			continue
+		}
+		switch {
+		case !revealed:
+			r.Unrevealed = append(r.Unrevealed, info.OperatorAddress)
+		case bytes.Equal(info.TaskResponse, response):
+			r.Matching = append(r.Matching, info.OperatorAddress)
+			addPower(matchingPower, info.Power)
+		default:
+			r.Mismatching = append(r.Mismatching, info.OperatorAddress)
+			addPower(mismatchingPower, info.Power)
+		}
+	}
+	switch {
+	case len(r.Mismatching) > 0:
+		r.Verdict = Mismatch
+	case len(r.Matching) > 0:
+		r.Verdict = Match
+	default:
+		r.Verdict = Unanswered
+	}
+	r.MatchingPower = matchingPower.String()
+	r.MismatchingPower = mismatchingPower.String()
+	return r
+}
+
+func addPower(sum, power *big.Int) {
+	if power != nil {
+		sum.Add(sum, power)
+	}
+}
+
+// Writer appends reports to a file. It is safe for concurrent use.
+type Writer struct {
+	mu   sync.Mutex
+	path string
+	file *os.File
+}
+
+// OpenWriter opens the report file at path for appending, creating it if needed.
+func OpenWriter(path string) (*Writer, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create report directory: %w", err)
+	}
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open shadow report: %w", err)
+	}
+	return &Writer{path: path, file: file}, nil
+}
+
+// Write appends r as one JSON line.
+func (w *Writer) Write(r Report) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.file == nil {
+		return fmt.Errorf("shadow report %s is closed", w.path)
+	}
+	if r.CheckedAt.IsZero() {
+		r.CheckedAt = time.Now().UTC()
+	}
+	line, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	if _, err := w.file.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("failed to append shadow report: %w", err)
+	}
+	return nil
+}
+
+// Close closes the report file.
+func (w *Writer) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.file == nil {
+		return nil
+	}
+	err := w.file.Close()
+	w.file = nil
+	return err
+}
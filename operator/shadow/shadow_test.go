@@ -0,0 +1,134 @@
+package shadow_test
+
+import (
+	"bufio"
+	"encoding/json"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	avs "github.com/imua-xyz/imua-avs/contracts/bindings/avs"
+	"github.com/imua-xyz/imua-avs/operator/shadow"
+)
+
+var (
+	self   = common.HexToAddress("0x01")
+	other1 = common.HexToAddress("0x02")
+	other2 = common.HexToAddress("0x03")
+	other3 = common.HexToAddress("0x04")
+)
+
+func TestParseMode(t *testing.T) {
+	for in, want := range map[string]shadow.Mode{"": shadow.Live, "live": shadow.Live, "dry-run": shadow.DryRun, "shadow": shadow.Shadow} {
+		got, err := shadow.ParseMode(in)
+		if err != nil || got != want {
+			t.Errorf("ParseMode(%q) = %q, %v, want %q", in, got, err, want)
+		}
+	}
+	if _, err := shadow.ParseMode("dryrun"); err == nil {
+		t.Error("ParseMode accepted an unknown mode")
+	}
+	if !shadow.Live.Submits() || shadow.DryRun.Submits() || shadow.Shadow.Submits() {
+		t.Error("only live mode may submit")
+	}
+}
+
+func TestCompare(t *testing.T) {
+	response := []byte{1, 2, 3}
+	revealed := func(op common.Address, res []byte, power int64) avs.OperatorResInfo {
+		return avs.OperatorResInfo{OperatorAddress: op, TaskResponse: res, Power: big.NewInt(power), Phase: 2}
+	}
+
+	tests := []struct {
+		name  string
+		infos []avs.OperatorResInfo
+		want  shadow.Verdict
+		live  shadow.Verdict
+	}{
+		{name: "no responses", want: shadow.Unanswered},
+		{
+			name:  "all match",
+			infos: []avs.OperatorResInfo{revealed(other1, response, 10), revealed(other2, response, 5)},
+			want:  shadow.Match,
+		},
+		{
+			name:  "one mismatch",
+			infos: []avs.OperatorResInfo{revealed(other1, response, 10), revealed(other2, []byte{9}, 5)},
+			want:  shadow.Mismatch,
+		},
+		{
+			name:  "only phase one",
+			infos: []avs.OperatorResInfo{{OperatorAddress: other1, Phase: 1}},
+			want:  shadow.Unanswered,
+		},
+		{
+			name:  "live operator differs",
+			infos: []avs.OperatorResInfo{revealed(self, []byte{9}, 10), revealed(other1, response, 10)},
+			want:  shadow.Match,
+			live:  shadow.Mismatch,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := shadow.Compare(self, response, tt.infos)
+			if r.Verdict != tt.want {
+				t.Errorf("verdict = %q, want %q", r.Verdict, tt.want)
+			}
+			if r.Live != tt.live {
+				t.Errorf("live verdict = %q, want %q", r.Live, tt.live)
+			}
+		})
+	}
+
+	r := shadow.Compare(self, response, []avs.OperatorResInfo{
+		revealed(other1, response, 10), revealed(other2, []byte{9}, 5), {OperatorAddress: other3, Phase: 1},
+	})
+	if len(r.Matching) != 1 || r.Matching[0] != other1 || r.MatchingPower != "10" {
+		t.Errorf("matching = %v (%s), want [%s] (10)", r.Matching, r.MatchingPower, other1)
+	}
+	if len(r.Mismatching) != 1 || r.Mismatching[0] != other2 || r.MismatchingPower != "5" {
+		t.Errorf("mismatching = %v (%s), want [%s] (5)", r.Mismatching, r.MismatchingPower, other2)
+	}
+	if len(r.Unrevealed) != 1 || r.Unrevealed[0] != other3 {
+		t.Errorf("unrevealed = %v, want [%s]", r.Unrevealed, other3)
+	}
+}
+
+func TestWriter(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "reports", "shadow.jsonl")
+	w, err := shadow.OpenWriter(path)
+	if err != nil {
+		t.Fatalf("Error opening report: %v", err)
+	}
+	for id := uint64(1); id <= 2; id++ {
+		r := shadow.Compare(self, []byte{1}, nil)
+		r.Mode = shadow.DryRun
+		r.TaskID = id
+		if err := w.Write(r); err != nil {
+			t.Fatalf("Error writing report: %v", err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Error closing report: %v", err)
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("Error reading report: %v", err)
+	}
+	defer file.Close()
+	var reports []shadow.Report
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var r shadow.Report
+		if err := json.Unmarshal(scanner.Bytes(), &r); err != nil {
+			t.Fatalf("Error decoding report line %q: %v", scanner.Text(), err)
+		}
+		reports = append(reports, r)
+	}
+	if len(reports) != 2 || reports[1].TaskID != 2 || reports[1].Verdict != shadow.Unanswered || reports[1].CheckedAt.IsZero() {
+		t.Errorf("reports = %+v, want two unanswered reports with a check time", reports)
+	}
+}
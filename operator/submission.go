@@ -0,0 +1,188 @@
+package operator
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	avs "github.com/imua-xyz/imua-avs/contracts/bindings/avs"
+	"github.com/imua-xyz/imua-avs/core"
+	"github.com/imua-xyz/imua-avs/core/metrics"
+	"github.com/imua-xyz/imua-avs/operator/journal"
+)
+
+// epochPollInterval is how often a task submission checks whether the epoch changed.
+const epochPollInterval = 5 * time.Second
+
+// submissionAction is what a task submission does when it observes a new epoch.
+type submissionAction int
+
+const (
+	actionWait submissionAction = iota
+	actionSubmitPhaseOne
+	actionSubmitPhaseTwo
+	actionFinish
+)
+
+// nextSubmissionAction is the transition table of the per-task state machine.
+// Phase one may only be sent during (startingEpoch, startingEpoch+taskResponsePeriod],
+// phase two during the following taskStatisticalPeriod epochs, and a phase that is
+// already sent is never sent again.
+func nextSubmissionAction(state journal.TaskState, epoch uint64, info avs.TaskInfo) submissionAction {
+	responseEnd := info.StartingEpoch + info.TaskResponsePeriod
+	statisticalEnd := responseEnd + info.TaskStatisticalPeriod
+	switch {
+	case state >= journal.TaskFinished, epoch > statisticalEnd:
+		return actionFinish
+	case epoch <= info.StartingEpoch:
+		return actionWait
+	case epoch <= responseEnd:
+		if state < journal.TaskPhaseOneSent {
+			return actionSubmitPhaseOne
+		}
+		return actionWait
+	case state < journal.TaskPhaseOneSent:
+		// phase two reveals what phase one committed to, without a commitment there is nothing to reveal
+		return actionFinish
+	case state < journal.TaskPhaseTwoSent:
+		return actionSubmitPhaseTwo
+	default:
+		return actionWait
+	}
+}
+
+// SendSignedTaskResponseToChain drives the two phase submission of a signed task response.
+// It polls the current epoch and only steps the task when the epoch changes, so each phase
+// is sent exactly once. A failed step is retried on the next poll; before every send the
+// chain is asked whether that phase is already recorded, so a retry never duplicates a submission.
+// Polling stops when ctx is canceled, a step in progress runs with the drain context instead.
+func (o *avsService) SendSignedTaskResponseToChain(
+	ctx context.Context,
+	taskId uint64,
+	taskResponse []byte,
+	blsSignature []byte,
+	taskInfo avs.TaskInfo) (string, error) {
+
+	ticker := time.NewTicker(epochPollInterval)
+	defer ticker.Stop()
+
+	stepped := false
+	var lastEpoch uint64
+	for {
+		num, err := o.avsReader.GetCurrentEpoch(&bind.CallOpts{Context: ctx}, o.epochIdentifier)
+		if err != nil {
+			o.logger.Error("Cannot exec GetCurrentEpoch", "err", err)
+		} else if currentEpoch := uint64(num); !stepped || currentEpoch != lastEpoch {
+			done, err := o.stepSubmission(o.drainer.Context(), taskId, taskResponse, blsSignature, taskInfo, currentEpoch)
+			if done {
+				return "The current task period has passed:", nil
+			}
+			if err != nil {
+				o.logger.Error("Task submission step failed, retrying", "taskId", taskId, "currentEpoch", currentEpoch, "err", err)
+			} else {
+				stepped = true
+				lastEpoch = currentEpoch
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return "", ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// stepSubmission performs the action of the task state machine for currentEpoch.
+// It reports whether the task is finished.
+func (o *avsService) stepSubmission(
+	ctx context.Context,
+	taskId uint64,
+	taskResponse []byte,
+	blsSignature []byte,
+	taskInfo avs.TaskInfo,
+	currentEpoch uint64) (bool, error) {
+
+	state := o.taskState(taskId)
+	switch nextSubmissionAction(state, currentEpoch, taskInfo) {
+	case actionSubmitPhaseOne:
+		o.logger.Info("Execute Phase One Submission Task", "currentEpoch", currentEpoch,
+			"startingEpoch", taskInfo.StartingEpoch, "taskResponsePeriod", taskInfo.TaskResponsePeriod)
+		if err := o.submitPhase(ctx, taskId, nil, blsSignature, core.TaskPhaseOne); err != nil {
+			return false, fmt.Errorf("failed to submit task during taskResponsePeriod: %w", err)
+		}
+		o.advanceTask(taskId, journal.TaskPhaseOneSent)
+
+	case actionSubmitPhaseTwo:
+		o.logger.Info("Execute Phase Two Submission Task", "currentEpoch", currentEpoch,
+			"startingEpoch", taskInfo.StartingEpoch, "taskResponsePeriod", taskInfo.TaskResponsePeriod,
+			"taskStatisticalPeriod", taskInfo.TaskStatisticalPeriod)
+		if err := o.submitPhase(ctx, taskId, taskResponse, blsSignature, core.TaskPhaseTwo); err != nil {
+			return false, fmt.Errorf("failed to submit task during statistical period: %w", err)
+		}
+		o.advanceTask(taskId, journal.TaskPhaseTwoSent)
+
+	case actionFinish:
+		switch {
+		case state < journal.TaskPhaseOneSent:
+			o.logger.Warn("Phase one window missed, giving up on task", "taskId", taskId, "currentEpoch", currentEpoch)
+		case state < journal.TaskPhaseTwoSent:
+			o.logger.Warn("Phase two window missed", "taskId", taskId, "currentEpoch", currentEpoch)
+		}
+		o.logger.Info("Exiting loop: Task period has passed",
+			"Task", taskInfo.TaskContractAddress.String()+"--"+strconv.FormatUint(taskId, 10))
+		o.advanceTask(taskId, journal.TaskFinished)
+		return true, nil
+	}
+	return false, nil
+}
+
+// submitPhase sends one phase of a task response unless the chain already has it,
+// and counts the submission by its outcome.
+func (o *avsService) submitPhase(ctx context.Context, taskId uint64, taskResponse, blsSignature []byte, phase uint8) error {
+	sent, err := o.sendPhase(ctx, taskId, taskResponse, blsSignature, phase)
+	switch {
+	case err != nil:
+		o.metrics.Submissions.WithLabelValues(o.name, strconv.Itoa(int(phase)), metrics.ResultFailed).Inc()
+	case sent:
+		o.metrics.Submissions.WithLabelValues(o.name, strconv.Itoa(int(phase)), metrics.ResultSubmitted).Inc()
+	}
+	return err
+}
+
+// sendPhase sends one phase of a task response unless the chain already has it.
+// It reports whether a transaction was sent.
+func (o *avsService) sendPhase(ctx context.Context, taskId uint64, taskResponse, blsSignature []byte, phase uint8) (bool, error) {
+	res, err := o.avsReader.GetOperatorTaskResponse(&bind.CallOpts{Context: ctx}, o.avsAddr.String(), o.operatorAddr.String(), taskId)
+	if err != nil {
+		return false, fmt.Errorf("failed to check submitted phase: %w", err)
+	}
+	if res.Phase >= phase {
+		o.logger.Info("Phase already recorded on chain, not sending it again",
+			"taskId", taskId, "phase", phase, "onChainPhase", res.Phase)
+		return false, nil
+	}
+
+	o.logger.Info("Submitting task response",
+		"taskAddr", o.avsAddr.String(), "taskId", taskId, "operator-addr", o.operatorAddr, "phase", phase)
+	receipt, err := o.avsWriter.OperatorSubmitTask(
+		ctx,
+		taskId,
+		taskResponse,
+		blsSignature,
+		o.avsAddr.String(),
+		phase)
+	if err != nil {
+		o.logger.Error("Avs failed to OperatorSubmitTask", "err", err)
+		return false, err
+	}
+	if receipt == nil {
+		return false, fmt.Errorf("no receipt for phase %d submission", phase)
+	}
+	if receipt.Status != 1 {
+		return false, fmt.Errorf("phase %d submission %s reverted", phase, receipt.TxHash.Hex())
+	}
+	return true, nil
+}
@@ -0,0 +1,38 @@
+package operator
+
+import (
+	"testing"
+
+	avs "github.com/imua-xyz/imua-avs/contracts/bindings/avs"
+	"github.com/imua-xyz/imua-avs/operator/journal"
+)
+
+func TestNextSubmissionAction(t *testing.T) {
+	// phase one during epochs 11 to 13, phase two during epochs 14 and 15
+	info := avs.TaskInfo{StartingEpoch: 10, TaskResponsePeriod: 3, TaskStatisticalPeriod: 2}
+	cases := []struct {
+		name  string
+		state journal.TaskState
+		epoch uint64
+		want  submissionAction
+	}{
+		{"starting epoch", journal.TaskSigned, 10, actionWait},
+		{"first epoch of phase one", journal.TaskSigned, 11, actionSubmitPhaseOne},
+		{"last epoch of phase one", journal.TaskSigned, 13, actionSubmitPhaseOne},
+		{"phase one already sent", journal.TaskPhaseOneSent, 12, actionWait},
+		{"phase one sent before the window", journal.TaskPhaseOneSent, 10, actionWait},
+		{"first epoch of phase two", journal.TaskPhaseOneSent, 14, actionSubmitPhaseTwo},
+		{"last epoch of phase two", journal.TaskPhaseOneSent, 15, actionSubmitPhaseTwo},
+		{"phase one missed", journal.TaskSigned, 14, actionFinish},
+		{"phase one missed, last epoch of phase two", journal.TaskSeen, 15, actionFinish},
+		{"phase two already sent", journal.TaskPhaseTwoSent, 15, actionWait},
+		{"expired", journal.TaskPhaseOneSent, 16, actionFinish},
+		{"expired before signing", journal.TaskSeen, 16, actionFinish},
+		{"finished", journal.TaskFinished, 12, actionFinish},
+	}
+	for _, c := range cases {
+		if got := nextSubmissionAction(c.state, c.epoch, info); got != c.want {
+			t.Errorf("%s: expected action %d in state %s at epoch %d, but got %d", c.name, c.want, c.state, c.epoch, got)
+		}
+	}
+}
@@ -0,0 +1,212 @@
+package operator
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	avs "github.com/imua-xyz/imua-avs/contracts/bindings/avs"
+	"github.com/imua-xyz/imua-avs/core"
+	chain "github.com/imua-xyz/imua-avs/core/chainio"
+	"github.com/imua-xyz/imua-avs/operator/journal"
+)
+
+// errTaskHashMismatch is returned for a TaskCreated event whose fields do not hash to the task hash on chain.
+var errTaskHashMismatch = errors.New("TaskCreated event does not match the task hash on chain")
+
+// handleTaskCreated journals a new task, solves and signs it and starts submitting it. Its log
+// stays pending in the checkpoint until the submission is done, so a restart picks it up again.
+// Tasks the journal already knows past TaskSeen are skipped, they are resumed on startup instead.
+// Events that cannot be verified against the task on chain are not journaled, and so never signed.
+func (o *avsService) handleTaskCreated(ctx context.Context, e *avs.ContracthelloWorldTaskCreated) {
+	done := func() { o.follower.Done(e.Raw) }
+	taskID := e.TaskId.Uint64()
+	if r, ok := o.journal.Get(o.avsAddr, taskID); ok && r.State > journal.TaskSeen {
+		o.logger.Info("Task already in journal, skipping", "taskId", taskID, "state", r.State.String())
+		done()
+		return
+	}
+	if err := o.verifyTaskCreated(ctx, e); err != nil {
+		if errors.Is(err, errTaskHashMismatch) {
+			o.metrics.TaskHashMismatches.WithLabelValues(o.name).Inc()
+			o.logger.Error("ALERT: refusing to sign task, the TaskCreated event may be spoofed or the RPC misbehaving",
+				"taskId", taskID, "name", e.Name, "numberToBeSquared", e.NumberToBeSquared, "block", e.Raw.BlockNumber,
+				"txHash", e.Raw.TxHash.Hex(), "err", err)
+		} else {
+			o.logger.Error("Cannot verify task, refusing to sign it", "taskId", taskID, "err", err)
+		}
+		done()
+		return
+	}
+	err := o.journal.Record(journal.TaskRecord{
+		TaskAddress: o.avsAddr,
+		TaskID:      taskID,
+		State:       journal.TaskSeen,
+		Name:        e.Name,
+		Input:       e.NumberToBeSquared,
+	})
+	if err != nil {
+		o.logger.Error("Failed to journal task", "taskId", taskID, "err", err)
+		done()
+		return
+	}
+	o.metrics.TasksSeen.WithLabelValues(o.name).Inc()
+	o.signAndSubmitTask(ctx, e, done)
+}
+
+// signAndSubmitTask solves and signs a journaled task, then submits it in the background.
+// done is called once nothing is left to do for the task in this run.
+func (o *avsService) signAndSubmitTask(ctx context.Context, e *avs.ContracthelloWorldTaskCreated, done func()) {
+	taskID, resBytes, err := o.ProcessNewTaskCreatedLog(e)
+	if err != nil {
+		o.logger.Error("Failed to process task", "err", err)
+		done()
+		return
+	}
+	sig, err := o.SignTaskResponse(ctx, resBytes)
+	if err != nil {
+		o.logger.Error("Failed to sign task response", "err", err)
+		done()
+		return
+	}
+	o.metrics.TasksSigned.WithLabelValues(o.name).Inc()
+	err = o.journal.Record(journal.TaskRecord{
+		TaskAddress:  o.avsAddr,
+		TaskID:       taskID,
+		State:        journal.TaskSigned,
+		TaskResponse: resBytes,
+		BlsSignature: sig,
+	})
+	if err != nil {
+		o.logger.Error("Failed to journal signed task", "taskId", taskID, "err", err)
+		done()
+		return
+	}
+	o.submitTask(ctx, taskID, resBytes, sig, done)
+}
+
+// submitTask sends a signed task response to the chain in the background, or in dry-run and
+// shadow mode compares it with the chain instead. The submission stops when ctx is canceled,
+// but a phase already being sent is given until the shutdown drain deadline. done is called
+// when the submission ends, but not when it is stopped, so the task stays pending.
+func (o *avsService) submitTask(ctx context.Context, taskID uint64, resBytes, sig []byte, done func()) {
+	taskInfo, err := o.avsReader.GetTaskInfo(&bind.CallOpts{}, o.avsAddr.String(), taskID)
+	if err != nil {
+		o.logger.Error("Cannot GetTaskInfo", "taskId", taskID, "err", err)
+		done()
+		return
+	}
+	o.drainer.Go(func() {
+		if !o.mode.Submits() {
+			o.compareTaskResponse(ctx, taskID, resBytes, taskInfo)
+			if ctx.Err() == nil {
+				done()
+			}
+			return
+		}
+		_, err := o.SendSignedTaskResponseToChain(ctx, taskID, resBytes, sig, taskInfo)
+		if errors.Is(err, context.Canceled) {
+			o.logger.Info("Stopped task submission, it resumes from the journal on restart", "taskId", taskID)
+			return
+		}
+		if err != nil {
+			o.logger.Error("Failed to send signed task response", "taskId", taskID, "err", err)
+		}
+		done()
+	})
+}
+
+// resumeUnfinishedTasks picks up every task the journal has not seen finish,
+// starting from the phase recorded before the operator stopped.
+func (o *avsService) resumeUnfinishedTasks(ctx context.Context) {
+	for _, r := range o.journal.Unfinished() {
+		if r.TaskAddress != o.avsAddr {
+			continue
+		}
+		o.logger.Info("Resuming task from journal", "taskId", r.TaskID, "state", r.State.String())
+		// resumed tasks have no log pending in the checkpoint
+		done := func() {}
+		if r.State == journal.TaskSeen {
+			o.signAndSubmitTask(ctx, &avs.ContracthelloWorldTaskCreated{
+				TaskId:            new(big.Int).SetUint64(r.TaskID),
+				Name:              r.Name,
+				NumberToBeSquared: r.Input,
+			}, done)
+			continue
+		}
+		o.submitTask(ctx, r.TaskID, r.TaskResponse, r.BlsSignature, done)
+	}
+}
+
+// verifyTaskCreated rebuilds the Task struct of the contract from the event and checks that
+// it hashes to the task hash the precompile stored when the task was created.
+func (o *avsService) verifyTaskCreated(ctx context.Context, e *avs.ContracthelloWorldTaskCreated) error {
+	taskInfo, err := o.avsReader.GetTaskInfo(&bind.CallOpts{Context: ctx}, o.avsAddr.String(), e.TaskId.Uint64())
+	if err != nil {
+		return fmt.Errorf("failed to get task info: %w", err)
+	}
+	hash, err := core.TaskHash(core.Task{
+		Name:                  e.Name,
+		NumberToBeSquared:     e.NumberToBeSquared,
+		TaskResponsePeriod:    e.TaskResponsePeriod,
+		TaskChallengePeriod:   e.TaskChallengePeriod,
+		ThresholdPercentage:   e.ThresholdPercentage,
+		TaskStatisticalPeriod: e.TaskStatisticalPeriod,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to hash task: %w", err)
+	}
+	if !bytes.Equal(hash, taskInfo.Hash) {
+		return fmt.Errorf("%w: event hashes to %x, task %d on chain has %x", errTaskHashMismatch, hash, taskInfo.TaskID, taskInfo.Hash)
+	}
+	return nil
+}
+
+// taskState returns the journaled state of a task of this operator's AVS.
+func (o *avsService) taskState(taskID uint64) journal.TaskState {
+	r, _ := o.journal.Get(o.avsAddr, taskID)
+	return r.State
+}
+
+// advanceTask journals a state transition. A failure is logged but does not stop the task,
+// the worst case after a crash is a resubmission that the chain rejects.
+func (o *avsService) advanceTask(taskID uint64, state journal.TaskState) {
+	if err := o.journal.Advance(o.avsAddr, taskID, state); err != nil {
+		o.logger.Error("Failed to journal task state", "taskId", taskID, "state", state.String(), "err", err)
+	}
+}
+
+// newEventRouter routes the TaskCreated events of the live subscription or, if backfilled, of the backfill.
+func (o *avsService) newEventRouter(backfilled bool) *chain.EventRouter {
+	router := chain.NewEventRouter()
+	chain.OnEvent(router, func(ctx context.Context, e *avs.ContracthelloWorldTaskCreated) error {
+		// backfilled tasks whose statistical period is over have nothing left to submit
+		if backfilled && !o.taskWindowOpen(ctx, e.TaskId.Uint64()) {
+			o.logger.Info("Backfilled task is past its statistical period, skipping", "taskId", e.TaskId.Uint64())
+			o.follower.Done(e.Raw)
+			return nil
+		}
+		o.handleTaskCreated(ctx, e)
+		return nil
+	})
+	return router
+}
+
+// taskWindowOpen reports whether a task can still be submitted. If the chain cannot be asked
+// the task is treated as open, the submission state machine gives up on it if it is not.
+func (o *avsService) taskWindowOpen(ctx context.Context, taskID uint64) bool {
+	taskInfo, err := o.avsReader.GetTaskInfo(&bind.CallOpts{Context: ctx}, o.avsAddr.String(), taskID)
+	if err != nil {
+		o.logger.Error("Cannot GetTaskInfo", "taskId", taskID, "err", err)
+		return true
+	}
+	num, err := o.avsReader.GetCurrentEpoch(&bind.CallOpts{Context: ctx}, o.epochIdentifier)
+	if err != nil {
+		o.logger.Error("Cannot exec GetCurrentEpoch", "err", err)
+		return true
+	}
+	return uint64(num) <= taskInfo.StartingEpoch+taskInfo.TaskResponsePeriod+taskInfo.TaskStatisticalPeriod
+}
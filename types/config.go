@@ -1,5 +1,16 @@
 package types
 
+// AVSConfig is one of the AVSs an operator serves.
+type AVSConfig struct {
+	// Name identifies the AVS in logs, metrics and health checks, its address by default.
+	Name    string `yaml:"name"`
+	Address string `yaml:"address"`
+	// TaskType restricts the tasks handled to one registered task type, any registered type when empty.
+	TaskType string `yaml:"task_type"`
+	// CheckpointPath is the file the log scan position of this AVS is persisted to.
+	CheckpointPath string `yaml:"checkpoint_path"`
+}
+
 type NodeConfig struct {
 	// used to set the logger level (true = info, false = debug)
 	Production                       bool   `yaml:"production"`
@@ -8,12 +19,42 @@ type NodeConfig struct {
 	AVSAddress                       string `yaml:"avs_address"`
 	EthRpcUrl                        string `yaml:"eth_rpc_url"`
 	EthWsUrl                         string `yaml:"eth_ws_url"`
+	Network                          string `yaml:"network"` // localnet, testnet, mainnet or the path of a custom network profile
 	BlsPrivateKeyStorePath           string `yaml:"bls_private_key_store_path"`
+	BlsSigner                        string `yaml:"bls_signer"`                   // "local" signs with the bls keystore, "remote" with a remote signer
+	BlsRemoteSignerUrl               string `yaml:"bls_remote_signer_url"`        // url of the remote bls signer
+	BlsRemoteSignerPublicKey         string `yaml:"bls_remote_signer_public_key"` // hex encoded bls public key on the remote signer
 	OperatorEcdsaPrivateKeyStorePath string `yaml:"operator_ecdsa_private_key_store_path"`
 	AVSEcdsaPrivateKeyStorePath      string `yaml:"avs_ecdsa_private_key_store_path"`
 	RegisterOperatorOnStartup        bool   `yaml:"register_operator_on_startup"`
 	NodeApiIpPortAddress             string `yaml:"node_api_ip_port_address"`
 	EnableNodeApi                    bool   `yaml:"enable_node_api"`
+	OperatorJournalPath              string `yaml:"operator_journal_path"`      // file the operator journals task progress to
+	OperatorCheckpointPath           string `yaml:"operator_checkpoint_path"`   // file the operator persists its log scan position to
+	ChallengerCheckpointPath         string `yaml:"challenger_checkpoint_path"` // file the challenger persists its log scan position to
+	BackfillStartBlock               uint64 `yaml:"backfill_start_block"`       // block to backfill TaskCreated events from when no checkpoint exists, 0 disables it
+	ShutdownTimeout                  uint64 `yaml:"shutdown_timeout"`           // seconds in-flight transactions may drain after SIGINT or SIGTERM
+
+	// prometheus metrics, served on /metrics of one address per role
+	EnableMetrics                  bool   `yaml:"enable_metrics"`
+	OperatorMetricsIpPortAddress   string `yaml:"operator_metrics_ip_port_address"`
+	AvsMetricsIpPortAddress        string `yaml:"avs_metrics_ip_port_address"`
+	ChallengerMetricsIpPortAddress string `yaml:"challenger_metrics_ip_port_address"`
+	// seconds the head block may be old before the node api reports the chain as stalled
+	HealthMaxHeadAge uint64 `yaml:"health_max_head_age"`
+
+	// AVSs the operator serves, avs_address alone when empty
+	OperatorAVSs []AVSConfig `yaml:"operator_avs_list"`
+
+	// live, dry-run or shadow; only live submits task responses
+	OperatorMode string `yaml:"operator_mode"`
+	// file dry-run and shadow mode append their comparisons with the chain to
+	ShadowReportPath string `yaml:"shadow_report_path"`
+
+	// simulate challenges with eth_call without sending them
+	ChallengerSimulateOnly bool `yaml:"challenger_simulate_only"`
+	// directory the challenger writes a verdict report and evidence bundles per task to
+	ChallengeReportDir string `yaml:"challenge_report_dir"`
 
 	// register avs parameters
 	AvsName            string   `yaml:"avs_name"`
@@ -29,6 +70,7 @@ type NodeConfig struct {
 	AVSSlashAddress    string   `yaml:"avs_slash_address"`
 
 	// create new task parameters
+	TaskType              string `yaml:"task_type"` // the registered task type the avs creates, defaults to "square"
 	CreateTaskInterval    int64  `yaml:"create_task_interval"`
 	TaskResponsePeriod    uint64 `yaml:"task_response_period"`
 	TaskChallengePeriod   uint64 `yaml:"task_challenge_period"`
@@ -40,7 +82,12 @@ type NodeConfig struct {
 	AvsSlashProportion    uint64 `yaml:"avs_slash_proportion"`   // the proportion of slash for AVS
 
 	// deposit and delegation
-	DepositAmount  int64  `yaml:"deposit_amount"`
-	DelegateAmount int64  `yaml:"delegate_amount"`
-	Staker         string `yaml:"staker"`
+	DepositAmount                   int64  `yaml:"deposit_amount"`
+	DelegateAmount                  int64  `yaml:"delegate_amount"`
+	Staker                          string `yaml:"staker"`
+	StakingAssetID                  string `yaml:"staking_asset_id"`                     // asset deposited and delegated, <address>_<client chain id>, defaults to the first of asset_ids
+	FundingSigner                   string `yaml:"funding_signer"`                       // "local" signs with the funding keystore, "remote" with a remote signer
+	FundingEcdsaPrivateKeyStorePath string `yaml:"funding_ecdsa_private_key_store_path"` // keystore of the account funding deposits and delegations
+	FundingRemoteSignerUrl          string `yaml:"funding_remote_signer_url"`            // url of the remote signer of the funding account
+	FundingAddress                  string `yaml:"funding_address"`                      // funding account on the remote signer
 }